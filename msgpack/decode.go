@@ -522,7 +522,7 @@ func unmarshalDecoder(ds *decodeState, v reflect.Value) {
 	m := v.Interface().(Unmarshaler)
 	err := m.UnmarshalMsgPack(ds.Decoder)
 	if e, ok := err.(*DecodeConvertError); ok {
-		if ds.errSaved != nil {
+		if ds.errSaved == nil {
 			ds.errSaved = e
 		}
 	} else if err != nil {
@@ -120,3 +120,40 @@ func TestDecode(t *testing.T) {
 		}
 	}
 }
+
+// testConvertUnmarshaler decodes a string and returns *DecodeConvertError for
+// other types.
+type testConvertUnmarshaler struct {
+	S string
+}
+
+func (u *testConvertUnmarshaler) UnmarshalMsgPack(dec *Decoder) error {
+	if dec.Type() != String {
+		err := &DecodeConvertError{SrcType: dec.Type(), DestType: reflect.TypeOf(u)}
+		if skipErr := dec.Skip(); skipErr != nil {
+			return skipErr
+		}
+		return err
+	}
+	u.S = dec.String()
+	return nil
+}
+
+func TestDecodeUnmarshalerConvertError(t *testing.T) {
+	data, err := pack(mapLen(2), "A", int64(1), "B", int64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		A testConvertUnmarshaler
+		B int
+	}
+	err = NewDecoder(bytes.NewReader(data)).Decode(&v)
+	if _, ok := err.(*DecodeConvertError); !ok {
+		t.Errorf("Decode() returned %v, want *DecodeConvertError", err)
+	}
+	// Decoding continues after the convert error.
+	if v.B != 2 {
+		t.Errorf("B = %d, want 2", v.B)
+	}
+}
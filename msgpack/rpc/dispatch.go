@@ -0,0 +1,119 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"errors"
+	"sync"
+)
+
+var errSuperseded = errors.New("msgpack/rpc: request superseded by a newer request")
+
+// HandlerOption specifies an option for a handler registered with
+// RegisterHandler.
+type HandlerOption struct{ f func(*handler) }
+
+// Serial specifies that calls to the handler run one at a time in the order
+// that the messages arrive.
+func Serial() HandlerOption {
+	return Parallel(1)
+}
+
+// Parallel specifies that at most n calls to the handler run concurrently.
+// Calls start in the order that the messages arrive.
+func Parallel(n int) HandlerOption {
+	return HandlerOption{func(h *handler) {
+		h.dispatcher = &dispatcher{max: n}
+	}}
+}
+
+// LatestWins specifies that calls to the handler run one at a time and that
+// a message waiting to run is dropped when a newer message for the handler
+// arrives. Dropped requests are replied to with an error.
+func LatestWins() HandlerOption {
+	return HandlerOption{func(h *handler) {
+		h.dispatcher = &dispatcher{max: 1, latest: true}
+	}}
+}
+
+// Queue is a dispatch queue shared by several handlers. Calls to the
+// handlers registered with the InQueue option for a queue run one at a time
+// in the order that the messages arrive. Use a queue to order calls across
+// several methods:
+//
+//	q := rpc.NewQueue()
+//	e.RegisterHandler("a", a, rpc.InQueue(q))
+//	e.RegisterHandler("b", b, rpc.InQueue(q))
+//
+// A queue orders the calls from all endpoints where the handlers are
+// registered. Use a separate queue for each endpoint to order the calls per
+// connection.
+type Queue struct {
+	d dispatcher
+}
+
+// NewQueue returns a new dispatch queue.
+func NewQueue() *Queue {
+	return &Queue{d: dispatcher{max: 1}}
+}
+
+// InQueue specifies that calls to the handler run in queue q.
+func InQueue(q *Queue) HandlerOption {
+	return HandlerOption{func(h *handler) {
+		h.dispatcher = &q.d
+	}}
+}
+
+type job struct {
+	run  func()
+	drop func()
+}
+
+// dispatcher schedules calls to a handler.
+type dispatcher struct {
+	max    int
+	latest bool
+
+	mu      sync.Mutex
+	running int
+	queue   []job
+}
+
+func (d *dispatcher) dispatch(j job) {
+	d.mu.Lock()
+	if d.running < d.max {
+		d.running++
+		d.mu.Unlock()
+		go d.run(j.run)
+		return
+	}
+	var dropped []job
+	if d.latest {
+		dropped = d.queue
+		d.queue = nil
+	}
+	d.queue = append(d.queue, j)
+	d.mu.Unlock()
+
+	for _, j := range dropped {
+		j.drop()
+	}
+}
+
+func (d *dispatcher) run(f func()) {
+	for {
+		f()
+		d.mu.Lock()
+		if len(d.queue) == 0 {
+			d.running--
+			d.mu.Unlock()
+			return
+		}
+		f = d.queue[0].run
+		d.queue[0] = job{}
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+	}
+}
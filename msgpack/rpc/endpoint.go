@@ -6,11 +6,14 @@
 package rpc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/garyburd/neovim-go/msgpack"
 )
@@ -23,6 +26,7 @@ const (
 
 var (
 	errClosed   = errors.New("msgpack/rpc: session closed")
+	errShutdown = errors.New("msgpack/rpc: session shutting down")
 	errInternal = errors.New("msgpack/rpc: internal error")
 )
 
@@ -34,16 +38,68 @@ func (e Error) Error() string {
 	return fmt.Sprintf("%v", e.Value)
 }
 
+// PanicError is the error returned to the peer when a handler panics.
+type PanicError struct {
+	// ServiceMethod is the name of the method that panicked.
+	ServiceMethod string
+
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("msgpack/rpc: %s: panic: %v", e.ServiceMethod, e.Value)
+}
+
+// MarshalMsgPack encodes the error as a Neovim exception error, the array
+// [0, message].
+func (e *PanicError) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackArrayLen(2); err != nil {
+		return err
+	}
+	if err := enc.PackUint(0); err != nil {
+		return err
+	}
+	return enc.PackString(e.Error())
+}
+
 type Call struct {
 	ServiceMethod string
 	Args          interface{}
 	Reply         interface{}
 	Err           error
 	Done          chan *Call
+
+	id uint64
+
+	// sent is true after the call is added to the pending calls. abandoned,
+	// if not nil, is the error for a call abandoned by CallContext before the
+	// client interceptors sent the call. Both fields are protected by the
+	// endpoint's mu.
+	sent      bool
+	abandoned error
+
+	// complete, if not nil, is called instead of sending the call to Done.
+	// Client interceptors use the function to wait for the reply.
+	complete func()
+
+	// start is the time the call started. The field is set when the
+	// endpoint collects metrics.
+	start time.Time
 }
 
 func (call *Call) done(e *Endpoint, err error) {
 	call.Err = err
+	if call.complete != nil {
+		call.complete()
+		return
+	}
+	if e.metrics != nil && !call.start.IsZero() {
+		e.metrics.recordCall(call)
+	}
 	select {
 	case call.Done <- call:
 		// ok
@@ -53,22 +109,56 @@ func (call *Call) done(e *Endpoint, err error) {
 }
 
 type Endpoint struct {
-	logf func(fmt string, args ...interface{})
-	arg  reflect.Value
+	logf     func(fmt string, args ...interface{})
+	arg      reflect.Value
+	failFast bool
+
+	clientInterceptors []ClientInterceptor
+	serverInterceptors []ServerInterceptor
+	metrics            *Metrics
 
 	dec *msgpack.Decoder
 
 	packMu sync.Mutex
 	enc    *msgpack.Encoder
 
-	mu      sync.Mutex
-	closed  bool
-	id      uint64
-	pending map[uint64]*Call
-	closer  io.Closer
+	mu       sync.Mutex
+	closed   bool
+	shutdown bool
+	id       uint64
+	pending  map[uint64]*Call
+	closer   io.Closer
+
+	// running counts the handler calls accepted by the endpoint and not
+	// yet completed.
+	running sync.WaitGroup
+
+	// ctx is the parent of the contexts passed to handlers. The context is
+	// cancelled when the endpoint is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
 
 	handlersMu sync.RWMutex
-	handlers   map[string]reflect.Value
+	handlers   map[string]*handler
+}
+
+type handler struct {
+	fn reflect.Value
+
+	// hasContext is true if fn takes a context.Context argument.
+	hasContext bool
+
+	// dispatcher schedules calls to fn. If nil, each call runs in a new
+	// goroutine.
+	dispatcher *dispatcher
+}
+
+func (h *handler) dispatch(run, drop func()) {
+	if h.dispatcher == nil {
+		go run()
+		return
+	}
+	h.dispatcher.dispatch(job{run: run, drop: drop})
 }
 
 func NewEndpoint(conn io.ReadWriteCloser, options ...Option) (*Endpoint, error) {
@@ -77,9 +167,10 @@ func NewEndpoint(conn io.ReadWriteCloser, options ...Option) (*Endpoint, error)
 		enc:      msgpack.NewEncoder(conn),
 		dec:      msgpack.NewDecoder(conn),
 		pending:  make(map[uint64]*Call),
-		handlers: make(map[string]reflect.Value),
+		handlers: make(map[string]*handler),
 		logf:     func(fmt string, args ...interface{}) {},
 	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
 	for _, option := range options {
 		option.f(e)
 	}
@@ -106,6 +197,15 @@ func WithFirstArg(v interface{}) Option {
 	}}
 }
 
+// WithFailFast specifies that a panic in a handler crashes the process. By
+// default, the endpoint recovers the panic, logs the stack and replies to the
+// peer with a *PanicError. This option is useful in tests.
+func WithFailFast() Option {
+	return Option{func(e *Endpoint) {
+		e.failFast = true
+	}}
+}
+
 func (e *Endpoint) decodeUint(what string) (uint64, error) {
 	if err := e.dec.Unpack(); err != nil {
 		return 0, err
@@ -151,19 +251,132 @@ func (e *Endpoint) Close() error {
 		return errClosed
 	}
 	e.closed = true
+	e.cancel()
 	for _, call := range e.pending {
 		call.done(e, errClosed)
 	}
 	e.pending = nil
+	if e.metrics != nil {
+		e.metrics.removeEndpoint(e)
+	}
 	return e.closer.Close()
 }
 
+// Shutdown gracefully shuts down the endpoint. Shutdown stops accepting
+// requests and notifications from the peer, waits for running and queued
+// handlers to complete and send their replies, and then closes the endpoint.
+// Requests received during shutdown fail with an error. Handlers can call the
+// peer until the endpoint is closed.
+//
+// If ctx is done before the handlers complete, Shutdown closes the endpoint,
+// cancels the contexts passed to the handlers and returns ctx.Err().
+func (e *Endpoint) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return errClosed
+	}
+	e.shutdown = true
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return e.Close()
+	case <-ctx.Done():
+		e.Close()
+		return ctx.Err()
+	}
+}
+
+// startHandler reports whether the endpoint accepts a handler call. If the
+// call is accepted, the caller must call e.running.Done() when the call
+// completes.
+func (e *Endpoint) startHandler() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed || e.shutdown {
+		return false
+	}
+	e.running.Add(1)
+	return true
+}
+
+func (e *Endpoint) isClosed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closed
+}
+
 func (e *Endpoint) Call(serviceMethod string, reply interface{}, args ...interface{}) error {
 	c := <-e.Go(serviceMethod, make(chan *Call, 1), reply, args...).Done
 	return c.Err
 }
 
+// CallContext is like Call, but abandons the call when ctx is done. If the
+// context is cancelled or times out before the reply arrives, CallContext
+// removes the call from the set of pending calls and returns ctx.Err(). A
+// reply that arrives after the call is abandoned is skipped.
+func (e *Endpoint) CallContext(ctx context.Context, serviceMethod string, reply interface{}, args ...interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	call := e.goContext(ctx, serviceMethod, make(chan *Call, 1), reply, args...)
+	select {
+	case <-call.Done:
+	case <-ctx.Done():
+		if e.abandon(call, ctx.Err()) {
+			// The client interceptors have not sent the call. The call is
+			// not sent when the interceptors invoke it.
+			return ctx.Err()
+		}
+		e.Cancel(call, ctx.Err())
+		// Wait for the reply in the case where the reply is already in
+		// progress.
+		<-call.Done
+	}
+	return call.Err
+}
+
+// abandon marks a call that is not sent as abandoned and returns true. If the
+// call is sent, then abandon returns false.
+func (e *Endpoint) abandon(call *Call, err error) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if call.sent {
+		return false
+	}
+	call.abandoned = err
+	return true
+}
+
+// Cancel abandons a pending call. If the call is waiting for a reply, then
+// Cancel removes the call from the set of pending calls, completes the call
+// with err and returns true. A reply that arrives later for the call is
+// skipped.
+func (e *Endpoint) Cancel(call *Call, err error) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pending[call.id] != call {
+		return false
+	}
+	delete(e.pending, call.id)
+	call.done(e, err)
+	return true
+}
+
 func (e *Endpoint) Go(serviceMethod string, done chan *Call, reply interface{}, args ...interface{}) *Call {
+	return e.goContext(context.Background(), serviceMethod, done, reply, args...)
+}
+
+// goContext is like Go, but returns before the client interceptors send the
+// call when ctx is done.
+func (e *Endpoint) goContext(ctx context.Context, serviceMethod string, done chan *Call, reply interface{}, args ...interface{}) *Call {
 	if args == nil {
 		args = []interface{}{}
 	}
@@ -179,7 +392,42 @@ func (e *Endpoint) Go(serviceMethod string, done chan *Call, reply interface{},
 		Reply:         reply,
 		Done:          done,
 	}
+	if e.metrics != nil {
+		call.start = time.Now()
+	}
 
+	if len(e.clientInterceptors) == 0 {
+		e.send(call)
+		return call
+	}
+
+	// Run the interceptors in a goroutine and return when the interceptors
+	// send the call or fail the call. CallContext abandons a call that the
+	// interceptors hold when the context is done.
+	sent := make(chan struct{})
+	var once sync.Once
+	go func() {
+		invoke := chainClient(e.clientInterceptors, func(call *Call) error {
+			complete := make(chan struct{})
+			call.complete = func() { close(complete) }
+			e.send(call)
+			once.Do(func() { close(sent) })
+			<-complete
+			call.complete = nil
+			return call.Err
+		})
+		err := invoke(call)
+		once.Do(func() { close(sent) })
+		call.done(e, err)
+	}()
+	select {
+	case <-sent:
+	case <-ctx.Done():
+	}
+	return call
+}
+
+func (e *Endpoint) send(call *Call) {
 	e.packMu.Lock()
 	defer e.packMu.Unlock()
 
@@ -187,18 +435,23 @@ func (e *Endpoint) Go(serviceMethod string, done chan *Call, reply interface{},
 	if e.closed {
 		call.done(e, errClosed)
 		e.mu.Unlock()
-		return call
+		return
+	}
+	if call.abandoned != nil {
+		call.done(e, call.abandoned)
+		e.mu.Unlock()
+		return
 	}
+	call.sent = true
 	e.id = (e.id + 1) & 0x7fffffff
 	id := e.id
+	call.id = id
 	e.pending[id] = call
 	e.mu.Unlock()
 
-	if err := e.enc.Encode([]interface{}{requestMessage, id, serviceMethod, args}); err != nil {
+	if err := e.enc.Encode([]interface{}{requestMessage, id, call.ServiceMethod, call.Args}); err != nil {
 		e.fatal(fmt.Errorf("msgpack/rpc: error encoding %s: %v", call.ServiceMethod, err))
 	}
-
-	return call
 }
 
 func (e *Endpoint) Notify(serviceMethod string, args ...interface{}) error {
@@ -213,28 +466,90 @@ func (e *Endpoint) Notify(serviceMethod string, args ...interface{}) error {
 	return nil
 }
 
-var errorType = reflect.ValueOf(new(error)).Elem().Type()
+// FirstArg returns the first argument passed to handlers or nil if the
+// endpoint does not have a first argument.
+func (e *Endpoint) FirstArg() interface{} {
+	if !e.arg.IsValid() {
+		return nil
+	}
+	return e.arg.Interface()
+}
+
+var (
+	errorType   = reflect.ValueOf(new(error)).Elem().Type()
+	contextType = reflect.ValueOf(new(context.Context)).Elem().Type()
+)
 
-func (e *Endpoint) RegisterHandler(serviceMethod string, function interface{}) error {
+// RegisterHandler registers function as the handler for serviceMethod. The
+// first argument to function is the value specified with the WithFirstArg
+// option, if any. The next argument is optionally a context.Context. The
+// context is cancelled when the handler returns or the endpoint is closed.
+// The remaining arguments are decoded from the request.
+//
+// By default, each call to the handler runs in a new goroutine. Use the
+// Serial, Parallel, LatestWins and InQueue options to limit and order the
+// calls.
+func (e *Endpoint) RegisterHandler(serviceMethod string, function interface{}, options ...HandlerOption) error {
+	h, err := e.newHandler(function, options)
+	if err != nil {
+		return err
+	}
+	e.handlersMu.Lock()
+	e.handlers[serviceMethod] = h
+	e.handlersMu.Unlock()
+	return nil
+}
+
+// newHandler checks that function is a valid handler for the endpoint and
+// returns the handler.
+func (e *Endpoint) newHandler(function interface{}, options []HandlerOption) (*handler, error) {
 	v := reflect.ValueOf(function)
-	t := v.Type()
-	if t.Kind() != reflect.Func {
-		return errors.New("msgpack/rpc: handler not a function")
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, errors.New("msgpack/rpc: handler not a function")
 	}
+	t := v.Type()
 
-	if e.arg.IsValid() && (t.NumIn() == 0 || t.In(0) != e.arg.Type()) {
-		return fmt.Errorf("msgpack/rpc: first handler arg must be type %s", e.arg.Type())
+	argIndex := 0
+	if e.arg.IsValid() {
+		if t.NumIn() == 0 || t.In(0) != e.arg.Type() {
+			return nil, fmt.Errorf("msgpack/rpc: first handler arg must be type %s", e.arg.Type())
+		}
+		argIndex++
 	}
 
 	if t.NumOut() > 2 || (t.NumOut() > 1 && t.Out(t.NumOut()-1) != errorType) {
-		return errors.New("msgpack/rpc: handler return must be (), (error) or (valueType, error)")
+		return nil, errors.New("msgpack/rpc: handler return must be (), (error) or (valueType, error)")
+	}
+
+	h := &handler{fn: v}
+	if t.NumIn() > argIndex && t.In(argIndex) == contextType {
+		h.hasContext = true
+	}
+
+	for _, option := range options {
+		if option.f != nil {
+			option.f(h)
+		}
+	}
+	if h.dispatcher != nil && h.dispatcher.max < 1 {
+		return nil, errors.New("msgpack/rpc: handler concurrency must be at least one")
 	}
+	return h, nil
+}
+
+// UnregisterHandler removes the handler for serviceMethod. Calls to the
+// handler that are in progress are not affected. The endpoint replies to
+// later requests for serviceMethod with an error.
+func (e *Endpoint) UnregisterHandler(serviceMethod string) {
 	e.handlersMu.Lock()
-	e.handlers[serviceMethod] = v
+	delete(e.handlers, serviceMethod)
 	e.handlersMu.Unlock()
-	return nil
 }
 
+// reply sends a reply to the peer. If replyErr or an error in its chain has
+// type Error, then the error's value is sent to the peer. If replyErr or an
+// error in its chain implements msgpack.Marshaler, then the error encodes
+// itself. Otherwise, the error's message is sent.
 func (e *Endpoint) reply(id uint64, replyErr error, reply interface{}) error {
 	e.packMu.Lock()
 	defer e.packMu.Unlock()
@@ -254,12 +569,16 @@ func (e *Endpoint) reply(id uint64, replyErr error, reply interface{}) error {
 		return err
 	}
 
+	var (
+		ee Error
+		em msgpack.Marshaler
+	)
 	if replyErr == nil {
 		err = e.enc.PackNil()
-	} else if ee, ok := replyErr.(Error); ok {
+	} else if errors.As(replyErr, &ee) {
 		err = e.enc.Encode(ee.Value)
-	} else if ee, ok := replyErr.(msgpack.Marshaler); ok {
-		err = ee.MarshalMsgPack(e.enc)
+	} else if errors.As(replyErr, &em) {
+		err = em.MarshalMsgPack(e.enc)
 	} else {
 		err = e.enc.PackString(replyErr.Error())
 	}
@@ -270,6 +589,14 @@ func (e *Endpoint) reply(id uint64, replyErr error, reply interface{}) error {
 	return e.enc.Encode(reply)
 }
 
+// handlerReply sends the reply for a handler call. Errors are fatal to the
+// endpoint unless the endpoint was closed while the handler was running.
+func (e *Endpoint) handlerReply(id uint64, replyErr error, reply interface{}) {
+	if err := e.reply(id, replyErr, reply); err != nil && !e.isClosed() {
+		e.fatal(err)
+	}
+}
+
 func (e *Endpoint) Serve() error {
 	for {
 		if err := e.dec.Unpack(); err != nil {
@@ -357,7 +684,8 @@ func (e *Endpoint) handleReply(messageLen int) error {
 	return nil
 }
 
-func (e *Endpoint) createCall(f reflect.Value) (func([]reflect.Value) []reflect.Value, []reflect.Value, error) {
+func (e *Endpoint) createCall(ctx context.Context, h *handler) (func([]reflect.Value) []reflect.Value, []reflect.Value, error) {
+	f := h.fn
 	t := f.Type()
 	args := make([]reflect.Value, t.NumIn())
 	argIndex := 0
@@ -365,6 +693,10 @@ func (e *Endpoint) createCall(f reflect.Value) (func([]reflect.Value) []reflect.
 		args[argIndex] = e.arg
 		argIndex++
 	}
+	if h.hasContext {
+		args[argIndex] = reflect.ValueOf(&ctx).Elem()
+		argIndex++
+	}
 
 	if err := e.dec.Unpack(); err != nil {
 		return nil, nil, err
@@ -440,6 +772,62 @@ func (e *Endpoint) createCall(f reflect.Value) (func([]reflect.Value) []reflect.
 	return f.CallSlice, args, nil
 }
 
+// callHandler calls the handler through the server interceptors and returns
+// the handler's result and error.
+func (e *Endpoint) callHandler(ctx context.Context, serviceMethod string, h *handler, call func([]reflect.Value) []reflect.Value, args []reflect.Value) (interface{}, error) {
+	if len(e.serverInterceptors) == 0 {
+		return e.invokeHandler(serviceMethod, h, call, args)
+	}
+
+	argIndex := 0
+	if e.arg.IsValid() {
+		argIndex++
+	}
+	ctxIndex := argIndex
+	if h.hasContext {
+		argIndex++
+	}
+	iargs := make([]interface{}, len(args)-argIndex)
+	for i, arg := range args[argIndex:] {
+		iargs[i] = arg.Interface()
+	}
+
+	handle := chainServer(e.serverInterceptors, serviceMethod, iargs, func(ctx context.Context) (interface{}, error) {
+		if h.hasContext {
+			args[ctxIndex] = reflect.ValueOf(&ctx).Elem()
+		}
+		return e.invokeHandler(serviceMethod, h, call, args)
+	})
+	return handle(ctx)
+}
+
+// invokeHandler calls the handler and returns the handler's result and error.
+// Panics in the handler are returned as a *PanicError unless the endpoint was
+// created with the WithFailFast option.
+func (e *Endpoint) invokeHandler(serviceMethod string, h *handler, call func([]reflect.Value) []reflect.Value, args []reflect.Value) (result interface{}, err error) {
+	if !e.failFast {
+		defer func() {
+			if r := recover(); r != nil {
+				const size = 64 << 10
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+				e.logf("msgpack/rpc: panic in service method %s: %v\n%s", serviceMethod, r, buf)
+				result = nil
+				err = &PanicError{ServiceMethod: serviceMethod, Value: r, Stack: buf}
+			}
+		}()
+	}
+	out := call(args)
+	switch h.fn.Type().NumOut() {
+	case 1:
+		err, _ = out[0].Interface().(error)
+	case 2:
+		result = out[0].Interface()
+		err, _ = out[1].Interface().(error)
+	}
+	return result, err
+}
+
 func (e *Endpoint) handleRequest(messageLen int) error {
 	if messageLen != 4 {
 		// messageType, id, serviceMethod, args
@@ -457,7 +845,7 @@ func (e *Endpoint) handleRequest(messageLen int) error {
 	}
 
 	e.handlersMu.RLock()
-	f, ok := e.handlers[serviceMethod]
+	h, ok := e.handlers[serviceMethod]
 	e.handlersMu.RUnlock()
 
 	if !ok {
@@ -468,29 +856,32 @@ func (e *Endpoint) handleRequest(messageLen int) error {
 		return e.reply(id, fmt.Errorf("unknown request method: %s", serviceMethod), nil)
 	}
 
-	call, args, err := e.createCall(f)
+	ctx, cancel := context.WithCancel(e.ctx)
+	call, args, err := e.createCall(ctx, h)
 	if _, ok := err.(*msgpack.DecodeConvertError); ok {
+		cancel()
 		e.logf("msgpack/rpc: %s: %v", serviceMethod, err)
 		return e.reply(id, errors.New("invalid argument"), nil)
 	} else if err != nil {
+		cancel()
 		return err
 	}
 
-	go func() {
-		out := call(args)
-		var replyErr error
-		var replyVal interface{}
-		switch f.Type().NumOut() {
-		case 1:
-			replyErr, _ = out[0].Interface().(error)
-		case 2:
-			replyVal = out[0].Interface()
-			replyErr, _ = out[1].Interface().(error)
-		}
-		if err := e.reply(id, replyErr, replyVal); err != nil {
-			e.fatal(err)
-		}
-	}()
+	if !e.startHandler() {
+		cancel()
+		return e.reply(id, errShutdown, nil)
+	}
+
+	h.dispatch(func() {
+		defer e.running.Done()
+		defer cancel()
+		replyVal, replyErr := e.callHandler(ctx, serviceMethod, h, call, args)
+		e.handlerReply(id, replyErr, replyVal)
+	}, func() {
+		defer e.running.Done()
+		cancel()
+		e.handlerReply(id, errSuperseded, nil)
+	})
 	return nil
 }
 
@@ -506,7 +897,7 @@ func (e *Endpoint) handleNotification(messageLen int) error {
 	}
 
 	e.handlersMu.RLock()
-	f, ok := e.handlers[serviceMethod]
+	h, ok := e.handlers[serviceMethod]
 	e.handlersMu.RUnlock()
 
 	if !ok {
@@ -514,20 +905,30 @@ func (e *Endpoint) handleNotification(messageLen int) error {
 		return e.skip(1)
 	}
 
-	call, args, err := e.createCall(f)
+	ctx, cancel := context.WithCancel(e.ctx)
+	call, args, err := e.createCall(ctx, h)
 	if err != nil {
+		cancel()
 		return err
 	}
 
-	go func() {
-		out := call(args)
-		if len(out) > 0 {
-			replyErr, _ := out[len(out)-1].Interface().(error)
-			if replyErr != nil {
-				e.logf("msgpack/rpc: service method %s returned %v", serviceMethod, replyErr)
-			}
+	if !e.startHandler() {
+		cancel()
+		e.logf("msgpack/rpc: notification %s dropped during shutdown", serviceMethod)
+		return nil
+	}
+
+	h.dispatch(func() {
+		defer e.running.Done()
+		defer cancel()
+		_, replyErr := e.callHandler(ctx, serviceMethod, h, call, args)
+		if replyErr != nil {
+			e.logf("msgpack/rpc: service method %s returned %v", serviceMethod, replyErr)
 		}
-	}()
+	}, func() {
+		defer e.running.Done()
+		cancel()
+	})
 
 	return nil
 }
@@ -5,11 +5,16 @@
 package rpc
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func clientServer(t *testing.T, options ...Option) (*Endpoint, *Endpoint, func()) {
@@ -91,6 +96,30 @@ func TestEndpoint(t *testing.T) {
 	}
 }
 
+func TestUnregisterHandler(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	if err := server.RegisterHandler("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+	var sum int
+	if err := client.Call("add", &sum, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3 {
+		t.Fatalf("sum = %d, want 3", sum)
+	}
+
+	server.UnregisterHandler("add")
+	if err := client.Call("add", &sum, 1, 2); err == nil {
+		t.Error("call to unregistered handler did not return error")
+	}
+
+	// Unregistering a method without a handler does nothing.
+	server.UnregisterHandler("add")
+}
+
 var argsTests = []struct {
 	sm     string
 	args   []interface{}
@@ -117,7 +146,7 @@ func TestArgs(t *testing.T) {
 	defer cleanup()
 
 	if err := server.RegisterHandler("n", func(a, b string) ([]string, error) {
-		return append([]string{a, b}), nil
+		return []string{a, b}, nil
 	}); err != nil {
 		t.Fatal(err)
 	}
@@ -165,3 +194,500 @@ func TestFirstArg(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestCallContext(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	unblock := make(chan struct{})
+	if err := server.RegisterHandler("block", func() (string, error) {
+		<-unblock
+		return "late", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterHandler("echo", func(s string) (string, error) { return s, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var result string
+	if err := client.CallContext(ctx, "block", &result); err != context.DeadlineExceeded {
+		t.Fatalf("CallContext returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	client.mu.Lock()
+	n := len(client.pending)
+	client.mu.Unlock()
+	if n != 0 {
+		t.Errorf("%d pending calls after cancel, want 0", n)
+	}
+
+	// The late reply to the abandoned call is skipped.
+	close(unblock)
+
+	if err := client.CallContext(context.Background(), "echo", &result, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "hello" {
+		t.Errorf("result = %q, want %q", result, "hello")
+	}
+}
+
+func TestCallContextInterceptor(t *testing.T) {
+	release := make(chan struct{})
+	released := make(chan error, 1)
+	client, server, cleanup := clientServer(t, WithClientInterceptor(func(call *Call, invoke Invoker) error {
+		<-release
+		err := invoke(call)
+		released <- err
+		return err
+	}))
+	defer cleanup()
+
+	called := make(chan struct{}, 1)
+	if err := server.RegisterHandler("f", func() { called <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, "f", nil); err != context.DeadlineExceeded {
+		t.Fatalf("CallContext returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The abandoned call is not sent when the interceptor invokes it.
+	close(release)
+	if err := <-released; err != context.DeadlineExceeded {
+		t.Errorf("invoke returned %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := client.Call("f", nil); err != nil {
+		t.Fatal(err)
+	}
+	<-called
+	select {
+	case <-called:
+		t.Error("abandoned call was sent")
+	default:
+	}
+}
+
+func TestHandlerContext(t *testing.T) {
+	client, server, cleanup := clientServer(t, WithFirstArg("hello"))
+	defer cleanup()
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	if err := server.RegisterHandler("wait", func(hello string, ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.RegisterHandler("args", func(hello string, ctx context.Context, a, b int) (int, error) {
+		if ctx == nil {
+			t.Error("nil context")
+		}
+		return a + b, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var sum int
+	if err := client.Call("args", &sum, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3 {
+		t.Errorf("sum = %d, want %d", sum, 3)
+	}
+
+	if err := client.Notify("wait"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	server.Close()
+
+	select {
+	case err := <-stopped:
+		if err != context.Canceled {
+			t.Errorf("ctx.Err() = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler context not cancelled on close")
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	if err := server.RegisterHandler("explode", func() error { panic("boom") }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := client.Call("explode", nil)
+	e, ok := err.(Error)
+	if !ok {
+		t.Fatalf("Call returned %T %v, want rpc.Error", err, err)
+	}
+	a, ok := e.Value.([]interface{})
+	if !ok || len(a) != 2 {
+		t.Fatalf("error value = %#v, want [type, message]", e.Value)
+	}
+	if msg, _ := a[1].(string); !strings.Contains(msg, "explode") || !strings.Contains(msg, "boom") {
+		t.Errorf("error message %q does not contain method name and panic value", msg)
+	}
+
+	// The endpoint continues to serve requests after the panic.
+	if err := server.RegisterHandler("ok", func() (string, error) { return "ok", nil }); err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	if err := client.Call("ok", &result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	if err := server.RegisterHandler("wait", func() (string, error) {
+		close(started)
+		<-release
+		return "done", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterHandler("ok", func() (string, error) { return "ok", nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	var result string
+	call := client.Go("wait", make(chan *Call, 1), &result)
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(context.Background()) }()
+
+	// Wait for the endpoint to reject requests.
+	for {
+		if err := client.Call("ok", nil); err != nil {
+			if e, ok := err.(Error); !ok || e.Value != errShutdown.Error() {
+				t.Fatalf("Call during shutdown returned %v, want %v", err, errShutdown)
+			}
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	if err := (<-call.Done).Err; err != nil {
+		t.Fatalf("running call returned %v", err)
+	}
+	if result != "done" {
+		t.Errorf("result = %q, want %q", result, "done")
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown returned %v", err)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	started := make(chan struct{})
+	if err := server.RegisterHandler("block", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	call := client.Go("block", make(chan *Call, 1), nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The handler's reply after the endpoint is closed is dropped and the
+	// client's call fails.
+	if err := (<-call.Done).Err; err == nil {
+		t.Error("call to closed endpoint did not fail")
+	}
+}
+
+func TestSerial(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	var got []int
+	done := make(chan struct{})
+	const n = 50
+	if err := server.RegisterHandler("n", func(i int) {
+		got = append(got, i)
+		if i == n-1 {
+			close(done)
+		}
+	}, Serial()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < n; i++ {
+		if err := client.Notify("n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+
+	for i := range got {
+		if got[i] != i {
+			t.Fatalf("got %v, want calls in arrival order", got)
+		}
+	}
+}
+
+func TestQueue(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	var got []string
+	done := make(chan struct{})
+	const n = 50
+	q := NewQueue()
+	for _, sm := range []string{"a", "b"} {
+		sm := sm
+		if err := server.RegisterHandler(sm, func(i int) {
+			got = append(got, fmt.Sprintf("%s%d", sm, i))
+			if sm == "b" && i == n-1 {
+				close(done)
+			}
+		}, InQueue(q)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var want []string
+	for i := 0; i < n; i++ {
+		for _, sm := range []string{"a", "b"} {
+			if err := client.Notify(sm, i); err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, fmt.Sprintf("%s%d", sm, i))
+		}
+	}
+	<-done
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want calls in arrival order", got)
+	}
+}
+
+func TestLatestWins(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	if err := server.RegisterHandler("r", func(i int) (int, error) {
+		if i == 0 {
+			started <- struct{}{}
+			<-release
+		}
+		return i, nil
+	}, LatestWins()); err != nil {
+		t.Fatal(err)
+	}
+
+	results := make([]int, 4)
+	calls := make([]*Call, len(results))
+	calls[0] = client.Go("r", nil, &results[0], 0)
+	<-started
+	for i := 1; i < len(calls); i++ {
+		calls[i] = client.Go("r", nil, &results[i], i)
+	}
+
+	// Wait for the stale requests to be dropped.
+	for i := 1; i < len(calls)-1; i++ {
+		c := <-calls[i].Done
+		if c.Err == nil {
+			t.Errorf("call %d succeeded, want superseded error", i)
+		}
+	}
+	close(release)
+
+	for _, i := range []int{0, len(calls) - 1} {
+		c := <-calls[i].Done
+		if c.Err != nil {
+			t.Errorf("call %d returned error %v", i, c.Err)
+		}
+		if results[i] != i {
+			t.Errorf("call %d result = %d, want %d", i, results[i], i)
+		}
+	}
+}
+
+func TestDispatchPerHandler(t *testing.T) {
+	client, server, cleanup := clientServer(t)
+	defer cleanup()
+
+	// Handlers registered with the same option value have separate queues.
+	latest := LatestWins()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	if err := server.RegisterHandler("a", func(i int) (int, error) {
+		if i == 0 {
+			started <- struct{}{}
+			<-release
+		}
+		return i, nil
+	}, latest); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterHandler("b", func(i int) (int, error) { return i, nil }, latest); err != nil {
+		t.Fatal(err)
+	}
+
+	var a int
+	blocked := client.Go("a", nil, &a, 0)
+	<-started
+	pending := client.Go("a", nil, new(int), 1)
+	for i := 0; i < 3; i++ {
+		var b int
+		if err := client.Call("b", &b, i); err != nil {
+			t.Errorf("call %d to b returned error %v while a is blocked", i, err)
+		}
+	}
+	close(release)
+	for _, c := range []*Call{<-blocked.Done, <-pending.Done} {
+		if c.Err != nil {
+			t.Errorf("call to a returned error %v", c.Err)
+		}
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		trace []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		trace = append(trace, s)
+		mu.Unlock()
+	}
+
+	client, server, cleanup := clientServer(t,
+		WithClientInterceptor(func(call *Call, invoke Invoker) error {
+			record("c1 " + call.ServiceMethod)
+			return invoke(call)
+		}),
+		WithClientInterceptor(func(call *Call, invoke Invoker) error {
+			record("c2 " + call.ServiceMethod)
+			if call.ServiceMethod == "fault" {
+				return errors.New("injected fault")
+			}
+			return invoke(call)
+		}),
+		WithServerInterceptor(func(ctx context.Context, sm string, args []interface{}, handle Handler) (interface{}, error) {
+			record(fmt.Sprintf("s1 %s %v", sm, args))
+			if sm == "secret" {
+				return nil, errors.New("permission denied")
+			}
+			return handle(ctx)
+		}),
+	)
+	defer cleanup()
+
+	if err := server.RegisterHandler("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterHandler("secret", func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	var sum int
+	if err := client.Call("add", &sum, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3 {
+		t.Errorf("sum = %d, want %d", sum, 3)
+	}
+
+	if err := client.Call("fault", nil); err == nil || err.Error() != "injected fault" {
+		t.Errorf("fault returned %v, want injected fault", err)
+	}
+
+	if err := client.Call("secret", nil); err == nil {
+		t.Errorf("secret returned nil error, want permission denied")
+	}
+
+	want := []string{
+		"c1 add", "c2 add", "s1 add [1 2]",
+		"c1 fault", "c2 fault",
+		"c1 secret", "c2 secret", "s1 secret []",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %q, want %q", trace, want)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	client, server, cleanup := clientServer(t, WithMetrics(m))
+
+	if err := server.RegisterHandler("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterHandler("fail", func() error { return errors.New("fail") }); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := client.Call("add", nil, 1, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Call("fail", nil); err == nil {
+		t.Fatal("fail returned nil error")
+	}
+
+	s := m.Snapshot()
+	if s.Pending != 0 || s.InFlight != 0 {
+		t.Errorf("pending, inFlight = %d, %d, want 0, 0", s.Pending, s.InFlight)
+	}
+	for _, stats := range []map[string]MethodStats{s.Client, s.Server} {
+		if add := stats["add"]; add.Count != 3 || add.Errors != 0 || len(add.Latency) != len(LatencyBuckets)+1 {
+			t.Errorf("add stats = %+v, want 3 calls and no errors", add)
+		}
+		if fail := stats["fail"]; fail.Count != 1 || fail.Errors != 1 {
+			t.Errorf("fail stats = %+v, want 1 call and 1 error", fail)
+		}
+	}
+
+	// Client metrics are recorded without a client interceptor.
+	if len(client.clientInterceptors) != 0 {
+		t.Errorf("client has %d client interceptors, want 0", len(client.clientInterceptors))
+	}
+
+	cleanup()
+	m.mu.Lock()
+	n := len(m.endpoints)
+	m.mu.Unlock()
+	if n != 0 {
+		t.Errorf("metrics has %d endpoints after close, want 0", n)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import "context"
+
+// Invoker sends a call to the peer and waits for the call to complete.
+type Invoker func(call *Call) error
+
+// ClientInterceptor intercepts calls made with the Go, Call and CallContext
+// methods. The interceptor calls invoke to send the call to the peer and wait
+// for the reply, or returns an error to fail the call without sending it. The
+// error returned from the interceptor is the error for the call.
+//
+// The Go method returns after the interceptors send the call or fail the
+// call. Interceptors that send the call without delay preserve the order of
+// calls.
+type ClientInterceptor func(call *Call, invoke Invoker) error
+
+// Handler invokes a registered handler. The result is the handler's return
+// value, if any.
+type Handler func(ctx context.Context) (interface{}, error)
+
+// ServerInterceptor intercepts calls to handlers registered with
+// RegisterHandler. The args are the arguments decoded from the peer's message.
+// The interceptor calls handle to invoke the handler, or returns an error
+// without calling the handler. The result and error returned from the
+// interceptor are sent to the peer in reply to a request.
+type ServerInterceptor func(ctx context.Context, serviceMethod string, args []interface{}, handle Handler) (interface{}, error)
+
+// WithClientInterceptor adds an interceptor for outgoing calls. Interceptors
+// are called in the order that they are added.
+func WithClientInterceptor(interceptor ClientInterceptor) Option {
+	return Option{func(e *Endpoint) {
+		e.clientInterceptors = append(e.clientInterceptors, interceptor)
+	}}
+}
+
+// WithServerInterceptor adds an interceptor for incoming calls. Interceptors
+// are called in the order that they are added.
+func WithServerInterceptor(interceptor ServerInterceptor) Option {
+	return Option{func(e *Endpoint) {
+		e.serverInterceptors = append(e.serverInterceptors, interceptor)
+	}}
+}
+
+func chainClient(interceptors []ClientInterceptor, invoke Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(call *Call) error {
+			return interceptor(call, next)
+		}
+	}
+	return invoke
+}
+
+func chainServer(interceptors []ServerInterceptor, serviceMethod string, args []interface{}, handle Handler) Handler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handle
+		handle = func(ctx context.Context) (interface{}, error) {
+			return interceptor(ctx, serviceMethod, args, next)
+		}
+	}
+	return handle
+}
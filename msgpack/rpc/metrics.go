@@ -0,0 +1,178 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets in the latency
+// histograms collected by Metrics. The last bucket in a histogram counts the
+// calls that take longer than the last bound.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Metrics collects statistics for the calls made and handled by endpoints. A
+// Metrics value can be shared by more than one endpoint. Use the WithMetrics
+// option to enable collection for an endpoint.
+//
+// To publish the statistics with the expvar package, use:
+//
+//	expvar.Publish(name, expvar.Func(func() interface{} { return m.Snapshot() }))
+type Metrics struct {
+	mu       sync.Mutex
+	client   map[string]*MethodStats
+	server   map[string]*MethodStats
+	inFlight int
+
+	// endpoints is the set of open endpoints using the metrics. Endpoints
+	// remove themselves when closed.
+	endpoints map[*Endpoint]struct{}
+}
+
+// MethodStats holds the statistics for a method.
+type MethodStats struct {
+	// Count is the number of completed calls.
+	Count int64 `json:"count"`
+
+	// Errors is the number of calls that returned an error.
+	Errors int64 `json:"errors"`
+
+	// TotalTime is the total time spent in the calls.
+	TotalTime time.Duration `json:"total_time"`
+
+	// Latency is a histogram of call durations. Latency[i] is the number of
+	// calls with duration less than or equal to LatencyBuckets[i] and greater
+	// than the previous bucket. The last element counts the calls longer than
+	// all of LatencyBuckets.
+	Latency []int64 `json:"latency"`
+}
+
+// MetricsSnapshot is a point in time copy of the statistics in Metrics.
+type MetricsSnapshot struct {
+	// Pending is the number of outgoing calls waiting for a reply.
+	Pending int `json:"pending"`
+
+	// InFlight is the number of handlers running.
+	InFlight int `json:"in_flight"`
+
+	// Client holds the statistics for outgoing calls by method.
+	Client map[string]MethodStats `json:"client"`
+
+	// Server holds the statistics for handled calls by method.
+	Server map[string]MethodStats `json:"server"`
+}
+
+// NewMetrics returns a new Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		client:    make(map[string]*MethodStats),
+		server:    make(map[string]*MethodStats),
+		endpoints: make(map[*Endpoint]struct{}),
+	}
+}
+
+// WithMetrics collects statistics for the endpoint in m.
+func WithMetrics(m *Metrics) Option {
+	return Option{func(e *Endpoint) {
+		m.mu.Lock()
+		m.endpoints[e] = struct{}{}
+		m.mu.Unlock()
+		e.metrics = m
+		e.serverInterceptors = append(e.serverInterceptors, m.interceptServer)
+	}}
+}
+
+// removeEndpoint removes a closed endpoint from the metrics.
+func (m *Metrics) removeEndpoint(e *Endpoint) {
+	m.mu.Lock()
+	delete(m.endpoints, e)
+	m.mu.Unlock()
+}
+
+// recordCall records the statistics for a completed outgoing call.
+func (m *Metrics) recordCall(call *Call) {
+	m.record(m.client, call.ServiceMethod, time.Since(call.start), call.Err)
+}
+
+func (m *Metrics) interceptServer(ctx context.Context, serviceMethod string, args []interface{}, handle Handler) (interface{}, error) {
+	m.mu.Lock()
+	m.inFlight++
+	m.mu.Unlock()
+	start := time.Now()
+	result, err := handle(ctx)
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+	m.record(m.server, serviceMethod, time.Since(start), err)
+	return result, err
+}
+
+func (m *Metrics) record(stats map[string]*MethodStats, serviceMethod string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := stats[serviceMethod]
+	if s == nil {
+		s = &MethodStats{Latency: make([]int64, len(LatencyBuckets)+1)}
+		stats[serviceMethod] = s
+	}
+	s.Count++
+	if err != nil {
+		s.Errors++
+	}
+	s.TotalTime += d
+	i := 0
+	for i < len(LatencyBuckets) && d > LatencyBuckets[i] {
+		i++
+	}
+	s.Latency[i]++
+}
+
+// Snapshot returns a copy of the current statistics.
+func (m *Metrics) Snapshot() *MetricsSnapshot {
+	m.mu.Lock()
+	snapshot := &MetricsSnapshot{
+		InFlight: m.inFlight,
+		Client:   copyStats(m.client),
+		Server:   copyStats(m.server),
+	}
+	endpoints := make([]*Endpoint, 0, len(m.endpoints))
+	for e := range m.endpoints {
+		endpoints = append(endpoints, e)
+	}
+	m.mu.Unlock()
+
+	// Count the pending calls without holding m.mu. Endpoints record
+	// completed calls while holding the endpoint's mu.
+	for _, e := range endpoints {
+		e.mu.Lock()
+		snapshot.Pending += len(e.pending)
+		e.mu.Unlock()
+	}
+	return snapshot
+}
+
+func copyStats(stats map[string]*MethodStats) map[string]MethodStats {
+	m := make(map[string]MethodStats, len(stats))
+	for k, s := range stats {
+		c := *s
+		c.Latency = append([]int64(nil), s.Latency...)
+		m[k] = c
+	}
+	return m
+}
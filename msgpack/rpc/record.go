@@ -0,0 +1,328 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/garyburd/neovim-go/msgpack"
+)
+
+// Message directions in a recorded session.
+const (
+	// DirectionIn is the direction of messages read from the peer.
+	DirectionIn = "in"
+
+	// DirectionOut is the direction of messages written to the peer.
+	DirectionOut = "out"
+)
+
+// Record is a message in a recorded session.
+type Record struct {
+	// Direction is DirectionIn or DirectionOut.
+	Direction string `msgpack:",array"`
+
+	// Time is the time that the message was decoded in nanoseconds since the
+	// Unix epoch.
+	Time int64
+
+	// Message is the decoded message.
+	Message interface{}
+}
+
+type recorder struct {
+	conn io.ReadWriteCloser
+	in   *messageReader
+	out  *messageReader
+	wg   sync.WaitGroup
+
+	mu  sync.Mutex
+	enc *msgpack.Encoder
+}
+
+// NewRecorder returns a connection that records the messages read from and
+// written to conn. Use the returned connection in place of conn when creating
+// an endpoint. The recorder decodes each message and writes the message to w
+// as a Record. A message is recorded before the bytes are returned from Read
+// or written to conn, so the order of the records matches the order of cause
+// and effect in the session. Replay a recorded session with a Replayer.
+func NewRecorder(conn io.ReadWriteCloser, w io.Writer) io.ReadWriteCloser {
+	r := &recorder{conn: conn, enc: msgpack.NewEncoder(w)}
+	r.in = r.start(DirectionIn)
+	r.out = r.start(DirectionOut)
+	return r
+}
+
+func (r *recorder) start(direction string) *messageReader {
+	mr := &messageReader{
+		chunks: make(chan []byte),
+		ack:    make(chan struct{}),
+		quit:   make(chan struct{}),
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer mr.close()
+		dec := msgpack.NewDecoder(mr)
+		for {
+			var message interface{}
+			err := dec.Decode(&message)
+			if _, ok := err.(*msgpack.DecodeConvertError); !ok && err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.enc.Encode(&Record{Direction: direction, Time: time.Now().UnixNano(), Message: message})
+			r.mu.Unlock()
+		}
+	}()
+	return mr
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.conn.Read(p)
+	if n > 0 {
+		r.in.feed(p[:n])
+	}
+	return n, err
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.out.feed(p)
+	return r.conn.Write(p)
+}
+
+func (r *recorder) Close() error {
+	err := r.conn.Close()
+	r.in.close()
+	r.out.close()
+	r.wg.Wait()
+	return err
+}
+
+// messageReader is the reader for a recorder's decoder. The feed method
+// returns after the decoder consumes the data and records the complete
+// messages in the data.
+type messageReader struct {
+	chunks  chan []byte
+	ack     chan struct{}
+	quit    chan struct{}
+	p       []byte
+	started bool
+	once    sync.Once
+}
+
+func (mr *messageReader) Read(p []byte) (int, error) {
+	if len(mr.p) == 0 {
+		if mr.started {
+			select {
+			case mr.ack <- struct{}{}:
+			case <-mr.quit:
+				return 0, io.EOF
+			}
+		}
+		mr.started = true
+		select {
+		case mr.p = <-mr.chunks:
+		case <-mr.quit:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, mr.p)
+	mr.p = mr.p[n:]
+	return n, nil
+}
+
+func (mr *messageReader) feed(p []byte) {
+	select {
+	case mr.chunks <- p:
+	case <-mr.quit:
+		return
+	}
+	select {
+	case <-mr.ack:
+	case <-mr.quit:
+	}
+}
+
+func (mr *messageReader) close() {
+	mr.once.Do(func() { close(mr.quit) })
+}
+
+// Divergence describes a difference between the messages written to a
+// Replayer and the recorded session.
+type Divergence struct {
+	// Index is the index of the expected record in the session or -1 if the
+	// session has no more outgoing messages.
+	Index int
+
+	// Want is the recorded message.
+	Want interface{}
+
+	// Got is the message written to the replayer.
+	Got interface{}
+}
+
+func (d *Divergence) Error() string {
+	if d.Index < 0 {
+		return fmt.Sprintf("msgpack/rpc: unexpected message %v", d.Got)
+	}
+	return fmt.Sprintf("msgpack/rpc: message %d is %v, want %v", d.Index, d.Got, d.Want)
+}
+
+// Replayer is a connection that plays back a recorded session. Read returns
+// the recorded incoming messages. The replayer delivers an incoming message
+// after the outgoing messages recorded before it are written. Read returns
+// io.EOF at the end of the session. The replayer compares the messages
+// written to it with the recorded outgoing messages and collects the
+// differences.
+//
+// To replay a session, create an endpoint with the replayer as the
+// connection, register the handlers and call Serve.
+type Replayer struct {
+	records []*Record
+	pw      *io.PipeWriter
+	done    chan struct{}
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	closed      bool
+	played      []bool
+	pos         int // index of first record not played
+	buf         []byte
+	divergences []*Divergence
+}
+
+// NewReplayer returns a replayer for the session recorded in r.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	rp := &Replayer{done: make(chan struct{})}
+	rp.cond = sync.NewCond(&rp.mu)
+	dec := msgpack.NewDecoder(r)
+	for {
+		var record Record
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if record.Direction != DirectionIn && record.Direction != DirectionOut {
+			return nil, fmt.Errorf("msgpack/rpc: invalid record direction %q", record.Direction)
+		}
+		rp.records = append(rp.records, &record)
+	}
+	rp.played = make([]bool, len(rp.records))
+
+	pr, pw := io.Pipe()
+	rp.pw = pw
+	go func() {
+		defer close(rp.done)
+		dec := msgpack.NewDecoder(pr)
+		for {
+			var message interface{}
+			err := dec.Decode(&message)
+			if _, ok := err.(*msgpack.DecodeConvertError); !ok && err != nil {
+				pr.CloseWithError(err)
+				return
+			}
+			rp.check(message)
+		}
+	}()
+	return rp, nil
+}
+
+// check compares message with the next outgoing record.
+func (rp *Replayer) check(message interface{}) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	i := rp.pos
+	for i < len(rp.records) && (rp.played[i] || rp.records[i].Direction != DirectionOut) {
+		i++
+	}
+	if i >= len(rp.records) {
+		rp.divergences = append(rp.divergences, &Divergence{Index: -1, Got: message})
+		return
+	}
+	if !bytes.Equal(encode(rp.records[i].Message), encode(message)) {
+		rp.divergences = append(rp.divergences, &Divergence{Index: i, Want: rp.records[i].Message, Got: message})
+	}
+	rp.played[i] = true
+	rp.advance()
+	rp.cond.Broadcast()
+}
+
+// advance advances pos past the played records.
+func (rp *Replayer) advance() {
+	for rp.pos < len(rp.records) && rp.played[rp.pos] {
+		rp.pos++
+	}
+}
+
+func encode(v interface{}) []byte {
+	var buf bytes.Buffer
+	msgpack.NewEncoder(&buf).Encode(v)
+	return buf.Bytes()
+}
+
+// Read reads the recorded incoming messages.
+func (rp *Replayer) Read(p []byte) (int, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for len(rp.buf) == 0 {
+		for !rp.closed && rp.pos < len(rp.records) && rp.records[rp.pos].Direction == DirectionOut {
+			rp.cond.Wait()
+		}
+		if rp.closed || rp.pos >= len(rp.records) {
+			return 0, io.EOF
+		}
+		rp.buf = encode(rp.records[rp.pos].Message)
+		rp.played[rp.pos] = true
+		rp.advance()
+	}
+	n := copy(p, rp.buf)
+	rp.buf = rp.buf[n:]
+	return n, nil
+}
+
+// Write compares the written messages with the recorded outgoing messages.
+func (rp *Replayer) Write(p []byte) (int, error) {
+	return rp.pw.Write(p)
+}
+
+// Close closes the replayer.
+func (rp *Replayer) Close() error {
+	rp.mu.Lock()
+	rp.closed = true
+	rp.cond.Broadcast()
+	rp.mu.Unlock()
+	rp.pw.Close()
+	<-rp.done
+	return nil
+}
+
+// Divergences returns the differences found between the written messages and
+// the recorded session.
+func (rp *Replayer) Divergences() []*Divergence {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return append([]*Divergence(nil), rp.divergences...)
+}
+
+// Err returns the first difference between the written messages and the
+// recorded session, or an error if part of the session was not played.
+func (rp *Replayer) Err() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if len(rp.divergences) > 0 {
+		return rp.divergences[0]
+	}
+	if rp.pos < len(rp.records) {
+		return fmt.Errorf("msgpack/rpc: %d of %d recorded messages not played", len(rp.records)-rp.pos, len(rp.records))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func recordSession(t *testing.T) []byte {
+	serverConn, clientConn := net.Pipe()
+	var buf bytes.Buffer
+
+	server, err := NewEndpoint(NewRecorder(serverConn, &buf), WithLogf(t.Logf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewEndpoint(clientConn, WithLogf(t.Logf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterHandler("add", func(a, b int) (int, error) { return a + b, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{}, 2)
+	go func() { server.Serve(); done <- struct{}{} }()
+	go func() { client.Serve(); done <- struct{}{} }()
+
+	for i := 0; i < 3; i++ {
+		var sum int
+		if err := client.Call("add", &sum, i, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client.Close()
+	<-done
+	<-done
+	return buf.Bytes()
+}
+
+func replaySession(t *testing.T, session []byte, add func(a, b int) (int, error)) *Replayer {
+	rp, err := NewReplayer(bytes.NewReader(session))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := NewEndpoint(rp, WithLogf(t.Logf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.RegisterHandler("add", add); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.Serve(); err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+func TestRecordReplay(t *testing.T) {
+	session := recordSession(t)
+
+	rp := replaySession(t, session, func(a, b int) (int, error) { return a + b, nil })
+	if err := rp.Err(); err != nil {
+		t.Errorf("replay returned error %v", err)
+	}
+
+	rp = replaySession(t, session, func(a, b int) (int, error) { return a * b, nil })
+	if d := rp.Divergences(); len(d) != 3 {
+		t.Errorf("replay with different handler found %d divergences, want 3", len(d))
+	}
+}
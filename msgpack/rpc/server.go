@@ -0,0 +1,247 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+)
+
+// ErrServerClosed is returned by the Server's Serve and ServeConn methods
+// after a call to Shutdown.
+var ErrServerClosed = errors.New("msgpack/rpc: server closed")
+
+// Server serves MessagePack RPC to multiple peers. The server creates an
+// endpoint for each connection and registers the handlers in the server's
+// handler table on the endpoint.
+type Server struct {
+	// Options specifies the options for the endpoint created for each
+	// connection.
+	Options []Option
+
+	// NewFirstArg, if not nil, returns the first argument passed to
+	// handlers called through endpoint e. The function is called once for
+	// each connection.
+	NewFirstArg func(e *Endpoint) interface{}
+
+	// OnConnect, if not nil, is called when the server starts serving a
+	// connection. The endpoint serves requests concurrently with the call
+	// to OnConnect.
+	OnConnect func(e *Endpoint)
+
+	// OnDisconnect, if not nil, is called after the server stops serving a
+	// connection. The argument err is the error returned from the
+	// endpoint's Serve method.
+	OnDisconnect func(e *Endpoint, err error)
+
+	mu        sync.Mutex
+	closed    bool
+	handlers  []serverHandler
+	listeners map[net.Listener]struct{}
+	endpoints map[*Endpoint]struct{}
+	wg        sync.WaitGroup
+}
+
+type serverHandler struct {
+	serviceMethod string
+	function      interface{}
+	options       []HandlerOption
+}
+
+// RegisterHandler adds a handler to the server's handler table. The handler
+// is registered on the endpoints for current and future connections. See
+// Endpoint.RegisterHandler for a description of the arguments. If the
+// handler is not valid for an endpoint, then RegisterHandler returns an
+// error and does not register the handler on any endpoint. The handler is
+// checked even when the server has no connections.
+//
+// Dispatch options apply to each connection separately. A handler registered
+// with the Serial option runs one call at a time per connection. The InQueue
+// option is the exception: a queue orders the calls from all connections.
+func (s *Server) RegisterHandler(serviceMethod string, function interface{}, options ...HandlerOption) error {
+	if err := s.checkHandler(function, options); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Check the handler against all endpoints before registering the
+	// handler on any endpoint.
+	handlers := make(map[*Endpoint]*handler, len(s.endpoints))
+	for e := range s.endpoints {
+		h, err := e.newHandler(function, options)
+		if err != nil {
+			return err
+		}
+		handlers[e] = h
+	}
+	for e, h := range handlers {
+		e.handlersMu.Lock()
+		e.handlers[serviceMethod] = h
+		e.handlersMu.Unlock()
+	}
+	s.handlers = append(s.handlers, serverHandler{serviceMethod: serviceMethod, function: function, options: options})
+	return nil
+}
+
+// checkHandler checks function and options on an endpoint created with the
+// server's options. The type of the first argument returned by NewFirstArg
+// is not known until a connection is served. The check uses the type of the
+// function's first argument and RegisterHandler checks the type against the
+// endpoints for the current connections.
+func (s *Server) checkHandler(function interface{}, options []HandlerOption) error {
+	e, err := NewEndpoint(discardConn{}, s.Options...)
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+	if s.NewFirstArg != nil {
+		t := reflect.TypeOf(function)
+		if t != nil && t.Kind() == reflect.Func {
+			if t.NumIn() == 0 {
+				return errors.New("msgpack/rpc: handler must have a first arg for NewFirstArg")
+			}
+			e.arg = reflect.Zero(t.In(0))
+		}
+	}
+	_, err = e.newHandler(function, options)
+	return err
+}
+
+// discardConn is the connection for the endpoint used to check handlers.
+type discardConn struct{}
+
+func (discardConn) Read(p []byte) (int, error)  { return 0, io.EOF }
+func (discardConn) Write(p []byte) (int, error) { return len(p), nil }
+func (discardConn) Close() error                { return nil }
+
+// Serve accepts connections on listener l and serves each connection in a
+// new goroutine. Serve always returns a non-nil error. After Shutdown, the
+// returned error is ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, l)
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves a single connection and blocks until the connection is
+// closed.
+func (s *Server) ServeConn(conn io.ReadWriteCloser) error {
+	e, err := s.newEndpoint(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer s.wg.Done()
+
+	if s.OnConnect != nil {
+		go s.OnConnect(e)
+	}
+
+	err = e.Serve()
+
+	s.mu.Lock()
+	delete(s.endpoints, e)
+	closed := s.closed
+	s.mu.Unlock()
+
+	if s.OnDisconnect != nil {
+		s.OnDisconnect(e, err)
+	}
+	if closed && err != nil {
+		err = ErrServerClosed
+	}
+	return err
+}
+
+func (s *Server) newEndpoint(conn io.ReadWriteCloser) (*Endpoint, error) {
+	e, err := NewEndpoint(conn, s.Options...)
+	if err != nil {
+		return nil, err
+	}
+	if s.NewFirstArg != nil {
+		e.arg = reflect.ValueOf(s.NewFirstArg(e))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrServerClosed
+	}
+	for _, h := range s.handlers {
+		if err := e.RegisterHandler(h.serviceMethod, h.function, h.options...); err != nil {
+			e.logf("msgpack/rpc: register %s: %v", h.serviceMethod, err)
+			return nil, err
+		}
+	}
+	if s.endpoints == nil {
+		s.endpoints = make(map[*Endpoint]struct{})
+	}
+	s.endpoints[e] = struct{}{}
+	s.wg.Add(1)
+	return e, nil
+}
+
+// Shutdown gracefully shuts down the server. Shutdown closes the server's
+// listeners, shuts down the endpoint for each connection as described in
+// Endpoint.Shutdown, and then waits for the connections to exit. If ctx is
+// done before the connections exit, Shutdown returns ctx.Err().
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for e := range s.endpoints {
+		go e.Shutdown(ctx)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type peer struct {
+	name string
+}
+
+func TestServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu           sync.Mutex
+		n            int
+		disconnected int
+		connected    = make(chan *Endpoint, 2)
+	)
+
+	s := &Server{
+		Options: []Option{WithLogf(t.Logf)},
+		NewFirstArg: func(e *Endpoint) interface{} {
+			mu.Lock()
+			defer mu.Unlock()
+			n++
+			return &peer{name: fmt.Sprintf("peer%d", n)}
+		},
+		OnConnect: func(e *Endpoint) {
+			connected <- e
+		},
+		OnDisconnect: func(e *Endpoint, err error) {
+			mu.Lock()
+			disconnected++
+			mu.Unlock()
+		},
+	}
+	if err := s.RegisterHandler("whoami", func(p *peer) (string, error) { return p.name, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- s.Serve(l) }()
+
+	names := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := NewEndpoint(conn, WithLogf(t.Logf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go client.Serve()
+		defer client.Close()
+
+		e := <-connected
+		if _, ok := e.FirstArg().(*peer); !ok {
+			t.Errorf("FirstArg() = %T, want *peer", e.FirstArg())
+		}
+
+		var name string
+		if err := client.Call("whoami", &name); err != nil {
+			t.Fatal(err)
+		}
+		names[name] = true
+	}
+	if !names["peer1"] || !names["peer2"] {
+		t.Errorf("whoami returned %v, want peer1 and peer2", names)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-serveErr; err != ErrServerClosed {
+		t.Errorf("Serve() returned %v, want %v", err, ErrServerClosed)
+	}
+	mu.Lock()
+	if disconnected != 2 {
+		t.Errorf("OnDisconnect called %d times, want 2", disconnected)
+	}
+	mu.Unlock()
+}
+
+func TestServerRegisterHandlerError(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		n         int
+		connected = make(chan *Endpoint, 2)
+	)
+	s := &Server{
+		Options: []Option{WithLogf(t.Logf)},
+		NewFirstArg: func(e *Endpoint) interface{} {
+			mu.Lock()
+			defer mu.Unlock()
+			n++
+			if n == 1 {
+				return &peer{name: "peer1"}
+			}
+			return "peer2"
+		},
+		OnConnect: func(e *Endpoint) {
+			connected <- e
+		},
+	}
+
+	var clients []*Endpoint
+	for i := 0; i < 2; i++ {
+		serverConn, clientConn := net.Pipe()
+		go s.ServeConn(serverConn)
+		client, err := NewEndpoint(clientConn, WithLogf(t.Logf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go client.Serve()
+		defer client.Close()
+		clients = append(clients, client)
+		<-connected
+	}
+
+	// The handler is valid for the first endpoint only.
+	if err := s.RegisterHandler("whoami", func(p *peer) (string, error) { return p.name, nil }); err == nil {
+		t.Fatal("RegisterHandler() returned nil error, want error for second endpoint")
+	}
+	var name string
+	if err := clients[0].Call("whoami", &name); err == nil {
+		t.Errorf("whoami returned %q, want error for handler that is not registered", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerRegisterInvalidHandler(t *testing.T) {
+	// The handlers are checked when no connections are served.
+	s := &Server{Options: []Option{WithLogf(t.Logf)}}
+	for _, fn := range []interface{}{nil, (func())(nil), "x", func() (int, int) { return 0, 0 }} {
+		if err := s.RegisterHandler("x", fn); err == nil {
+			t.Errorf("RegisterHandler(%T) returned nil error", fn)
+		}
+	}
+	if err := s.RegisterHandler("x", func() {}, Parallel(0)); err == nil {
+		t.Error("RegisterHandler(Parallel(0)) returned nil error")
+	}
+
+	s = &Server{NewFirstArg: func(e *Endpoint) interface{} { return &peer{} }}
+	if err := s.RegisterHandler("x", func() {}); err == nil {
+		t.Error("RegisterHandler(func()) returned nil error with NewFirstArg")
+	}
+	if err := s.RegisterHandler("x", func(p *peer, s string) {}); err != nil {
+		t.Errorf("RegisterHandler(func(*peer, string)) returned %v", err)
+	}
+	if len(s.handlers) != 1 {
+		t.Errorf("len(handlers) = %d, want 1", len(s.handlers))
+	}
+}
@@ -3,6 +3,7 @@
 package vim
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
@@ -15,6 +16,188 @@ const (
 	validationError = 1
 )
 
+// deprecatedFunctions maps deprecated API functions to the function that
+// replaces the deprecated function.
+var deprecatedFunctions = map[string]string{
+	"buffer_add_highlight":      "nvim_buf_add_highlight",
+	"buffer_clear_highlight":    "nvim_buf_clear_namespace",
+	"buffer_del_line":           "nvim_buf_set_lines",
+	"buffer_get_line":           "nvim_buf_get_lines",
+	"buffer_get_line_slice":     "nvim_buf_get_lines",
+	"buffer_get_lines":          "nvim_buf_get_lines",
+	"buffer_get_mark":           "nvim_buf_get_mark",
+	"buffer_get_name":           "nvim_buf_get_name",
+	"buffer_get_number":         "",
+	"buffer_get_option":         "nvim_buf_get_option",
+	"buffer_get_var":            "nvim_buf_get_var",
+	"buffer_insert":             "nvim_buf_set_lines",
+	"buffer_is_valid":           "nvim_buf_is_valid",
+	"buffer_line_count":         "nvim_buf_line_count",
+	"buffer_set_line":           "nvim_buf_set_lines",
+	"buffer_set_line_slice":     "nvim_buf_set_lines",
+	"buffer_set_lines":          "nvim_buf_set_lines",
+	"buffer_set_name":           "nvim_buf_set_name",
+	"buffer_set_option":         "nvim_buf_set_option",
+	"buffer_set_var":            "nvim_buf_set_var",
+	"nvim_buf_clear_highlight":  "nvim_buf_clear_namespace",
+	"nvim_buf_get_number":       "",
+	"nvim_buf_set_virtual_text": "nvim_buf_set_extmark",
+	"nvim_command_output":       "nvim_exec2",
+	"nvim_exec":                 "nvim_exec2",
+	"nvim_execute_lua":          "nvim_exec_lua",
+	"nvim_get_hl_by_id":         "nvim_get_hl",
+	"nvim_get_hl_by_name":       "nvim_get_hl",
+	"nvim_get_option_info":      "nvim_get_option_info2",
+	"tabpage_get_var":           "nvim_tabpage_get_var",
+	"tabpage_get_window":        "nvim_tabpage_get_win",
+	"tabpage_get_windows":       "nvim_tabpage_list_wins",
+	"tabpage_is_valid":          "nvim_tabpage_is_valid",
+	"tabpage_set_var":           "nvim_tabpage_set_var",
+	"vim_call_function":         "nvim_call_function",
+	"vim_change_directory":      "nvim_set_current_dir",
+	"vim_command":               "nvim_command",
+	"vim_command_output":        "nvim_exec2",
+	"vim_del_current_line":      "nvim_del_current_line",
+	"vim_err_write":             "nvim_err_write",
+	"vim_eval":                  "nvim_eval",
+	"vim_feedkeys":              "nvim_feedkeys",
+	"vim_get_api_info":          "nvim_get_api_info",
+	"vim_get_buffers":           "nvim_list_bufs",
+	"vim_get_color_map":         "nvim_get_color_map",
+	"vim_get_current_buffer":    "nvim_get_current_buf",
+	"vim_get_current_line":      "nvim_get_current_line",
+	"vim_get_current_tabpage":   "nvim_get_current_tabpage",
+	"vim_get_current_window":    "nvim_get_current_win",
+	"vim_get_option":            "nvim_get_option",
+	"vim_get_tabpages":          "nvim_list_tabpages",
+	"vim_get_var":               "nvim_get_var",
+	"vim_get_vvar":              "nvim_get_vvar",
+	"vim_get_windows":           "nvim_list_wins",
+	"vim_input":                 "nvim_input",
+	"vim_list_runtime_paths":    "nvim_list_runtime_paths",
+	"vim_name_to_color":         "nvim_get_color_by_name",
+	"vim_out_write":             "nvim_out_write",
+	"vim_replace_termcodes":     "nvim_replace_termcodes",
+	"vim_report_error":          "nvim_err_writeln",
+	"vim_set_current_buffer":    "nvim_set_current_buf",
+	"vim_set_current_line":      "nvim_set_current_line",
+	"vim_set_current_tabpage":   "nvim_set_current_tabpage",
+	"vim_set_current_window":    "nvim_set_current_win",
+	"vim_set_option":            "nvim_set_option",
+	"vim_set_var":               "nvim_set_var",
+	"vim_strwidth":              "nvim_strwidth",
+	"vim_subscribe":             "nvim_subscribe",
+	"vim_unsubscribe":           "nvim_unsubscribe",
+	"window_get_buffer":         "nvim_win_get_buf",
+	"window_get_cursor":         "nvim_win_get_cursor",
+	"window_get_height":         "nvim_win_get_height",
+	"window_get_option":         "nvim_win_get_option",
+	"window_get_position":       "nvim_win_get_position",
+	"window_get_tabpage":        "nvim_win_get_tabpage",
+	"window_get_var":            "nvim_win_get_var",
+	"window_get_width":          "nvim_win_get_width",
+	"window_is_valid":           "nvim_win_is_valid",
+	"window_set_cursor":         "nvim_win_set_cursor",
+	"window_set_height":         "nvim_win_set_height",
+	"window_set_option":         "nvim_win_set_option",
+	"window_set_var":            "nvim_win_set_var",
+	"window_set_width":          "nvim_win_set_width",
+}
+
+// functionLevels maps API functions added after API level 1 to the API level
+// where the function was added.
+var functionLevels = map[string]int{
+	"nvim_buf_attach":              4,
+	"nvim_buf_call":                7,
+	"nvim_buf_clear_namespace":     5,
+	"nvim_buf_create_user_command": 9,
+	"nvim_buf_del_extmark":         7,
+	"nvim_buf_del_keymap":          6,
+	"nvim_buf_del_mark":            8,
+	"nvim_buf_del_user_command":    9,
+	"nvim_buf_delete":              7,
+	"nvim_buf_detach":              4,
+	"nvim_buf_get_changedtick":     2,
+	"nvim_buf_get_commands":        4,
+	"nvim_buf_get_extmark_by_id":   7,
+	"nvim_buf_get_extmarks":        7,
+	"nvim_buf_get_keymap":          3,
+	"nvim_buf_get_offset":          5,
+	"nvim_buf_get_text":            9,
+	"nvim_buf_is_loaded":           5,
+	"nvim_buf_set_extmark":         7,
+	"nvim_buf_set_keymap":          6,
+	"nvim_buf_set_mark":            8,
+	"nvim_buf_set_text":            7,
+	"nvim_call_dict_function":      4,
+	"nvim_chan_send":               7,
+	"nvim_clear_autocmds":          9,
+	"nvim_cmd":                     10,
+	"nvim_create_augroup":          9,
+	"nvim_create_autocmd":          9,
+	"nvim_create_buf":              6,
+	"nvim_create_namespace":        5,
+	"nvim_create_user_command":     9,
+	"nvim_del_augroup_by_id":       9,
+	"nvim_del_augroup_by_name":     9,
+	"nvim_del_autocmd":             9,
+	"nvim_del_keymap":              6,
+	"nvim_del_mark":                8,
+	"nvim_del_user_command":        9,
+	"nvim_echo":                    7,
+	"nvim_eval_statusline":         8,
+	"nvim_exec2":                   11,
+	"nvim_exec_autocmds":           9,
+	"nvim_exec_lua":                7,
+	"nvim_get_all_options_info":    7,
+	"nvim_get_autocmds":            9,
+	"nvim_get_chan_info":           4,
+	"nvim_get_commands":            4,
+	"nvim_get_context":             6,
+	"nvim_get_hl":                  11,
+	"nvim_get_hl_id_by_name":       7,
+	"nvim_get_keymap":              3,
+	"nvim_get_mark":                8,
+	"nvim_get_mode":                2,
+	"nvim_get_namespaces":          5,
+	"nvim_get_option_info2":        11,
+	"nvim_get_option_value":        9,
+	"nvim_get_proc":                4,
+	"nvim_get_proc_children":       4,
+	"nvim_get_runtime_file":        7,
+	"nvim_input_mouse":             6,
+	"nvim_list_chans":              4,
+	"nvim_list_uis":                4,
+	"nvim_load_context":            6,
+	"nvim_notify":                  7,
+	"nvim_open_term":               7,
+	"nvim_open_win":                6,
+	"nvim_parse_cmd":               10,
+	"nvim_parse_expression":        4,
+	"nvim_paste":                   6,
+	"nvim_put":                     6,
+	"nvim_select_popupmenu_item":   6,
+	"nvim_set_client_info":         4,
+	"nvim_set_decoration_provider": 7,
+	"nvim_set_hl":                  7,
+	"nvim_set_hl_ns":               10,
+	"nvim_set_hl_ns_fast":          10,
+	"nvim_set_keymap":              6,
+	"nvim_set_option_value":        9,
+	"nvim_set_vvar":                6,
+	"nvim_ui_pum_set_bounds":       7,
+	"nvim_ui_pum_set_height":       6,
+	"nvim_ui_set_focus":            11,
+	"nvim_ui_try_resize_grid":      6,
+	"nvim_win_call":                7,
+	"nvim_win_close":               6,
+	"nvim_win_get_config":          6,
+	"nvim_win_hide":                7,
+	"nvim_win_set_buf":             5,
+	"nvim_win_set_config":          6,
+	"nvim_win_set_hl_ns":           10,
+}
+
 func withExtensions() rpc.Option {
 	return rpc.WithExtensions(msgpack.ExtensionMap{
 
@@ -110,16 +293,233 @@ func (x Tabpage) String() string {
 	return fmt.Sprintf("Tabpage:%d", int(x))
 }
 
+// Autocmds calls the nvim_get_autocmds API function.
+//
+//	:help nvim_get_autocmds()
+func (v *Vim) Autocmds(opts map[string]interface{}) ([]interface{}, error) {
+	var result []interface{}
+	err := v.call("nvim_get_autocmds", &result, opts)
+	return result, err
+}
+
+// AutocmdsContext is like Autocmds with a context for cancelling the call.
+func (v *Vim) AutocmdsContext(ctx context.Context, opts map[string]interface{}) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_get_autocmds", &result, opts)
+	return result, err
+}
+
+// Autocmds calls the nvim_get_autocmds API function.
+//
+//	:help nvim_get_autocmds()
+func (p *Pipeline) Autocmds(opts map[string]interface{}, result *[]interface{}) {
+	p.call("nvim_get_autocmds", result, opts)
+}
+
+// CreateRawAutocmd calls the nvim_create_autocmd API function.
+//
+//	:help nvim_create_autocmd()
+func (v *Vim) CreateRawAutocmd(event interface{}, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.call("nvim_create_autocmd", &result, event, opts)
+	return result, err
+}
+
+// CreateRawAutocmdContext is like CreateRawAutocmd with a context for cancelling the call.
+func (v *Vim) CreateRawAutocmdContext(ctx context.Context, event interface{}, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_create_autocmd", &result, event, opts)
+	return result, err
+}
+
+// CreateRawAutocmd calls the nvim_create_autocmd API function.
+//
+//	:help nvim_create_autocmd()
+func (p *Pipeline) CreateRawAutocmd(event interface{}, opts map[string]interface{}, result *int) {
+	p.call("nvim_create_autocmd", result, event, opts)
+}
+
+// DeleteAutocmd calls the nvim_del_autocmd API function.
+//
+//	:help nvim_del_autocmd()
+func (v *Vim) DeleteAutocmd(id int) error {
+	return v.call("nvim_del_autocmd", nil, id)
+}
+
+// DeleteAutocmdContext is like DeleteAutocmd with a context for cancelling the call.
+func (v *Vim) DeleteAutocmdContext(ctx context.Context, id int) error {
+	return v.callContext(ctx, "nvim_del_autocmd", nil, id)
+}
+
+// DeleteAutocmd calls the nvim_del_autocmd API function.
+//
+//	:help nvim_del_autocmd()
+func (p *Pipeline) DeleteAutocmd(id int) {
+	p.call("nvim_del_autocmd", nil, id)
+}
+
+// ClearAutocmds calls the nvim_clear_autocmds API function.
+//
+//	:help nvim_clear_autocmds()
+func (v *Vim) ClearAutocmds(opts map[string]interface{}) error {
+	return v.call("nvim_clear_autocmds", nil, opts)
+}
+
+// ClearAutocmdsContext is like ClearAutocmds with a context for cancelling the call.
+func (v *Vim) ClearAutocmdsContext(ctx context.Context, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_clear_autocmds", nil, opts)
+}
+
+// ClearAutocmds calls the nvim_clear_autocmds API function.
+//
+//	:help nvim_clear_autocmds()
+func (p *Pipeline) ClearAutocmds(opts map[string]interface{}) {
+	p.call("nvim_clear_autocmds", nil, opts)
+}
+
+// CreateAugroup calls the nvim_create_augroup API function.
+//
+//	:help nvim_create_augroup()
+func (v *Vim) CreateAugroup(name string, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.call("nvim_create_augroup", &result, name, opts)
+	return result, err
+}
+
+// CreateAugroupContext is like CreateAugroup with a context for cancelling the call.
+func (v *Vim) CreateAugroupContext(ctx context.Context, name string, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_create_augroup", &result, name, opts)
+	return result, err
+}
+
+// CreateAugroup calls the nvim_create_augroup API function.
+//
+//	:help nvim_create_augroup()
+func (p *Pipeline) CreateAugroup(name string, opts map[string]interface{}, result *int) {
+	p.call("nvim_create_augroup", result, name, opts)
+}
+
+// DeleteAugroupByID calls the nvim_del_augroup_by_id API function.
+//
+//	:help nvim_del_augroup_by_id()
+func (v *Vim) DeleteAugroupByID(id int) error {
+	return v.call("nvim_del_augroup_by_id", nil, id)
+}
+
+// DeleteAugroupByIDContext is like DeleteAugroupByID with a context for cancelling the call.
+func (v *Vim) DeleteAugroupByIDContext(ctx context.Context, id int) error {
+	return v.callContext(ctx, "nvim_del_augroup_by_id", nil, id)
+}
+
+// DeleteAugroupByID calls the nvim_del_augroup_by_id API function.
+//
+//	:help nvim_del_augroup_by_id()
+func (p *Pipeline) DeleteAugroupByID(id int) {
+	p.call("nvim_del_augroup_by_id", nil, id)
+}
+
+// DeleteAugroupByName calls the nvim_del_augroup_by_name API function.
+//
+//	:help nvim_del_augroup_by_name()
+func (v *Vim) DeleteAugroupByName(name string) error {
+	return v.call("nvim_del_augroup_by_name", nil, name)
+}
+
+// DeleteAugroupByNameContext is like DeleteAugroupByName with a context for cancelling the call.
+func (v *Vim) DeleteAugroupByNameContext(ctx context.Context, name string) error {
+	return v.callContext(ctx, "nvim_del_augroup_by_name", nil, name)
+}
+
+// DeleteAugroupByName calls the nvim_del_augroup_by_name API function.
+//
+//	:help nvim_del_augroup_by_name()
+func (p *Pipeline) DeleteAugroupByName(name string) {
+	p.call("nvim_del_augroup_by_name", nil, name)
+}
+
+// ExecAutocmds calls the nvim_exec_autocmds API function.
+//
+//	:help nvim_exec_autocmds()
+func (v *Vim) ExecAutocmds(event interface{}, opts map[string]interface{}) error {
+	return v.call("nvim_exec_autocmds", nil, event, opts)
+}
+
+// ExecAutocmdsContext is like ExecAutocmds with a context for cancelling the call.
+func (v *Vim) ExecAutocmdsContext(ctx context.Context, event interface{}, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_exec_autocmds", nil, event, opts)
+}
+
+// ExecAutocmds calls the nvim_exec_autocmds API function.
+//
+//	:help nvim_exec_autocmds()
+func (p *Pipeline) ExecAutocmds(event interface{}, opts map[string]interface{}) {
+	p.call("nvim_exec_autocmds", nil, event, opts)
+}
+
 // BufferLineCount returns the number of lines in the buffer.
 func (v *Vim) BufferLineCount(buffer Buffer) (int, error) {
 	var result int
-	err := v.call("buffer_line_count", &result, buffer)
+	err := v.call("nvim_buf_line_count", &result, buffer)
+	return result, err
+}
+
+// BufferLineCountContext is like BufferLineCount with a context for cancelling the call.
+func (v *Vim) BufferLineCountContext(ctx context.Context, buffer Buffer) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_line_count", &result, buffer)
 	return result, err
 }
 
 // BufferLineCount returns the number of lines in the buffer.
 func (p *Pipeline) BufferLineCount(buffer Buffer, result *int) {
-	p.call("buffer_line_count", result, buffer)
+	p.call("nvim_buf_line_count", result, buffer)
+}
+
+// AttachBuffer calls the nvim_buf_attach API function.
+//
+//	:help nvim_buf_attach()
+func (v *Vim) AttachBuffer(buffer Buffer, sendBuffer bool, opts map[string]interface{}) (bool, error) {
+	var result bool
+	err := v.call("nvim_buf_attach", &result, buffer, sendBuffer, opts)
+	return result, err
+}
+
+// AttachBufferContext is like AttachBuffer with a context for cancelling the call.
+func (v *Vim) AttachBufferContext(ctx context.Context, buffer Buffer, sendBuffer bool, opts map[string]interface{}) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_attach", &result, buffer, sendBuffer, opts)
+	return result, err
+}
+
+// AttachBuffer calls the nvim_buf_attach API function.
+//
+//	:help nvim_buf_attach()
+func (p *Pipeline) AttachBuffer(buffer Buffer, sendBuffer bool, opts map[string]interface{}, result *bool) {
+	p.call("nvim_buf_attach", result, buffer, sendBuffer, opts)
+}
+
+// DetachBuffer calls the nvim_buf_detach API function.
+//
+//	:help nvim_buf_detach()
+func (v *Vim) DetachBuffer(buffer Buffer) (bool, error) {
+	var result bool
+	err := v.call("nvim_buf_detach", &result, buffer)
+	return result, err
+}
+
+// DetachBufferContext is like DetachBuffer with a context for cancelling the call.
+func (v *Vim) DetachBufferContext(ctx context.Context, buffer Buffer) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_detach", &result, buffer)
+	return result, err
+}
+
+// DetachBuffer calls the nvim_buf_detach API function.
+//
+//	:help nvim_buf_detach()
+func (p *Pipeline) DetachBuffer(buffer Buffer, result *bool) {
+	p.call("nvim_buf_detach", result, buffer)
 }
 
 // BufferLines retrieves a line range from a buffer.
@@ -132,7 +532,14 @@ func (p *Pipeline) BufferLineCount(buffer Buffer, result *int) {
 // = true.
 func (v *Vim) BufferLines(buffer Buffer, start int, end int, strict bool) ([][]byte, error) {
 	var result [][]byte
-	err := v.call("buffer_get_lines", &result, buffer, start, end, strict)
+	err := v.call("nvim_buf_get_lines", &result, buffer, start, end, strict)
+	return result, err
+}
+
+// BufferLinesContext is like BufferLines with a context for cancelling the call.
+func (v *Vim) BufferLinesContext(ctx context.Context, buffer Buffer, start int, end int, strict bool) ([][]byte, error) {
+	var result [][]byte
+	err := v.callContext(ctx, "nvim_buf_get_lines", &result, buffer, start, end, strict)
 	return result, err
 }
 
@@ -145,7 +552,7 @@ func (v *Vim) BufferLines(buffer Buffer, start int, end int, strict bool) ([][]b
 // Out-of-bounds indices are clamped to the nearest valid value, unless strict
 // = true.
 func (p *Pipeline) BufferLines(buffer Buffer, start int, end int, strict bool, result *[][]byte) {
-	p.call("buffer_get_lines", result, buffer, start, end, strict)
+	p.call("nvim_buf_get_lines", result, buffer, start, end, strict)
 }
 
 // SetBufferLines replaces a line range on a buffer.
@@ -160,7 +567,12 @@ func (p *Pipeline) BufferLines(buffer Buffer, start int, end int, strict bool, r
 // Out-of-bounds indices are clamped to the nearest valid value, unless strict
 // = true.
 func (v *Vim) SetBufferLines(buffer Buffer, start int, end int, strict bool, replacement [][]byte) error {
-	return v.call("buffer_set_lines", nil, buffer, start, end, strict, replacement)
+	return v.call("nvim_buf_set_lines", nil, buffer, start, end, strict, replacement)
+}
+
+// SetBufferLinesContext is like SetBufferLines with a context for cancelling the call.
+func (v *Vim) SetBufferLinesContext(ctx context.Context, buffer Buffer, start int, end int, strict bool, replacement [][]byte) error {
+	return v.callContext(ctx, "nvim_buf_set_lines", nil, buffer, start, end, strict, replacement)
 }
 
 // SetBufferLines replaces a line range on a buffer.
@@ -175,128 +587,704 @@ func (v *Vim) SetBufferLines(buffer Buffer, start int, end int, strict bool, rep
 // Out-of-bounds indices are clamped to the nearest valid value, unless strict
 // = true.
 func (p *Pipeline) SetBufferLines(buffer Buffer, start int, end int, strict bool, replacement [][]byte) {
-	p.call("buffer_set_lines", nil, buffer, start, end, strict, replacement)
+	p.call("nvim_buf_set_lines", nil, buffer, start, end, strict, replacement)
+}
+
+// SetBufferText calls the nvim_buf_set_text API function.
+//
+//	:help nvim_buf_set_text()
+func (v *Vim) SetBufferText(buffer Buffer, startRow int, startCol int, endRow int, endCol int, replacement []string) error {
+	return v.call("nvim_buf_set_text", nil, buffer, startRow, startCol, endRow, endCol, replacement)
+}
+
+// SetBufferTextContext is like SetBufferText with a context for cancelling the call.
+func (v *Vim) SetBufferTextContext(ctx context.Context, buffer Buffer, startRow int, startCol int, endRow int, endCol int, replacement []string) error {
+	return v.callContext(ctx, "nvim_buf_set_text", nil, buffer, startRow, startCol, endRow, endCol, replacement)
+}
+
+// SetBufferText calls the nvim_buf_set_text API function.
+//
+//	:help nvim_buf_set_text()
+func (p *Pipeline) SetBufferText(buffer Buffer, startRow int, startCol int, endRow int, endCol int, replacement []string) {
+	p.call("nvim_buf_set_text", nil, buffer, startRow, startCol, endRow, endCol, replacement)
+}
+
+// BufferText calls the nvim_buf_get_text API function.
+//
+//	:help nvim_buf_get_text()
+func (v *Vim) BufferText(buffer Buffer, startRow int, startCol int, endRow int, endCol int, opts map[string]interface{}) ([]string, error) {
+	var result []string
+	err := v.call("nvim_buf_get_text", &result, buffer, startRow, startCol, endRow, endCol, opts)
+	return result, err
+}
+
+// BufferTextContext is like BufferText with a context for cancelling the call.
+func (v *Vim) BufferTextContext(ctx context.Context, buffer Buffer, startRow int, startCol int, endRow int, endCol int, opts map[string]interface{}) ([]string, error) {
+	var result []string
+	err := v.callContext(ctx, "nvim_buf_get_text", &result, buffer, startRow, startCol, endRow, endCol, opts)
+	return result, err
+}
+
+// BufferText calls the nvim_buf_get_text API function.
+//
+//	:help nvim_buf_get_text()
+func (p *Pipeline) BufferText(buffer Buffer, startRow int, startCol int, endRow int, endCol int, opts map[string]interface{}, result *[]string) {
+	p.call("nvim_buf_get_text", result, buffer, startRow, startCol, endRow, endCol, opts)
+}
+
+// BufferOffset calls the nvim_buf_get_offset API function.
+//
+//	:help nvim_buf_get_offset()
+func (v *Vim) BufferOffset(buffer Buffer, index int) (int, error) {
+	var result int
+	err := v.call("nvim_buf_get_offset", &result, buffer, index)
+	return result, err
+}
+
+// BufferOffsetContext is like BufferOffset with a context for cancelling the call.
+func (v *Vim) BufferOffsetContext(ctx context.Context, buffer Buffer, index int) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_get_offset", &result, buffer, index)
+	return result, err
+}
+
+// BufferOffset calls the nvim_buf_get_offset API function.
+//
+//	:help nvim_buf_get_offset()
+func (p *Pipeline) BufferOffset(buffer Buffer, index int, result *int) {
+	p.call("nvim_buf_get_offset", result, buffer, index)
 }
 
 // BufferVar gets a buffer-scoped (b:) variable.
 func (v *Vim) BufferVar(buffer Buffer, name string, result interface{}) error {
-	return v.call("buffer_get_var", result, buffer, name)
+	return v.call("nvim_buf_get_var", result, buffer, name)
+}
+
+// BufferVarContext is like BufferVar with a context for cancelling the call.
+func (v *Vim) BufferVarContext(ctx context.Context, buffer Buffer, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_buf_get_var", result, buffer, name)
 }
 
 // BufferVar gets a buffer-scoped (b:) variable.
 func (p *Pipeline) BufferVar(buffer Buffer, name string, result interface{}) {
-	p.call("buffer_get_var", result, buffer, name)
+	p.call("nvim_buf_get_var", result, buffer, name)
 }
 
-// SetBufferVar sets a buffer-scoped (b:) variable. The value nil deletes the
-// variable. Result is the previous value of the variable.
-func (v *Vim) SetBufferVar(buffer Buffer, name string, value interface{}, result interface{}) error {
-	return v.call("buffer_set_var", result, buffer, name, value)
+// BufferChangedtick calls the nvim_buf_get_changedtick API function.
+//
+//	:help nvim_buf_get_changedtick()
+func (v *Vim) BufferChangedtick(buffer Buffer) (int, error) {
+	var result int
+	err := v.call("nvim_buf_get_changedtick", &result, buffer)
+	return result, err
 }
 
-// SetBufferVar sets a buffer-scoped (b:) variable. The value nil deletes the
-// variable. Result is the previous value of the variable.
-func (p *Pipeline) SetBufferVar(buffer Buffer, name string, value interface{}, result interface{}) {
-	p.call("buffer_set_var", result, buffer, name, value)
+// BufferChangedtickContext is like BufferChangedtick with a context for cancelling the call.
+func (v *Vim) BufferChangedtickContext(ctx context.Context, buffer Buffer) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_get_changedtick", &result, buffer)
+	return result, err
 }
 
-// BufferOption gets a buffer option value.
-func (v *Vim) BufferOption(buffer Buffer, name string, result interface{}) error {
-	return v.call("buffer_get_option", result, buffer, name)
+// BufferChangedtick calls the nvim_buf_get_changedtick API function.
+//
+//	:help nvim_buf_get_changedtick()
+func (p *Pipeline) BufferChangedtick(buffer Buffer, result *int) {
+	p.call("nvim_buf_get_changedtick", result, buffer)
 }
 
-// BufferOption gets a buffer option value.
-func (p *Pipeline) BufferOption(buffer Buffer, name string, result interface{}) {
-	p.call("buffer_get_option", result, buffer, name)
+// BufferKeymap calls the nvim_buf_get_keymap API function.
+//
+//	:help nvim_buf_get_keymap()
+func (v *Vim) BufferKeymap(buffer Buffer, mode string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := v.call("nvim_buf_get_keymap", &result, buffer, mode)
+	return result, err
 }
 
-// SetBufferOption sets a buffer option value. The value nil deletes the option
-// in the case where there's a global fallback.
-func (v *Vim) SetBufferOption(buffer Buffer, name string, value interface{}) error {
-	return v.call("buffer_set_option", nil, buffer, name, value)
+// BufferKeymapContext is like BufferKeymap with a context for cancelling the call.
+func (v *Vim) BufferKeymapContext(ctx context.Context, buffer Buffer, mode string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := v.callContext(ctx, "nvim_buf_get_keymap", &result, buffer, mode)
+	return result, err
 }
 
-// SetBufferOption sets a buffer option value. The value nil deletes the option
-// in the case where there's a global fallback.
-func (p *Pipeline) SetBufferOption(buffer Buffer, name string, value interface{}) {
-	p.call("buffer_set_option", nil, buffer, name, value)
+// BufferKeymap calls the nvim_buf_get_keymap API function.
+//
+//	:help nvim_buf_get_keymap()
+func (p *Pipeline) BufferKeymap(buffer Buffer, mode string, result *[]map[string]interface{}) {
+	p.call("nvim_buf_get_keymap", result, buffer, mode)
 }
 
-// BufferNumber gets a buffer's number.
-func (v *Vim) BufferNumber(buffer Buffer) (int, error) {
-	var result int
-	err := v.call("buffer_get_number", &result, buffer)
-	return result, err
+// SetBufferKeymap calls the nvim_buf_set_keymap API function.
+//
+//	:help nvim_buf_set_keymap()
+func (v *Vim) SetBufferKeymap(buffer Buffer, mode string, lhs string, rhs string, opts map[string]interface{}) error {
+	return v.call("nvim_buf_set_keymap", nil, buffer, mode, lhs, rhs, opts)
 }
 
-// BufferNumber gets a buffer's number.
-func (p *Pipeline) BufferNumber(buffer Buffer, result *int) {
-	p.call("buffer_get_number", result, buffer)
+// SetBufferKeymapContext is like SetBufferKeymap with a context for cancelling the call.
+func (v *Vim) SetBufferKeymapContext(ctx context.Context, buffer Buffer, mode string, lhs string, rhs string, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_buf_set_keymap", nil, buffer, mode, lhs, rhs, opts)
+}
+
+// SetBufferKeymap calls the nvim_buf_set_keymap API function.
+//
+//	:help nvim_buf_set_keymap()
+func (p *Pipeline) SetBufferKeymap(buffer Buffer, mode string, lhs string, rhs string, opts map[string]interface{}) {
+	p.call("nvim_buf_set_keymap", nil, buffer, mode, lhs, rhs, opts)
+}
+
+// DeleteBufferKeymap calls the nvim_buf_del_keymap API function.
+//
+//	:help nvim_buf_del_keymap()
+func (v *Vim) DeleteBufferKeymap(buffer Buffer, mode string, lhs string) error {
+	return v.call("nvim_buf_del_keymap", nil, buffer, mode, lhs)
+}
+
+// DeleteBufferKeymapContext is like DeleteBufferKeymap with a context for cancelling the call.
+func (v *Vim) DeleteBufferKeymapContext(ctx context.Context, buffer Buffer, mode string, lhs string) error {
+	return v.callContext(ctx, "nvim_buf_del_keymap", nil, buffer, mode, lhs)
+}
+
+// DeleteBufferKeymap calls the nvim_buf_del_keymap API function.
+//
+//	:help nvim_buf_del_keymap()
+func (p *Pipeline) DeleteBufferKeymap(buffer Buffer, mode string, lhs string) {
+	p.call("nvim_buf_del_keymap", nil, buffer, mode, lhs)
+}
+
+// SetBufferVar sets a buffer-scoped (b:) variable. Use DeleteBufferVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+func (v *Vim) SetBufferVar(buffer Buffer, name string, value interface{}, result interface{}) error {
+	return v.call("nvim_buf_set_var", result, buffer, name, value)
+}
+
+// SetBufferVarContext is like SetBufferVar with a context for cancelling the call.
+func (v *Vim) SetBufferVarContext(ctx context.Context, buffer Buffer, name string, value interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_buf_set_var", result, buffer, name, value)
+}
+
+// SetBufferVar sets a buffer-scoped (b:) variable. Use DeleteBufferVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+func (p *Pipeline) SetBufferVar(buffer Buffer, name string, value interface{}, result interface{}) {
+	p.call("nvim_buf_set_var", result, buffer, name, value)
+}
+
+// DeleteBufferVar calls the nvim_buf_del_var API function.
+//
+//	:help nvim_buf_del_var()
+func (v *Vim) DeleteBufferVar(buffer Buffer, name string) error {
+	return v.call("nvim_buf_del_var", nil, buffer, name)
+}
+
+// DeleteBufferVarContext is like DeleteBufferVar with a context for cancelling the call.
+func (v *Vim) DeleteBufferVarContext(ctx context.Context, buffer Buffer, name string) error {
+	return v.callContext(ctx, "nvim_buf_del_var", nil, buffer, name)
+}
+
+// DeleteBufferVar calls the nvim_buf_del_var API function.
+//
+//	:help nvim_buf_del_var()
+func (p *Pipeline) DeleteBufferVar(buffer Buffer, name string) {
+	p.call("nvim_buf_del_var", nil, buffer, name)
 }
 
 // BufferName gets the full file name of a buffer.
 func (v *Vim) BufferName(buffer Buffer) (string, error) {
 	var result string
-	err := v.call("buffer_get_name", &result, buffer)
+	err := v.call("nvim_buf_get_name", &result, buffer)
+	return result, err
+}
+
+// BufferNameContext is like BufferName with a context for cancelling the call.
+func (v *Vim) BufferNameContext(ctx context.Context, buffer Buffer) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_buf_get_name", &result, buffer)
 	return result, err
 }
 
 // BufferName gets the full file name of a buffer.
 func (p *Pipeline) BufferName(buffer Buffer, result *string) {
-	p.call("buffer_get_name", result, buffer)
+	p.call("nvim_buf_get_name", result, buffer)
 }
 
 // SetBufferName sets the full file name of a buffer.
 // BufFilePre/BufFilePost are triggered.
 func (v *Vim) SetBufferName(buffer Buffer, name string) error {
-	return v.call("buffer_set_name", nil, buffer, name)
+	return v.call("nvim_buf_set_name", nil, buffer, name)
+}
+
+// SetBufferNameContext is like SetBufferName with a context for cancelling the call.
+func (v *Vim) SetBufferNameContext(ctx context.Context, buffer Buffer, name string) error {
+	return v.callContext(ctx, "nvim_buf_set_name", nil, buffer, name)
 }
 
 // SetBufferName sets the full file name of a buffer.
 // BufFilePre/BufFilePost are triggered.
 func (p *Pipeline) SetBufferName(buffer Buffer, name string) {
-	p.call("buffer_set_name", nil, buffer, name)
+	p.call("nvim_buf_set_name", nil, buffer, name)
+}
+
+// IsBufferLoaded calls the nvim_buf_is_loaded API function.
+//
+//	:help nvim_buf_is_loaded()
+func (v *Vim) IsBufferLoaded(buffer Buffer) (bool, error) {
+	var result bool
+	err := v.call("nvim_buf_is_loaded", &result, buffer)
+	return result, err
+}
+
+// IsBufferLoadedContext is like IsBufferLoaded with a context for cancelling the call.
+func (v *Vim) IsBufferLoadedContext(ctx context.Context, buffer Buffer) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_is_loaded", &result, buffer)
+	return result, err
+}
+
+// IsBufferLoaded calls the nvim_buf_is_loaded API function.
+//
+//	:help nvim_buf_is_loaded()
+func (p *Pipeline) IsBufferLoaded(buffer Buffer, result *bool) {
+	p.call("nvim_buf_is_loaded", result, buffer)
+}
+
+// DeleteBuffer calls the nvim_buf_delete API function.
+//
+//	:help nvim_buf_delete()
+func (v *Vim) DeleteBuffer(buffer Buffer, opts map[string]interface{}) error {
+	return v.call("nvim_buf_delete", nil, buffer, opts)
+}
+
+// DeleteBufferContext is like DeleteBuffer with a context for cancelling the call.
+func (v *Vim) DeleteBufferContext(ctx context.Context, buffer Buffer, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_buf_delete", nil, buffer, opts)
+}
+
+// DeleteBuffer calls the nvim_buf_delete API function.
+//
+//	:help nvim_buf_delete()
+func (p *Pipeline) DeleteBuffer(buffer Buffer, opts map[string]interface{}) {
+	p.call("nvim_buf_delete", nil, buffer, opts)
 }
 
 // IsBufferValid returns true if the buffer is valid.
 func (v *Vim) IsBufferValid(buffer Buffer) (bool, error) {
 	var result bool
-	err := v.call("buffer_is_valid", &result, buffer)
+	err := v.call("nvim_buf_is_valid", &result, buffer)
+	return result, err
+}
+
+// IsBufferValidContext is like IsBufferValid with a context for cancelling the call.
+func (v *Vim) IsBufferValidContext(ctx context.Context, buffer Buffer) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_is_valid", &result, buffer)
 	return result, err
 }
 
 // IsBufferValid returns true if the buffer is valid.
 func (p *Pipeline) IsBufferValid(buffer Buffer, result *bool) {
-	p.call("buffer_is_valid", result, buffer)
+	p.call("nvim_buf_is_valid", result, buffer)
 }
 
-// BufferMark returns the (row,col) of the named mark.
-func (v *Vim) BufferMark(buffer Buffer, name string) ([2]int, error) {
-	var result [2]int
-	err := v.call("buffer_get_mark", &result, buffer, name)
+// DeleteBufferMark calls the nvim_buf_del_mark API function.
+//
+//	:help nvim_buf_del_mark()
+func (v *Vim) DeleteBufferMark(buffer Buffer, name string) (bool, error) {
+	var result bool
+	err := v.call("nvim_buf_del_mark", &result, buffer, name)
 	return result, err
 }
 
-// BufferMark returns the (row,col) of the named mark.
-func (p *Pipeline) BufferMark(buffer Buffer, name string, result *[2]int) {
-	p.call("buffer_get_mark", result, buffer, name)
+// DeleteBufferMarkContext is like DeleteBufferMark with a context for cancelling the call.
+func (v *Vim) DeleteBufferMarkContext(ctx context.Context, buffer Buffer, name string) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_del_mark", &result, buffer, name)
+	return result, err
 }
 
-// AddBufferHighlight adds a highlight to buffer and returns the source id of
-// the highlight.
+// DeleteBufferMark calls the nvim_buf_del_mark API function.
 //
-// AddBufferHighlight can be used for plugins which dynamically generate
-// highlights to a buffer (like a semantic highlighter or linter). The function
-// adds a single highlight to a buffer. Unlike matchaddpos() highlights follow
-// changes to line numbering (as lines are inserted/removed above the
-// highlighted line), like signs and marks do.
+//	:help nvim_buf_del_mark()
+func (p *Pipeline) DeleteBufferMark(buffer Buffer, name string, result *bool) {
+	p.call("nvim_buf_del_mark", result, buffer, name)
+}
+
+// SetBufferMark calls the nvim_buf_set_mark API function.
 //
-// The srcID is useful for batch deletion/updating of a set of highlights. When
-// called with srcID = 0, an unique source id is generated and returned.
-// Succesive calls can pass in it as srcID to add new highlights to the same
-// source group. All highlights in the same group can then be cleared with
-// ClearBufferHighlight. If the highlight never will be manually deleted pass
-// in -1 for srcID.
+//	:help nvim_buf_set_mark()
+func (v *Vim) SetBufferMark(buffer Buffer, name string, line int, col int, opts map[string]interface{}) (bool, error) {
+	var result bool
+	err := v.call("nvim_buf_set_mark", &result, buffer, name, line, col, opts)
+	return result, err
+}
+
+// SetBufferMarkContext is like SetBufferMark with a context for cancelling the call.
+func (v *Vim) SetBufferMarkContext(ctx context.Context, buffer Buffer, name string, line int, col int, opts map[string]interface{}) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_set_mark", &result, buffer, name, line, col, opts)
+	return result, err
+}
+
+// SetBufferMark calls the nvim_buf_set_mark API function.
+//
+//	:help nvim_buf_set_mark()
+func (p *Pipeline) SetBufferMark(buffer Buffer, name string, line int, col int, opts map[string]interface{}, result *bool) {
+	p.call("nvim_buf_set_mark", result, buffer, name, line, col, opts)
+}
+
+// BufferMark returns the (row,col) of the named mark.
+func (v *Vim) BufferMark(buffer Buffer, name string) ([2]int, error) {
+	var result [2]int
+	err := v.call("nvim_buf_get_mark", &result, buffer, name)
+	return result, err
+}
+
+// BufferMarkContext is like BufferMark with a context for cancelling the call.
+func (v *Vim) BufferMarkContext(ctx context.Context, buffer Buffer, name string) ([2]int, error) {
+	var result [2]int
+	err := v.callContext(ctx, "nvim_buf_get_mark", &result, buffer, name)
+	return result, err
+}
+
+// BufferMark returns the (row,col) of the named mark.
+func (p *Pipeline) BufferMark(buffer Buffer, name string, result *[2]int) {
+	p.call("nvim_buf_get_mark", result, buffer, name)
+}
+
+// CreateRawUserCommand calls the nvim_create_user_command API function.
+//
+//	:help nvim_create_user_command()
+func (v *Vim) CreateRawUserCommand(name string, command interface{}, opts map[string]interface{}) error {
+	return v.call("nvim_create_user_command", nil, name, command, opts)
+}
+
+// CreateRawUserCommandContext is like CreateRawUserCommand with a context for cancelling the call.
+func (v *Vim) CreateRawUserCommandContext(ctx context.Context, name string, command interface{}, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_create_user_command", nil, name, command, opts)
+}
+
+// CreateRawUserCommand calls the nvim_create_user_command API function.
+//
+//	:help nvim_create_user_command()
+func (p *Pipeline) CreateRawUserCommand(name string, command interface{}, opts map[string]interface{}) {
+	p.call("nvim_create_user_command", nil, name, command, opts)
+}
+
+// DeleteUserCommand calls the nvim_del_user_command API function.
+//
+//	:help nvim_del_user_command()
+func (v *Vim) DeleteUserCommand(name string) error {
+	return v.call("nvim_del_user_command", nil, name)
+}
+
+// DeleteUserCommandContext is like DeleteUserCommand with a context for cancelling the call.
+func (v *Vim) DeleteUserCommandContext(ctx context.Context, name string) error {
+	return v.callContext(ctx, "nvim_del_user_command", nil, name)
+}
+
+// DeleteUserCommand calls the nvim_del_user_command API function.
+//
+//	:help nvim_del_user_command()
+func (p *Pipeline) DeleteUserCommand(name string) {
+	p.call("nvim_del_user_command", nil, name)
+}
+
+// CreateBufferUserCommand calls the nvim_buf_create_user_command API function.
+//
+//	:help nvim_buf_create_user_command()
+func (v *Vim) CreateBufferUserCommand(buffer Buffer, name string, command interface{}, opts map[string]interface{}) error {
+	return v.call("nvim_buf_create_user_command", nil, buffer, name, command, opts)
+}
+
+// CreateBufferUserCommandContext is like CreateBufferUserCommand with a context for cancelling the call.
+func (v *Vim) CreateBufferUserCommandContext(ctx context.Context, buffer Buffer, name string, command interface{}, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_buf_create_user_command", nil, buffer, name, command, opts)
+}
+
+// CreateBufferUserCommand calls the nvim_buf_create_user_command API function.
+//
+//	:help nvim_buf_create_user_command()
+func (p *Pipeline) CreateBufferUserCommand(buffer Buffer, name string, command interface{}, opts map[string]interface{}) {
+	p.call("nvim_buf_create_user_command", nil, buffer, name, command, opts)
+}
+
+// DeleteBufferUserCommand calls the nvim_buf_del_user_command API function.
+//
+//	:help nvim_buf_del_user_command()
+func (v *Vim) DeleteBufferUserCommand(buffer Buffer, name string) error {
+	return v.call("nvim_buf_del_user_command", nil, buffer, name)
+}
+
+// DeleteBufferUserCommandContext is like DeleteBufferUserCommand with a context for cancelling the call.
+func (v *Vim) DeleteBufferUserCommandContext(ctx context.Context, buffer Buffer, name string) error {
+	return v.callContext(ctx, "nvim_buf_del_user_command", nil, buffer, name)
+}
+
+// DeleteBufferUserCommand calls the nvim_buf_del_user_command API function.
+//
+//	:help nvim_buf_del_user_command()
+func (p *Pipeline) DeleteBufferUserCommand(buffer Buffer, name string) {
+	p.call("nvim_buf_del_user_command", nil, buffer, name)
+}
+
+// Commands calls the nvim_get_commands API function.
+//
+//	:help nvim_get_commands()
+func (v *Vim) Commands(opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_commands", &result, opts)
+	return result, err
+}
+
+// CommandsContext is like Commands with a context for cancelling the call.
+func (v *Vim) CommandsContext(ctx context.Context, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_commands", &result, opts)
+	return result, err
+}
+
+// Commands calls the nvim_get_commands API function.
+//
+//	:help nvim_get_commands()
+func (p *Pipeline) Commands(opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_get_commands", result, opts)
+}
+
+// BufferCommands calls the nvim_buf_get_commands API function.
+//
+//	:help nvim_buf_get_commands()
+func (v *Vim) BufferCommands(buffer Buffer, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_buf_get_commands", &result, buffer, opts)
+	return result, err
+}
+
+// BufferCommandsContext is like BufferCommands with a context for cancelling the call.
+func (v *Vim) BufferCommandsContext(ctx context.Context, buffer Buffer, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_buf_get_commands", &result, buffer, opts)
+	return result, err
+}
+
+// BufferCommands calls the nvim_buf_get_commands API function.
+//
+//	:help nvim_buf_get_commands()
+func (p *Pipeline) BufferCommands(buffer Buffer, opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_buf_get_commands", result, buffer, opts)
+}
+
+// ParseCmd calls the nvim_parse_cmd API function.
+//
+//	:help nvim_parse_cmd()
+func (v *Vim) ParseCmd(str string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_parse_cmd", &result, str, opts)
+	return result, err
+}
+
+// ParseCmdContext is like ParseCmd with a context for cancelling the call.
+func (v *Vim) ParseCmdContext(ctx context.Context, str string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_parse_cmd", &result, str, opts)
+	return result, err
+}
+
+// ParseCmd calls the nvim_parse_cmd API function.
+//
+//	:help nvim_parse_cmd()
+func (p *Pipeline) ParseCmd(str string, opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_parse_cmd", result, str, opts)
+}
+
+// Cmd calls the nvim_cmd API function.
+//
+//	:help nvim_cmd()
+func (v *Vim) Cmd(cmd map[string]interface{}, opts map[string]interface{}) (string, error) {
+	var result string
+	err := v.call("nvim_cmd", &result, cmd, opts)
+	return result, err
+}
+
+// CmdContext is like Cmd with a context for cancelling the call.
+func (v *Vim) CmdContext(ctx context.Context, cmd map[string]interface{}, opts map[string]interface{}) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_cmd", &result, cmd, opts)
+	return result, err
+}
+
+// Cmd calls the nvim_cmd API function.
+//
+//	:help nvim_cmd()
+func (p *Pipeline) Cmd(cmd map[string]interface{}, opts map[string]interface{}, result *string) {
+	p.call("nvim_cmd", result, cmd, opts)
+}
+
+// CommandOutput executes a single ex command and returns the output.
+//
+// Deprecated: Use Exec instead.
+func (v *Vim) CommandOutput(str string) (string, error) {
+	var result string
+	err := v.call("nvim_command_output", &result, str)
+	return result, err
+}
+
+// CommandOutputContext is like CommandOutput with a context for cancelling the call.
+func (v *Vim) CommandOutputContext(ctx context.Context, str string) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_command_output", &result, str)
+	return result, err
+}
+
+// CommandOutput executes a single ex command and returns the output.
+//
+// Deprecated: Use Exec instead.
+func (p *Pipeline) CommandOutput(str string, result *string) {
+	p.call("nvim_command_output", result, str)
+}
+
+// BufferNumber gets a buffer's number.
+//
+// Deprecated: The buffer number is the value of the Buffer.
+func (v *Vim) BufferNumber(buffer Buffer) (int, error) {
+	var result int
+	err := v.call("nvim_buf_get_number", &result, buffer)
+	return result, err
+}
+
+// BufferNumberContext is like BufferNumber with a context for cancelling the call.
+func (v *Vim) BufferNumberContext(ctx context.Context, buffer Buffer) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_get_number", &result, buffer)
+	return result, err
+}
+
+// BufferNumber gets a buffer's number.
+//
+// Deprecated: The buffer number is the value of the Buffer.
+func (p *Pipeline) BufferNumber(buffer Buffer, result *int) {
+	p.call("nvim_buf_get_number", result, buffer)
+}
+
+// ClearBufferHighlight clears highlights from a given source group and a range
+// of lines.
+//
+// To clear a source group in the entire buffer, pass in 1 and -1 to startLine
+// and endLine respectively.
+//
+// The lineStart and lineEnd parameters specify the range of lines to clear.
+// The end of range is exclusive. Specify -1 to clear to the end of the file.
+//
+// Deprecated: Use ClearBufferNamespace instead.
+func (v *Vim) ClearBufferHighlight(buffer Buffer, srcID int, startLine int, endLine int) error {
+	return v.call("nvim_buf_clear_highlight", nil, buffer, srcID, startLine, endLine)
+}
+
+// ClearBufferHighlightContext is like ClearBufferHighlight with a context for cancelling the call.
+func (v *Vim) ClearBufferHighlightContext(ctx context.Context, buffer Buffer, srcID int, startLine int, endLine int) error {
+	return v.callContext(ctx, "nvim_buf_clear_highlight", nil, buffer, srcID, startLine, endLine)
+}
+
+// ClearBufferHighlight clears highlights from a given source group and a range
+// of lines.
+//
+// To clear a source group in the entire buffer, pass in 1 and -1 to startLine
+// and endLine respectively.
+//
+// The lineStart and lineEnd parameters specify the range of lines to clear.
+// The end of range is exclusive. Specify -1 to clear to the end of the file.
+//
+// Deprecated: Use ClearBufferNamespace instead.
+func (p *Pipeline) ClearBufferHighlight(buffer Buffer, srcID int, startLine int, endLine int) {
+	p.call("nvim_buf_clear_highlight", nil, buffer, srcID, startLine, endLine)
+}
+
+// CreateNamespace calls the nvim_create_namespace API function.
+//
+//	:help nvim_create_namespace()
+func (v *Vim) CreateNamespace(name string) (int, error) {
+	var result int
+	err := v.call("nvim_create_namespace", &result, name)
+	return result, err
+}
+
+// CreateNamespaceContext is like CreateNamespace with a context for cancelling the call.
+func (v *Vim) CreateNamespaceContext(ctx context.Context, name string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_create_namespace", &result, name)
+	return result, err
+}
+
+// CreateNamespace calls the nvim_create_namespace API function.
+//
+//	:help nvim_create_namespace()
+func (p *Pipeline) CreateNamespace(name string, result *int) {
+	p.call("nvim_create_namespace", result, name)
+}
+
+// Namespaces calls the nvim_get_namespaces API function.
+//
+//	:help nvim_get_namespaces()
+func (v *Vim) Namespaces() (map[string]int, error) {
+	var result map[string]int
+	err := v.call("nvim_get_namespaces", &result)
+	return result, err
+}
+
+// NamespacesContext is like Namespaces with a context for cancelling the call.
+func (v *Vim) NamespacesContext(ctx context.Context) (map[string]int, error) {
+	var result map[string]int
+	err := v.callContext(ctx, "nvim_get_namespaces", &result)
+	return result, err
+}
+
+// Namespaces calls the nvim_get_namespaces API function.
+//
+//	:help nvim_get_namespaces()
+func (p *Pipeline) Namespaces(result *map[string]int) {
+	p.call("nvim_get_namespaces", result)
+}
+
+// DeleteBufferExtmark calls the nvim_buf_del_extmark API function.
+//
+//	:help nvim_buf_del_extmark()
+func (v *Vim) DeleteBufferExtmark(buffer Buffer, nsID int, id int) (bool, error) {
+	var result bool
+	err := v.call("nvim_buf_del_extmark", &result, buffer, nsID, id)
+	return result, err
+}
+
+// DeleteBufferExtmarkContext is like DeleteBufferExtmark with a context for cancelling the call.
+func (v *Vim) DeleteBufferExtmarkContext(ctx context.Context, buffer Buffer, nsID int, id int) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_buf_del_extmark", &result, buffer, nsID, id)
+	return result, err
+}
+
+// DeleteBufferExtmark calls the nvim_buf_del_extmark API function.
+//
+//	:help nvim_buf_del_extmark()
+func (p *Pipeline) DeleteBufferExtmark(buffer Buffer, nsID int, id int, result *bool) {
+	p.call("nvim_buf_del_extmark", result, buffer, nsID, id)
+}
+
+// AddBufferHighlight adds a highlight to buffer and returns the source id of
+// the highlight.
+//
+// AddBufferHighlight can be used for plugins which dynamically generate
+// highlights to a buffer (like a semantic highlighter or linter). The function
+// adds a single highlight to a buffer. Unlike matchaddpos() highlights follow
+// changes to line numbering (as lines are inserted/removed above the
+// highlighted line), like signs and marks do.
+//
+// The srcID is useful for batch deletion/updating of a set of highlights. When
+// called with srcID = 0, an unique source id is generated and returned.
+// Succesive calls can pass in it as srcID to add new highlights to the same
+// source group. All highlights in the same group can then be cleared with
+// ClearBufferHighlight. If the highlight never will be manually deleted pass
+// in -1 for srcID.
 //
 // If hlGroup is the empty string no highlight is added, but a new srcID is
 // still returned. This is useful for an external plugin to synchrounously
@@ -307,681 +1295,2893 @@ func (p *Pipeline) BufferMark(buffer Buffer, name string, result *[2]int) {
 // highlight. Use endCol = -1 to highlight to the end of the line.
 func (v *Vim) AddBufferHighlight(buffer Buffer, srcID int, hlGroup string, line int, startCol int, endCol int) (int, error) {
 	var result int
-	err := v.call("buffer_add_highlight", &result, buffer, srcID, hlGroup, line, startCol, endCol)
+	err := v.call("nvim_buf_add_highlight", &result, buffer, srcID, hlGroup, line, startCol, endCol)
+	return result, err
+}
+
+// AddBufferHighlightContext is like AddBufferHighlight with a context for cancelling the call.
+func (v *Vim) AddBufferHighlightContext(ctx context.Context, buffer Buffer, srcID int, hlGroup string, line int, startCol int, endCol int) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_buf_add_highlight", &result, buffer, srcID, hlGroup, line, startCol, endCol)
+	return result, err
+}
+
+// AddBufferHighlight adds a highlight to buffer and returns the source id of
+// the highlight.
+//
+// AddBufferHighlight can be used for plugins which dynamically generate
+// highlights to a buffer (like a semantic highlighter or linter). The function
+// adds a single highlight to a buffer. Unlike matchaddpos() highlights follow
+// changes to line numbering (as lines are inserted/removed above the
+// highlighted line), like signs and marks do.
+//
+// The srcID is useful for batch deletion/updating of a set of highlights. When
+// called with srcID = 0, an unique source id is generated and returned.
+// Succesive calls can pass in it as srcID to add new highlights to the same
+// source group. All highlights in the same group can then be cleared with
+// ClearBufferHighlight. If the highlight never will be manually deleted pass
+// in -1 for srcID.
+//
+// If hlGroup is the empty string no highlight is added, but a new srcID is
+// still returned. This is useful for an external plugin to synchrounously
+// request an unique srcID at initialization, and later asynchronously add and
+// clear highlights in response to buffer changes.
+//
+// The startCol and endCol parameters specify the range of columns to
+// highlight. Use endCol = -1 to highlight to the end of the line.
+func (p *Pipeline) AddBufferHighlight(buffer Buffer, srcID int, hlGroup string, line int, startCol int, endCol int, result *int) {
+	p.call("nvim_buf_add_highlight", result, buffer, srcID, hlGroup, line, startCol, endCol)
+}
+
+// ClearBufferNamespace calls the nvim_buf_clear_namespace API function.
+//
+//	:help nvim_buf_clear_namespace()
+func (v *Vim) ClearBufferNamespace(buffer Buffer, nsID int, lineStart int, lineEnd int) error {
+	return v.call("nvim_buf_clear_namespace", nil, buffer, nsID, lineStart, lineEnd)
+}
+
+// ClearBufferNamespaceContext is like ClearBufferNamespace with a context for cancelling the call.
+func (v *Vim) ClearBufferNamespaceContext(ctx context.Context, buffer Buffer, nsID int, lineStart int, lineEnd int) error {
+	return v.callContext(ctx, "nvim_buf_clear_namespace", nil, buffer, nsID, lineStart, lineEnd)
+}
+
+// ClearBufferNamespace calls the nvim_buf_clear_namespace API function.
+//
+//	:help nvim_buf_clear_namespace()
+func (p *Pipeline) ClearBufferNamespace(buffer Buffer, nsID int, lineStart int, lineEnd int) {
+	p.call("nvim_buf_clear_namespace", nil, buffer, nsID, lineStart, lineEnd)
+}
+
+// SetDecorationProvider calls the nvim_set_decoration_provider API function.
+//
+//	:help nvim_set_decoration_provider()
+func (v *Vim) SetDecorationProvider(nsID int, opts map[string]interface{}) error {
+	return v.call("nvim_set_decoration_provider", nil, nsID, opts)
+}
+
+// SetDecorationProviderContext is like SetDecorationProvider with a context for cancelling the call.
+func (v *Vim) SetDecorationProviderContext(ctx context.Context, nsID int, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_set_decoration_provider", nil, nsID, opts)
+}
+
+// SetDecorationProvider calls the nvim_set_decoration_provider API function.
+//
+//	:help nvim_set_decoration_provider()
+func (p *Pipeline) SetDecorationProvider(nsID int, opts map[string]interface{}) {
+	p.call("nvim_set_decoration_provider", nil, nsID, opts)
+}
+
+// OptionValue calls the nvim_get_option_value API function.
+//
+//	:help nvim_get_option_value()
+func (v *Vim) OptionValue(name string, opts map[string]interface{}, result interface{}) error {
+	return v.call("nvim_get_option_value", result, name, opts)
+}
+
+// OptionValueContext is like OptionValue with a context for cancelling the call.
+func (v *Vim) OptionValueContext(ctx context.Context, name string, opts map[string]interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_option_value", result, name, opts)
+}
+
+// OptionValue calls the nvim_get_option_value API function.
+//
+//	:help nvim_get_option_value()
+func (p *Pipeline) OptionValue(name string, opts map[string]interface{}, result interface{}) {
+	p.call("nvim_get_option_value", result, name, opts)
+}
+
+// SetOptionValue calls the nvim_set_option_value API function.
+//
+//	:help nvim_set_option_value()
+func (v *Vim) SetOptionValue(name string, value interface{}, opts map[string]interface{}) error {
+	return v.call("nvim_set_option_value", nil, name, value, opts)
+}
+
+// SetOptionValueContext is like SetOptionValue with a context for cancelling the call.
+func (v *Vim) SetOptionValueContext(ctx context.Context, name string, value interface{}, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_set_option_value", nil, name, value, opts)
+}
+
+// SetOptionValue calls the nvim_set_option_value API function.
+//
+//	:help nvim_set_option_value()
+func (p *Pipeline) SetOptionValue(name string, value interface{}, opts map[string]interface{}) {
+	p.call("nvim_set_option_value", nil, name, value, opts)
+}
+
+// AllOptionsInfo calls the nvim_get_all_options_info API function.
+//
+//	:help nvim_get_all_options_info()
+func (v *Vim) AllOptionsInfo() (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_all_options_info", &result)
+	return result, err
+}
+
+// AllOptionsInfoContext is like AllOptionsInfo with a context for cancelling the call.
+func (v *Vim) AllOptionsInfoContext(ctx context.Context) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_all_options_info", &result)
+	return result, err
+}
+
+// AllOptionsInfo calls the nvim_get_all_options_info API function.
+//
+//	:help nvim_get_all_options_info()
+func (p *Pipeline) AllOptionsInfo(result *map[string]interface{}) {
+	p.call("nvim_get_all_options_info", result)
+}
+
+// OptionInfo calls the nvim_get_option_info2 API function.
+//
+//	:help nvim_get_option_info2()
+func (v *Vim) OptionInfo(name string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_option_info2", &result, name, opts)
+	return result, err
+}
+
+// OptionInfoContext is like OptionInfo with a context for cancelling the call.
+func (v *Vim) OptionInfoContext(ctx context.Context, name string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_option_info2", &result, name, opts)
+	return result, err
+}
+
+// OptionInfo calls the nvim_get_option_info2 API function.
+//
+//	:help nvim_get_option_info2()
+func (p *Pipeline) OptionInfo(name string, opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_get_option_info2", result, name, opts)
+}
+
+// SetOption sets an option.
+func (v *Vim) SetOption(name string, value interface{}) error {
+	return v.call("nvim_set_option", nil, name, value)
+}
+
+// SetOptionContext is like SetOption with a context for cancelling the call.
+func (v *Vim) SetOptionContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_set_option", nil, name, value)
+}
+
+// SetOption sets an option.
+func (p *Pipeline) SetOption(name string, value interface{}) {
+	p.call("nvim_set_option", nil, name, value)
+}
+
+// Option gets an option.
+func (v *Vim) Option(name string, result interface{}) error {
+	return v.call("nvim_get_option", result, name)
+}
+
+// OptionContext is like Option with a context for cancelling the call.
+func (v *Vim) OptionContext(ctx context.Context, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_option", result, name)
+}
+
+// Option gets an option.
+func (p *Pipeline) Option(name string, result interface{}) {
+	p.call("nvim_get_option", result, name)
+}
+
+// BufferOption gets a buffer option value.
+func (v *Vim) BufferOption(buffer Buffer, name string, result interface{}) error {
+	return v.call("nvim_buf_get_option", result, buffer, name)
+}
+
+// BufferOptionContext is like BufferOption with a context for cancelling the call.
+func (v *Vim) BufferOptionContext(ctx context.Context, buffer Buffer, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_buf_get_option", result, buffer, name)
+}
+
+// BufferOption gets a buffer option value.
+func (p *Pipeline) BufferOption(buffer Buffer, name string, result interface{}) {
+	p.call("nvim_buf_get_option", result, buffer, name)
+}
+
+// SetBufferOption sets a buffer option value. The value nil deletes the option
+// in the case where there's a global fallback.
+func (v *Vim) SetBufferOption(buffer Buffer, name string, value interface{}) error {
+	return v.call("nvim_buf_set_option", nil, buffer, name, value)
+}
+
+// SetBufferOptionContext is like SetBufferOption with a context for cancelling the call.
+func (v *Vim) SetBufferOptionContext(ctx context.Context, buffer Buffer, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_buf_set_option", nil, buffer, name, value)
+}
+
+// SetBufferOption sets a buffer option value. The value nil deletes the option
+// in the case where there's a global fallback.
+func (p *Pipeline) SetBufferOption(buffer Buffer, name string, value interface{}) {
+	p.call("nvim_buf_set_option", nil, buffer, name, value)
+}
+
+// WindowOption gets a window option.
+func (v *Vim) WindowOption(window Window, name string, result interface{}) error {
+	return v.call("nvim_win_get_option", result, window, name)
+}
+
+// WindowOptionContext is like WindowOption with a context for cancelling the call.
+func (v *Vim) WindowOptionContext(ctx context.Context, window Window, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_win_get_option", result, window, name)
+}
+
+// WindowOption gets a window option.
+func (p *Pipeline) WindowOption(window Window, name string, result interface{}) {
+	p.call("nvim_win_get_option", result, window, name)
+}
+
+// SetWindowOption sets a window option.
+func (v *Vim) SetWindowOption(window Window, name string, value interface{}) error {
+	return v.call("nvim_win_set_option", nil, window, name, value)
+}
+
+// SetWindowOptionContext is like SetWindowOption with a context for cancelling the call.
+func (v *Vim) SetWindowOptionContext(ctx context.Context, window Window, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_win_set_option", nil, window, name, value)
+}
+
+// SetWindowOption sets a window option.
+func (p *Pipeline) SetWindowOption(window Window, name string, value interface{}) {
+	p.call("nvim_win_set_option", nil, window, name, value)
+}
+
+// TabpageWindows returns the windows in a tabpage.
+func (v *Vim) TabpageWindows(tabpage Tabpage) ([]Window, error) {
+	var result []Window
+	err := v.call("nvim_tabpage_list_wins", &result, tabpage)
+	return result, err
+}
+
+// TabpageWindowsContext is like TabpageWindows with a context for cancelling the call.
+func (v *Vim) TabpageWindowsContext(ctx context.Context, tabpage Tabpage) ([]Window, error) {
+	var result []Window
+	err := v.callContext(ctx, "nvim_tabpage_list_wins", &result, tabpage)
+	return result, err
+}
+
+// TabpageWindows returns the windows in a tabpage.
+func (p *Pipeline) TabpageWindows(tabpage Tabpage, result *[]Window) {
+	p.call("nvim_tabpage_list_wins", result, tabpage)
+}
+
+// TabpageVar gets a tab-scoped (t:) variable.
+func (v *Vim) TabpageVar(tabpage Tabpage, name string, result interface{}) error {
+	return v.call("nvim_tabpage_get_var", result, tabpage, name)
+}
+
+// TabpageVarContext is like TabpageVar with a context for cancelling the call.
+func (v *Vim) TabpageVarContext(ctx context.Context, tabpage Tabpage, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_tabpage_get_var", result, tabpage, name)
+}
+
+// TabpageVar gets a tab-scoped (t:) variable.
+func (p *Pipeline) TabpageVar(tabpage Tabpage, name string, result interface{}) {
+	p.call("nvim_tabpage_get_var", result, tabpage, name)
+}
+
+// SetTabpageVar sets a tab-scoped (t:) variable. Use DeleteTabpageVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+func (v *Vim) SetTabpageVar(tabpage Tabpage, name string, value interface{}, result interface{}) error {
+	return v.call("nvim_tabpage_set_var", result, tabpage, name, value)
+}
+
+// SetTabpageVarContext is like SetTabpageVar with a context for cancelling the call.
+func (v *Vim) SetTabpageVarContext(ctx context.Context, tabpage Tabpage, name string, value interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_tabpage_set_var", result, tabpage, name, value)
+}
+
+// SetTabpageVar sets a tab-scoped (t:) variable. Use DeleteTabpageVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+func (p *Pipeline) SetTabpageVar(tabpage Tabpage, name string, value interface{}, result interface{}) {
+	p.call("nvim_tabpage_set_var", result, tabpage, name, value)
+}
+
+// DeleteTabpageVar calls the nvim_tabpage_del_var API function.
+//
+//	:help nvim_tabpage_del_var()
+func (v *Vim) DeleteTabpageVar(tabpage Tabpage, name string) error {
+	return v.call("nvim_tabpage_del_var", nil, tabpage, name)
+}
+
+// DeleteTabpageVarContext is like DeleteTabpageVar with a context for cancelling the call.
+func (v *Vim) DeleteTabpageVarContext(ctx context.Context, tabpage Tabpage, name string) error {
+	return v.callContext(ctx, "nvim_tabpage_del_var", nil, tabpage, name)
+}
+
+// DeleteTabpageVar calls the nvim_tabpage_del_var API function.
+//
+//	:help nvim_tabpage_del_var()
+func (p *Pipeline) DeleteTabpageVar(tabpage Tabpage, name string) {
+	p.call("nvim_tabpage_del_var", nil, tabpage, name)
+}
+
+// TabpageWindow gets the current window in a tab page.
+func (v *Vim) TabpageWindow(tabpage Tabpage) (Window, error) {
+	var result Window
+	err := v.call("nvim_tabpage_get_win", &result, tabpage)
+	return result, err
+}
+
+// TabpageWindowContext is like TabpageWindow with a context for cancelling the call.
+func (v *Vim) TabpageWindowContext(ctx context.Context, tabpage Tabpage) (Window, error) {
+	var result Window
+	err := v.callContext(ctx, "nvim_tabpage_get_win", &result, tabpage)
+	return result, err
+}
+
+// TabpageWindow gets the current window in a tab page.
+func (p *Pipeline) TabpageWindow(tabpage Tabpage, result *Window) {
+	p.call("nvim_tabpage_get_win", result, tabpage)
+}
+
+// TabpageNumber calls the nvim_tabpage_get_number API function.
+//
+//	:help nvim_tabpage_get_number()
+func (v *Vim) TabpageNumber(tabpage Tabpage) (int, error) {
+	var result int
+	err := v.call("nvim_tabpage_get_number", &result, tabpage)
+	return result, err
+}
+
+// TabpageNumberContext is like TabpageNumber with a context for cancelling the call.
+func (v *Vim) TabpageNumberContext(ctx context.Context, tabpage Tabpage) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_tabpage_get_number", &result, tabpage)
+	return result, err
+}
+
+// TabpageNumber calls the nvim_tabpage_get_number API function.
+//
+//	:help nvim_tabpage_get_number()
+func (p *Pipeline) TabpageNumber(tabpage Tabpage, result *int) {
+	p.call("nvim_tabpage_get_number", result, tabpage)
+}
+
+// IsTabpageValid checks if a tab page is valid.
+func (v *Vim) IsTabpageValid(tabpage Tabpage) (bool, error) {
+	var result bool
+	err := v.call("nvim_tabpage_is_valid", &result, tabpage)
+	return result, err
+}
+
+// IsTabpageValidContext is like IsTabpageValid with a context for cancelling the call.
+func (v *Vim) IsTabpageValidContext(ctx context.Context, tabpage Tabpage) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_tabpage_is_valid", &result, tabpage)
+	return result, err
+}
+
+// IsTabpageValid checks if a tab page is valid.
+func (p *Pipeline) IsTabpageValid(tabpage Tabpage, result *bool) {
+	p.call("nvim_tabpage_is_valid", result, tabpage)
+}
+
+// SetUIFocus calls the nvim_ui_set_focus API function.
+//
+//	:help nvim_ui_set_focus()
+func (v *Vim) SetUIFocus(gained bool) error {
+	return v.call("nvim_ui_set_focus", nil, gained)
+}
+
+// SetUIFocusContext is like SetUIFocus with a context for cancelling the call.
+func (v *Vim) SetUIFocusContext(ctx context.Context, gained bool) error {
+	return v.callContext(ctx, "nvim_ui_set_focus", nil, gained)
+}
+
+// SetUIFocus calls the nvim_ui_set_focus API function.
+//
+//	:help nvim_ui_set_focus()
+func (p *Pipeline) SetUIFocus(gained bool) {
+	p.call("nvim_ui_set_focus", nil, gained)
+}
+
+// TryResizeUI calls the nvim_ui_try_resize API function.
+//
+//	:help nvim_ui_try_resize()
+func (v *Vim) TryResizeUI(width int, height int) error {
+	return v.call("nvim_ui_try_resize", nil, width, height)
+}
+
+// TryResizeUIContext is like TryResizeUI with a context for cancelling the call.
+func (v *Vim) TryResizeUIContext(ctx context.Context, width int, height int) error {
+	return v.callContext(ctx, "nvim_ui_try_resize", nil, width, height)
+}
+
+// TryResizeUI calls the nvim_ui_try_resize API function.
+//
+//	:help nvim_ui_try_resize()
+func (p *Pipeline) TryResizeUI(width int, height int) {
+	p.call("nvim_ui_try_resize", nil, width, height)
+}
+
+// SetUIOption calls the nvim_ui_set_option API function.
+//
+//	:help nvim_ui_set_option()
+func (v *Vim) SetUIOption(name string, value interface{}) error {
+	return v.call("nvim_ui_set_option", nil, name, value)
+}
+
+// SetUIOptionContext is like SetUIOption with a context for cancelling the call.
+func (v *Vim) SetUIOptionContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_ui_set_option", nil, name, value)
+}
+
+// SetUIOption calls the nvim_ui_set_option API function.
+//
+//	:help nvim_ui_set_option()
+func (p *Pipeline) SetUIOption(name string, value interface{}) {
+	p.call("nvim_ui_set_option", nil, name, value)
+}
+
+// TryResizeUIGrid calls the nvim_ui_try_resize_grid API function.
+//
+//	:help nvim_ui_try_resize_grid()
+func (v *Vim) TryResizeUIGrid(grid int, width int, height int) error {
+	return v.call("nvim_ui_try_resize_grid", nil, grid, width, height)
+}
+
+// TryResizeUIGridContext is like TryResizeUIGrid with a context for cancelling the call.
+func (v *Vim) TryResizeUIGridContext(ctx context.Context, grid int, width int, height int) error {
+	return v.callContext(ctx, "nvim_ui_try_resize_grid", nil, grid, width, height)
+}
+
+// TryResizeUIGrid calls the nvim_ui_try_resize_grid API function.
+//
+//	:help nvim_ui_try_resize_grid()
+func (p *Pipeline) TryResizeUIGrid(grid int, width int, height int) {
+	p.call("nvim_ui_try_resize_grid", nil, grid, width, height)
+}
+
+// SetPopupmenuHeight calls the nvim_ui_pum_set_height API function.
+//
+//	:help nvim_ui_pum_set_height()
+func (v *Vim) SetPopupmenuHeight(height int) error {
+	return v.call("nvim_ui_pum_set_height", nil, height)
+}
+
+// SetPopupmenuHeightContext is like SetPopupmenuHeight with a context for cancelling the call.
+func (v *Vim) SetPopupmenuHeightContext(ctx context.Context, height int) error {
+	return v.callContext(ctx, "nvim_ui_pum_set_height", nil, height)
+}
+
+// SetPopupmenuHeight calls the nvim_ui_pum_set_height API function.
+//
+//	:help nvim_ui_pum_set_height()
+func (p *Pipeline) SetPopupmenuHeight(height int) {
+	p.call("nvim_ui_pum_set_height", nil, height)
+}
+
+// SetPopupmenuBounds calls the nvim_ui_pum_set_bounds API function.
+//
+//	:help nvim_ui_pum_set_bounds()
+func (v *Vim) SetPopupmenuBounds(width float64, height float64, row float64, col float64) error {
+	return v.call("nvim_ui_pum_set_bounds", nil, width, height, row, col)
+}
+
+// SetPopupmenuBoundsContext is like SetPopupmenuBounds with a context for cancelling the call.
+func (v *Vim) SetPopupmenuBoundsContext(ctx context.Context, width float64, height float64, row float64, col float64) error {
+	return v.callContext(ctx, "nvim_ui_pum_set_bounds", nil, width, height, row, col)
+}
+
+// SetPopupmenuBounds calls the nvim_ui_pum_set_bounds API function.
+//
+//	:help nvim_ui_pum_set_bounds()
+func (p *Pipeline) SetPopupmenuBounds(width float64, height float64, row float64, col float64) {
+	p.call("nvim_ui_pum_set_bounds", nil, width, height, row, col)
+}
+
+// HighlightIDByName calls the nvim_get_hl_id_by_name API function.
+//
+//	:help nvim_get_hl_id_by_name()
+func (v *Vim) HighlightIDByName(name string) (int, error) {
+	var result int
+	err := v.call("nvim_get_hl_id_by_name", &result, name)
+	return result, err
+}
+
+// HighlightIDByNameContext is like HighlightIDByName with a context for cancelling the call.
+func (v *Vim) HighlightIDByNameContext(ctx context.Context, name string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_get_hl_id_by_name", &result, name)
+	return result, err
+}
+
+// HighlightIDByName calls the nvim_get_hl_id_by_name API function.
+//
+//	:help nvim_get_hl_id_by_name()
+func (p *Pipeline) HighlightIDByName(name string, result *int) {
+	p.call("nvim_get_hl_id_by_name", result, name)
+}
+
+// Highlight calls the nvim_get_hl API function.
+//
+//	:help nvim_get_hl()
+func (v *Vim) Highlight(nsID int, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_hl", &result, nsID, opts)
+	return result, err
+}
+
+// HighlightContext is like Highlight with a context for cancelling the call.
+func (v *Vim) HighlightContext(ctx context.Context, nsID int, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_hl", &result, nsID, opts)
+	return result, err
+}
+
+// Highlight calls the nvim_get_hl API function.
+//
+//	:help nvim_get_hl()
+func (p *Pipeline) Highlight(nsID int, opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_get_hl", result, nsID, opts)
+}
+
+// SetHighlight calls the nvim_set_hl API function.
+//
+//	:help nvim_set_hl()
+func (v *Vim) SetHighlight(nsID int, name string, val map[string]interface{}) error {
+	return v.call("nvim_set_hl", nil, nsID, name, val)
+}
+
+// SetHighlightContext is like SetHighlight with a context for cancelling the call.
+func (v *Vim) SetHighlightContext(ctx context.Context, nsID int, name string, val map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_set_hl", nil, nsID, name, val)
+}
+
+// SetHighlight calls the nvim_set_hl API function.
+//
+//	:help nvim_set_hl()
+func (p *Pipeline) SetHighlight(nsID int, name string, val map[string]interface{}) {
+	p.call("nvim_set_hl", nil, nsID, name, val)
+}
+
+// SetHighlightNamespace calls the nvim_set_hl_ns API function.
+//
+//	:help nvim_set_hl_ns()
+func (v *Vim) SetHighlightNamespace(nsID int) error {
+	return v.call("nvim_set_hl_ns", nil, nsID)
+}
+
+// SetHighlightNamespaceContext is like SetHighlightNamespace with a context for cancelling the call.
+func (v *Vim) SetHighlightNamespaceContext(ctx context.Context, nsID int) error {
+	return v.callContext(ctx, "nvim_set_hl_ns", nil, nsID)
+}
+
+// SetHighlightNamespace calls the nvim_set_hl_ns API function.
+//
+//	:help nvim_set_hl_ns()
+func (p *Pipeline) SetHighlightNamespace(nsID int) {
+	p.call("nvim_set_hl_ns", nil, nsID)
+}
+
+// SetHighlightNamespaceFast calls the nvim_set_hl_ns_fast API function.
+//
+//	:help nvim_set_hl_ns_fast()
+func (v *Vim) SetHighlightNamespaceFast(nsID int) error {
+	return v.call("nvim_set_hl_ns_fast", nil, nsID)
+}
+
+// SetHighlightNamespaceFastContext is like SetHighlightNamespaceFast with a context for cancelling the call.
+func (v *Vim) SetHighlightNamespaceFastContext(ctx context.Context, nsID int) error {
+	return v.callContext(ctx, "nvim_set_hl_ns_fast", nil, nsID)
+}
+
+// SetHighlightNamespaceFast calls the nvim_set_hl_ns_fast API function.
+//
+//	:help nvim_set_hl_ns_fast()
+func (p *Pipeline) SetHighlightNamespaceFast(nsID int) {
+	p.call("nvim_set_hl_ns_fast", nil, nsID)
+}
+
+// FeedKeys Pushes keys to the Neovim user input buffer. Options can be a string
+// with the following character flags:
+//
+//	m:  Remap keys. This is default.
+//	n:  Do not remap keys.
+//	t:  Handle keys as if typed; otherwise they are handled as if coming from a
+//	   mapping. This matters for undo, opening folds, etc.
+func (v *Vim) FeedKeys(keys string, mode string, escapeCsi bool) error {
+	return v.call("nvim_feedkeys", nil, keys, mode, escapeCsi)
+}
+
+// FeedKeysContext is like FeedKeys with a context for cancelling the call.
+func (v *Vim) FeedKeysContext(ctx context.Context, keys string, mode string, escapeCsi bool) error {
+	return v.callContext(ctx, "nvim_feedkeys", nil, keys, mode, escapeCsi)
+}
+
+// FeedKeys Pushes keys to the Neovim user input buffer. Options can be a string
+// with the following character flags:
+//
+//	m:  Remap keys. This is default.
+//	n:  Do not remap keys.
+//	t:  Handle keys as if typed; otherwise they are handled as if coming from a
+//	   mapping. This matters for undo, opening folds, etc.
+func (p *Pipeline) FeedKeys(keys string, mode string, escapeCsi bool) {
+	p.call("nvim_feedkeys", nil, keys, mode, escapeCsi)
+}
+
+// Input pushes bytes to the Neovim low level input buffer.
+//
+// Unlike FeedKeys, this uses the lowest level input buffer and the call is not
+// deferred. It returns the number of bytes actually written(which can be less
+// than what was requested if the buffer is full).
+func (v *Vim) Input(keys string) (int, error) {
+	var result int
+	err := v.call("nvim_input", &result, keys)
+	return result, err
+}
+
+// InputContext is like Input with a context for cancelling the call.
+func (v *Vim) InputContext(ctx context.Context, keys string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_input", &result, keys)
+	return result, err
+}
+
+// Input pushes bytes to the Neovim low level input buffer.
+//
+// Unlike FeedKeys, this uses the lowest level input buffer and the call is not
+// deferred. It returns the number of bytes actually written(which can be less
+// than what was requested if the buffer is full).
+func (p *Pipeline) Input(keys string, result *int) {
+	p.call("nvim_input", result, keys)
+}
+
+// InputMouse calls the nvim_input_mouse API function.
+//
+//	:help nvim_input_mouse()
+func (v *Vim) InputMouse(button string, action string, modifier string, grid int, row int, col int) error {
+	return v.call("nvim_input_mouse", nil, button, action, modifier, grid, row, col)
+}
+
+// InputMouseContext is like InputMouse with a context for cancelling the call.
+func (v *Vim) InputMouseContext(ctx context.Context, button string, action string, modifier string, grid int, row int, col int) error {
+	return v.callContext(ctx, "nvim_input_mouse", nil, button, action, modifier, grid, row, col)
+}
+
+// InputMouse calls the nvim_input_mouse API function.
+//
+//	:help nvim_input_mouse()
+func (p *Pipeline) InputMouse(button string, action string, modifier string, grid int, row int, col int) {
+	p.call("nvim_input_mouse", nil, button, action, modifier, grid, row, col)
+}
+
+// ReplaceTermcodes replaces any terminal code strings by byte sequences. The
+// returned sequences are Nvim's internal representation of keys, for example:
+//
+//	<esc> -> '\x1b'
+//	<cr>  -> '\r'
+//	<c-l> -> '\x0c'
+//	<up>  -> '\x80ku'
+//
+// The returned sequences can be used as input to feedkeys.
+func (v *Vim) ReplaceTermcodes(str string, fromPart bool, doLt bool, special bool) (string, error) {
+	var result string
+	err := v.call("nvim_replace_termcodes", &result, str, fromPart, doLt, special)
+	return result, err
+}
+
+// ReplaceTermcodesContext is like ReplaceTermcodes with a context for cancelling the call.
+func (v *Vim) ReplaceTermcodesContext(ctx context.Context, str string, fromPart bool, doLt bool, special bool) (string, error) {
+	var result string
+	err := v.callContext(ctx, "nvim_replace_termcodes", &result, str, fromPart, doLt, special)
+	return result, err
+}
+
+// ReplaceTermcodes replaces any terminal code strings by byte sequences. The
+// returned sequences are Nvim's internal representation of keys, for example:
+//
+//	<esc> -> '\x1b'
+//	<cr>  -> '\r'
+//	<c-l> -> '\x0c'
+//	<up>  -> '\x80ku'
+//
+// The returned sequences can be used as input to feedkeys.
+func (p *Pipeline) ReplaceTermcodes(str string, fromPart bool, doLt bool, special bool, result *string) {
+	p.call("nvim_replace_termcodes", result, str, fromPart, doLt, special)
+}
+
+// Notify calls the nvim_notify API function.
+//
+//	:help nvim_notify()
+func (v *Vim) Notify(msg string, logLevel int, opts map[string]interface{}, result interface{}) error {
+	return v.call("nvim_notify", result, msg, logLevel, opts)
+}
+
+// NotifyContext is like Notify with a context for cancelling the call.
+func (v *Vim) NotifyContext(ctx context.Context, msg string, logLevel int, opts map[string]interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_notify", result, msg, logLevel, opts)
+}
+
+// Notify calls the nvim_notify API function.
+//
+//	:help nvim_notify()
+func (p *Pipeline) Notify(msg string, logLevel int, opts map[string]interface{}, result interface{}) {
+	p.call("nvim_notify", result, msg, logLevel, opts)
+}
+
+// Strwidth returns the number of display cells the string occupies. Tab is
+// counted as one cell.
+func (v *Vim) Strwidth(str string) (int, error) {
+	var result int
+	err := v.call("nvim_strwidth", &result, str)
+	return result, err
+}
+
+// StrwidthContext is like Strwidth with a context for cancelling the call.
+func (v *Vim) StrwidthContext(ctx context.Context, str string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_strwidth", &result, str)
+	return result, err
+}
+
+// Strwidth returns the number of display cells the string occupies. Tab is
+// counted as one cell.
+func (p *Pipeline) Strwidth(str string, result *int) {
+	p.call("nvim_strwidth", result, str)
+}
+
+// RuntimePaths returns a list of paths contained in the runtimepath option.
+func (v *Vim) RuntimePaths() ([]string, error) {
+	var result []string
+	err := v.call("nvim_list_runtime_paths", &result)
+	return result, err
+}
+
+// RuntimePathsContext is like RuntimePaths with a context for cancelling the call.
+func (v *Vim) RuntimePathsContext(ctx context.Context) ([]string, error) {
+	var result []string
+	err := v.callContext(ctx, "nvim_list_runtime_paths", &result)
+	return result, err
+}
+
+// RuntimePaths returns a list of paths contained in the runtimepath option.
+func (p *Pipeline) RuntimePaths(result *[]string) {
+	p.call("nvim_list_runtime_paths", result)
+}
+
+// RuntimeFile calls the nvim_get_runtime_file API function.
+//
+//	:help nvim_get_runtime_file()
+func (v *Vim) RuntimeFile(name string, all bool) ([]string, error) {
+	var result []string
+	err := v.call("nvim_get_runtime_file", &result, name, all)
+	return result, err
+}
+
+// RuntimeFileContext is like RuntimeFile with a context for cancelling the call.
+func (v *Vim) RuntimeFileContext(ctx context.Context, name string, all bool) ([]string, error) {
+	var result []string
+	err := v.callContext(ctx, "nvim_get_runtime_file", &result, name, all)
+	return result, err
+}
+
+// RuntimeFile calls the nvim_get_runtime_file API function.
+//
+//	:help nvim_get_runtime_file()
+func (p *Pipeline) RuntimeFile(name string, all bool, result *[]string) {
+	p.call("nvim_get_runtime_file", result, name, all)
+}
+
+// ChangeDirectory changes Vim working directory.
+func (v *Vim) ChangeDirectory(dir string) error {
+	return v.call("nvim_set_current_dir", nil, dir)
+}
+
+// ChangeDirectoryContext is like ChangeDirectory with a context for cancelling the call.
+func (v *Vim) ChangeDirectoryContext(ctx context.Context, dir string) error {
+	return v.callContext(ctx, "nvim_set_current_dir", nil, dir)
+}
+
+// ChangeDirectory changes Vim working directory.
+func (p *Pipeline) ChangeDirectory(dir string) {
+	p.call("nvim_set_current_dir", nil, dir)
+}
+
+// CurrentLine gets the current line in the current buffer.
+func (v *Vim) CurrentLine() ([]byte, error) {
+	var result []byte
+	err := v.call("nvim_get_current_line", &result)
+	return result, err
+}
+
+// CurrentLineContext is like CurrentLine with a context for cancelling the call.
+func (v *Vim) CurrentLineContext(ctx context.Context) ([]byte, error) {
+	var result []byte
+	err := v.callContext(ctx, "nvim_get_current_line", &result)
+	return result, err
+}
+
+// CurrentLine gets the current line in the current buffer.
+func (p *Pipeline) CurrentLine(result *[]byte) {
+	p.call("nvim_get_current_line", result)
+}
+
+// SetCurrentLine sets the current line in the current buffer.
+func (v *Vim) SetCurrentLine(line []byte) error {
+	return v.call("nvim_set_current_line", nil, line)
+}
+
+// SetCurrentLineContext is like SetCurrentLine with a context for cancelling the call.
+func (v *Vim) SetCurrentLineContext(ctx context.Context, line []byte) error {
+	return v.callContext(ctx, "nvim_set_current_line", nil, line)
+}
+
+// SetCurrentLine sets the current line in the current buffer.
+func (p *Pipeline) SetCurrentLine(line []byte) {
+	p.call("nvim_set_current_line", nil, line)
+}
+
+// DeleteCurrentLine deletes the current line in the current buffer.
+func (v *Vim) DeleteCurrentLine() error {
+	return v.call("nvim_del_current_line", nil)
+}
+
+// DeleteCurrentLineContext is like DeleteCurrentLine with a context for cancelling the call.
+func (v *Vim) DeleteCurrentLineContext(ctx context.Context) error {
+	return v.callContext(ctx, "nvim_del_current_line", nil)
+}
+
+// DeleteCurrentLine deletes the current line in the current buffer.
+func (p *Pipeline) DeleteCurrentLine() {
+	p.call("nvim_del_current_line", nil)
+}
+
+// Var gets a global (g:) variable.
+func (v *Vim) Var(name string, result interface{}) error {
+	return v.call("nvim_get_var", result, name)
+}
+
+// VarContext is like Var with a context for cancelling the call.
+func (v *Vim) VarContext(ctx context.Context, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_var", result, name)
+}
+
+// Var gets a global (g:) variable.
+func (p *Pipeline) Var(name string, result interface{}) {
+	p.call("nvim_get_var", result, name)
+}
+
+// SetVar sets a global (g:) variable. Use DeleteVar to delete the variable.
+// Neovim does not return the previous value of the variable; result is set
+// to nil.
+func (v *Vim) SetVar(name string, value interface{}, result interface{}) error {
+	return v.call("nvim_set_var", result, name, value)
+}
+
+// SetVarContext is like SetVar with a context for cancelling the call.
+func (v *Vim) SetVarContext(ctx context.Context, name string, value interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_set_var", result, name, value)
+}
+
+// SetVar sets a global (g:) variable. Use DeleteVar to delete the variable.
+// Neovim does not return the previous value of the variable; result is set
+// to nil.
+func (p *Pipeline) SetVar(name string, value interface{}, result interface{}) {
+	p.call("nvim_set_var", result, name, value)
+}
+
+// DeleteVar calls the nvim_del_var API function.
+//
+//	:help nvim_del_var()
+func (v *Vim) DeleteVar(name string) error {
+	return v.call("nvim_del_var", nil, name)
+}
+
+// DeleteVarContext is like DeleteVar with a context for cancelling the call.
+func (v *Vim) DeleteVarContext(ctx context.Context, name string) error {
+	return v.callContext(ctx, "nvim_del_var", nil, name)
+}
+
+// DeleteVar calls the nvim_del_var API function.
+//
+//	:help nvim_del_var()
+func (p *Pipeline) DeleteVar(name string) {
+	p.call("nvim_del_var", nil, name)
+}
+
+// Vvar gets a vim (v:) variable.
+func (v *Vim) Vvar(name string, result interface{}) error {
+	return v.call("nvim_get_vvar", result, name)
+}
+
+// VvarContext is like Vvar with a context for cancelling the call.
+func (v *Vim) VvarContext(ctx context.Context, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_vvar", result, name)
+}
+
+// Vvar gets a vim (v:) variable.
+func (p *Pipeline) Vvar(name string, result interface{}) {
+	p.call("nvim_get_vvar", result, name)
+}
+
+// SetVvar calls the nvim_set_vvar API function.
+//
+//	:help nvim_set_vvar()
+func (v *Vim) SetVvar(name string, value interface{}) error {
+	return v.call("nvim_set_vvar", nil, name, value)
+}
+
+// SetVvarContext is like SetVvar with a context for cancelling the call.
+func (v *Vim) SetVvarContext(ctx context.Context, name string, value interface{}) error {
+	return v.callContext(ctx, "nvim_set_vvar", nil, name, value)
+}
+
+// SetVvar calls the nvim_set_vvar API function.
+//
+//	:help nvim_set_vvar()
+func (p *Pipeline) SetVvar(name string, value interface{}) {
+	p.call("nvim_set_vvar", nil, name, value)
+}
+
+// Echo calls the nvim_echo API function.
+//
+//	:help nvim_echo()
+func (v *Vim) Echo(chunks []interface{}, history bool, opts map[string]interface{}) error {
+	return v.call("nvim_echo", nil, chunks, history, opts)
+}
+
+// EchoContext is like Echo with a context for cancelling the call.
+func (v *Vim) EchoContext(ctx context.Context, chunks []interface{}, history bool, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_echo", nil, chunks, history, opts)
+}
+
+// Echo calls the nvim_echo API function.
+//
+//	:help nvim_echo()
+func (p *Pipeline) Echo(chunks []interface{}, history bool, opts map[string]interface{}) {
+	p.call("nvim_echo", nil, chunks, history, opts)
+}
+
+// WriteOut writes a message to vim output buffer. The string is split and
+// flushed after each newline. Incomplete lines are kept for writing later.
+func (v *Vim) WriteOut(str string) error {
+	return v.call("nvim_out_write", nil, str)
+}
+
+// WriteOutContext is like WriteOut with a context for cancelling the call.
+func (v *Vim) WriteOutContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_out_write", nil, str)
+}
+
+// WriteOut writes a message to vim output buffer. The string is split and
+// flushed after each newline. Incomplete lines are kept for writing later.
+func (p *Pipeline) WriteOut(str string) {
+	p.call("nvim_out_write", nil, str)
+}
+
+// WriteErr writes a message to vim error buffer. The string is split and
+// flushed after each newline. Incomplete lines are kept for writing later.
+func (v *Vim) WriteErr(str string) error {
+	return v.call("nvim_err_write", nil, str)
+}
+
+// WriteErrContext is like WriteErr with a context for cancelling the call.
+func (v *Vim) WriteErrContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_err_write", nil, str)
+}
+
+// WriteErr writes a message to vim error buffer. The string is split and
+// flushed after each newline. Incomplete lines are kept for writing later.
+func (p *Pipeline) WriteErr(str string) {
+	p.call("nvim_err_write", nil, str)
+}
+
+// ReportError writes prints str and a newline as an error message.
+func (v *Vim) ReportError(str string) error {
+	return v.call("nvim_err_writeln", nil, str)
+}
+
+// ReportErrorContext is like ReportError with a context for cancelling the call.
+func (v *Vim) ReportErrorContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_err_writeln", nil, str)
+}
+
+// ReportError writes prints str and a newline as an error message.
+func (p *Pipeline) ReportError(str string) {
+	p.call("nvim_err_writeln", nil, str)
+}
+
+// Buffers returns the current list of buffers.
+func (v *Vim) Buffers() ([]Buffer, error) {
+	var result []Buffer
+	err := v.call("nvim_list_bufs", &result)
+	return result, err
+}
+
+// BuffersContext is like Buffers with a context for cancelling the call.
+func (v *Vim) BuffersContext(ctx context.Context) ([]Buffer, error) {
+	var result []Buffer
+	err := v.callContext(ctx, "nvim_list_bufs", &result)
+	return result, err
+}
+
+// Buffers returns the current list of buffers.
+func (p *Pipeline) Buffers(result *[]Buffer) {
+	p.call("nvim_list_bufs", result)
+}
+
+// CurrentBuffer returns the current buffer.
+func (v *Vim) CurrentBuffer() (Buffer, error) {
+	var result Buffer
+	err := v.call("nvim_get_current_buf", &result)
+	return result, err
+}
+
+// CurrentBufferContext is like CurrentBuffer with a context for cancelling the call.
+func (v *Vim) CurrentBufferContext(ctx context.Context) (Buffer, error) {
+	var result Buffer
+	err := v.callContext(ctx, "nvim_get_current_buf", &result)
+	return result, err
+}
+
+// CurrentBuffer returns the current buffer.
+func (p *Pipeline) CurrentBuffer(result *Buffer) {
+	p.call("nvim_get_current_buf", result)
+}
+
+// SetCurrentBuffer sets the current buffer.
+func (v *Vim) SetCurrentBuffer(buffer Buffer) error {
+	return v.call("nvim_set_current_buf", nil, buffer)
+}
+
+// SetCurrentBufferContext is like SetCurrentBuffer with a context for cancelling the call.
+func (v *Vim) SetCurrentBufferContext(ctx context.Context, buffer Buffer) error {
+	return v.callContext(ctx, "nvim_set_current_buf", nil, buffer)
+}
+
+// SetCurrentBuffer sets the current buffer.
+func (p *Pipeline) SetCurrentBuffer(buffer Buffer) {
+	p.call("nvim_set_current_buf", nil, buffer)
+}
+
+// Windows returns the current list of windows.
+func (v *Vim) Windows() ([]Window, error) {
+	var result []Window
+	err := v.call("nvim_list_wins", &result)
+	return result, err
+}
+
+// WindowsContext is like Windows with a context for cancelling the call.
+func (v *Vim) WindowsContext(ctx context.Context) ([]Window, error) {
+	var result []Window
+	err := v.callContext(ctx, "nvim_list_wins", &result)
+	return result, err
+}
+
+// Windows returns the current list of windows.
+func (p *Pipeline) Windows(result *[]Window) {
+	p.call("nvim_list_wins", result)
+}
+
+// CurrentWindow returns the current window.
+func (v *Vim) CurrentWindow() (Window, error) {
+	var result Window
+	err := v.call("nvim_get_current_win", &result)
+	return result, err
+}
+
+// CurrentWindowContext is like CurrentWindow with a context for cancelling the call.
+func (v *Vim) CurrentWindowContext(ctx context.Context) (Window, error) {
+	var result Window
+	err := v.callContext(ctx, "nvim_get_current_win", &result)
+	return result, err
+}
+
+// CurrentWindow returns the current window.
+func (p *Pipeline) CurrentWindow(result *Window) {
+	p.call("nvim_get_current_win", result)
+}
+
+// SetCurrentWindow sets the current window.
+func (v *Vim) SetCurrentWindow(window Window) error {
+	return v.call("nvim_set_current_win", nil, window)
+}
+
+// SetCurrentWindowContext is like SetCurrentWindow with a context for cancelling the call.
+func (v *Vim) SetCurrentWindowContext(ctx context.Context, window Window) error {
+	return v.callContext(ctx, "nvim_set_current_win", nil, window)
+}
+
+// SetCurrentWindow sets the current window.
+func (p *Pipeline) SetCurrentWindow(window Window) {
+	p.call("nvim_set_current_win", nil, window)
+}
+
+// CreateBuffer calls the nvim_create_buf API function.
+//
+//	:help nvim_create_buf()
+func (v *Vim) CreateBuffer(listed bool, scratch bool) (Buffer, error) {
+	var result Buffer
+	err := v.call("nvim_create_buf", &result, listed, scratch)
+	return result, err
+}
+
+// CreateBufferContext is like CreateBuffer with a context for cancelling the call.
+func (v *Vim) CreateBufferContext(ctx context.Context, listed bool, scratch bool) (Buffer, error) {
+	var result Buffer
+	err := v.callContext(ctx, "nvim_create_buf", &result, listed, scratch)
+	return result, err
+}
+
+// CreateBuffer calls the nvim_create_buf API function.
+//
+//	:help nvim_create_buf()
+func (p *Pipeline) CreateBuffer(listed bool, scratch bool, result *Buffer) {
+	p.call("nvim_create_buf", result, listed, scratch)
+}
+
+// OpenTerm calls the nvim_open_term API function.
+//
+//	:help nvim_open_term()
+func (v *Vim) OpenTerm(buffer Buffer, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.call("nvim_open_term", &result, buffer, opts)
+	return result, err
+}
+
+// OpenTermContext is like OpenTerm with a context for cancelling the call.
+func (v *Vim) OpenTermContext(ctx context.Context, buffer Buffer, opts map[string]interface{}) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_open_term", &result, buffer, opts)
+	return result, err
+}
+
+// OpenTerm calls the nvim_open_term API function.
+//
+//	:help nvim_open_term()
+func (p *Pipeline) OpenTerm(buffer Buffer, opts map[string]interface{}, result *int) {
+	p.call("nvim_open_term", result, buffer, opts)
+}
+
+// ChannelSend calls the nvim_chan_send API function.
+//
+//	:help nvim_chan_send()
+func (v *Vim) ChannelSend(channel int, data string) error {
+	return v.call("nvim_chan_send", nil, channel, data)
+}
+
+// ChannelSendContext is like ChannelSend with a context for cancelling the call.
+func (v *Vim) ChannelSendContext(ctx context.Context, channel int, data string) error {
+	return v.callContext(ctx, "nvim_chan_send", nil, channel, data)
+}
+
+// ChannelSend calls the nvim_chan_send API function.
+//
+//	:help nvim_chan_send()
+func (p *Pipeline) ChannelSend(channel int, data string) {
+	p.call("nvim_chan_send", nil, channel, data)
+}
+
+// Tabpages returns the current list of tabpages.
+func (v *Vim) Tabpages() ([]Tabpage, error) {
+	var result []Tabpage
+	err := v.call("nvim_list_tabpages", &result)
+	return result, err
+}
+
+// TabpagesContext is like Tabpages with a context for cancelling the call.
+func (v *Vim) TabpagesContext(ctx context.Context) ([]Tabpage, error) {
+	var result []Tabpage
+	err := v.callContext(ctx, "nvim_list_tabpages", &result)
+	return result, err
+}
+
+// Tabpages returns the current list of tabpages.
+func (p *Pipeline) Tabpages(result *[]Tabpage) {
+	p.call("nvim_list_tabpages", result)
+}
+
+// CurrentTabpage returns the current tabpage.
+func (v *Vim) CurrentTabpage() (Tabpage, error) {
+	var result Tabpage
+	err := v.call("nvim_get_current_tabpage", &result)
+	return result, err
+}
+
+// CurrentTabpageContext is like CurrentTabpage with a context for cancelling the call.
+func (v *Vim) CurrentTabpageContext(ctx context.Context) (Tabpage, error) {
+	var result Tabpage
+	err := v.callContext(ctx, "nvim_get_current_tabpage", &result)
+	return result, err
+}
+
+// CurrentTabpage returns the current tabpage.
+func (p *Pipeline) CurrentTabpage(result *Tabpage) {
+	p.call("nvim_get_current_tabpage", result)
+}
+
+// SetCurrentTabpage sets the current tabpage.
+func (v *Vim) SetCurrentTabpage(tabpage Tabpage) error {
+	return v.call("nvim_set_current_tabpage", nil, tabpage)
+}
+
+// SetCurrentTabpageContext is like SetCurrentTabpage with a context for cancelling the call.
+func (v *Vim) SetCurrentTabpageContext(ctx context.Context, tabpage Tabpage) error {
+	return v.callContext(ctx, "nvim_set_current_tabpage", nil, tabpage)
+}
+
+// SetCurrentTabpage sets the current tabpage.
+func (p *Pipeline) SetCurrentTabpage(tabpage Tabpage) {
+	p.call("nvim_set_current_tabpage", nil, tabpage)
+}
+
+// Paste calls the nvim_paste API function.
+//
+//	:help nvim_paste()
+func (v *Vim) Paste(data string, crlf bool, phase int) (bool, error) {
+	var result bool
+	err := v.call("nvim_paste", &result, data, crlf, phase)
+	return result, err
+}
+
+// PasteContext is like Paste with a context for cancelling the call.
+func (v *Vim) PasteContext(ctx context.Context, data string, crlf bool, phase int) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_paste", &result, data, crlf, phase)
+	return result, err
+}
+
+// Paste calls the nvim_paste API function.
+//
+//	:help nvim_paste()
+func (p *Pipeline) Paste(data string, crlf bool, phase int, result *bool) {
+	p.call("nvim_paste", result, data, crlf, phase)
+}
+
+// Put calls the nvim_put API function.
+//
+//	:help nvim_put()
+func (v *Vim) Put(lines []string, typ string, after bool, follow bool) error {
+	return v.call("nvim_put", nil, lines, typ, after, follow)
+}
+
+// PutContext is like Put with a context for cancelling the call.
+func (v *Vim) PutContext(ctx context.Context, lines []string, typ string, after bool, follow bool) error {
+	return v.callContext(ctx, "nvim_put", nil, lines, typ, after, follow)
+}
+
+// Put calls the nvim_put API function.
+//
+//	:help nvim_put()
+func (p *Pipeline) Put(lines []string, typ string, after bool, follow bool) {
+	p.call("nvim_put", nil, lines, typ, after, follow)
+}
+
+// Subscribe subscribes to a Neovim event.
+func (v *Vim) Subscribe(event string) error {
+	return v.call("nvim_subscribe", nil, event)
+}
+
+// SubscribeContext is like Subscribe with a context for cancelling the call.
+func (v *Vim) SubscribeContext(ctx context.Context, event string) error {
+	return v.callContext(ctx, "nvim_subscribe", nil, event)
+}
+
+// Subscribe subscribes to a Neovim event.
+func (p *Pipeline) Subscribe(event string) {
+	p.call("nvim_subscribe", nil, event)
+}
+
+// Unsubscribe unsubscribes to a Neovim event.
+func (v *Vim) Unsubscribe(event string) error {
+	return v.call("nvim_unsubscribe", nil, event)
+}
+
+// UnsubscribeContext is like Unsubscribe with a context for cancelling the call.
+func (v *Vim) UnsubscribeContext(ctx context.Context, event string) error {
+	return v.callContext(ctx, "nvim_unsubscribe", nil, event)
+}
+
+// Unsubscribe unsubscribes to a Neovim event.
+func (p *Pipeline) Unsubscribe(event string) {
+	p.call("nvim_unsubscribe", nil, event)
+}
+
+// NameToColor calls the nvim_get_color_by_name API function.
+//
+//	:help nvim_get_color_by_name()
+func (v *Vim) NameToColor(name string) (int, error) {
+	var result int
+	err := v.call("nvim_get_color_by_name", &result, name)
+	return result, err
+}
+
+// NameToColorContext is like NameToColor with a context for cancelling the call.
+func (v *Vim) NameToColorContext(ctx context.Context, name string) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_get_color_by_name", &result, name)
+	return result, err
+}
+
+// NameToColor calls the nvim_get_color_by_name API function.
+//
+//	:help nvim_get_color_by_name()
+func (p *Pipeline) NameToColor(name string, result *int) {
+	p.call("nvim_get_color_by_name", result, name)
+}
+
+// ColorMap calls the nvim_get_color_map API function.
+//
+//	:help nvim_get_color_map()
+func (v *Vim) ColorMap() (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_color_map", &result)
+	return result, err
+}
+
+// ColorMapContext is like ColorMap with a context for cancelling the call.
+func (v *Vim) ColorMapContext(ctx context.Context) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_color_map", &result)
+	return result, err
+}
+
+// ColorMap calls the nvim_get_color_map API function.
+//
+//	:help nvim_get_color_map()
+func (p *Pipeline) ColorMap(result *map[string]interface{}) {
+	p.call("nvim_get_color_map", result)
+}
+
+// Context calls the nvim_get_context API function.
+//
+//	:help nvim_get_context()
+func (v *Vim) Context(opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_context", &result, opts)
+	return result, err
+}
+
+// ContextContext is like Context with a context for cancelling the call.
+func (v *Vim) ContextContext(ctx context.Context, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_context", &result, opts)
+	return result, err
+}
+
+// Context calls the nvim_get_context API function.
+//
+//	:help nvim_get_context()
+func (p *Pipeline) Context(opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_get_context", result, opts)
+}
+
+// LoadContext calls the nvim_load_context API function.
+//
+//	:help nvim_load_context()
+func (v *Vim) LoadContext(dict map[string]interface{}, result interface{}) error {
+	return v.call("nvim_load_context", result, dict)
+}
+
+// LoadContextContext is like LoadContext with a context for cancelling the call.
+func (v *Vim) LoadContextContext(ctx context.Context, dict map[string]interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_load_context", result, dict)
+}
+
+// LoadContext calls the nvim_load_context API function.
+//
+//	:help nvim_load_context()
+func (p *Pipeline) LoadContext(dict map[string]interface{}, result interface{}) {
+	p.call("nvim_load_context", result, dict)
+}
+
+// Mode calls the nvim_get_mode API function.
+//
+//	:help nvim_get_mode()
+func (v *Vim) Mode() (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_mode", &result)
+	return result, err
+}
+
+// ModeContext is like Mode with a context for cancelling the call.
+func (v *Vim) ModeContext(ctx context.Context) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_mode", &result)
+	return result, err
+}
+
+// Mode calls the nvim_get_mode API function.
+//
+//	:help nvim_get_mode()
+func (p *Pipeline) Mode(result *map[string]interface{}) {
+	p.call("nvim_get_mode", result)
+}
+
+// Keymap calls the nvim_get_keymap API function.
+//
+//	:help nvim_get_keymap()
+func (v *Vim) Keymap(mode string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := v.call("nvim_get_keymap", &result, mode)
+	return result, err
+}
+
+// KeymapContext is like Keymap with a context for cancelling the call.
+func (v *Vim) KeymapContext(ctx context.Context, mode string) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_keymap", &result, mode)
+	return result, err
+}
+
+// Keymap calls the nvim_get_keymap API function.
+//
+//	:help nvim_get_keymap()
+func (p *Pipeline) Keymap(mode string, result *[]map[string]interface{}) {
+	p.call("nvim_get_keymap", result, mode)
+}
+
+// SetRawKeymap calls the nvim_set_keymap API function.
+//
+//	:help nvim_set_keymap()
+func (v *Vim) SetRawKeymap(mode string, lhs string, rhs string, opts map[string]interface{}) error {
+	return v.call("nvim_set_keymap", nil, mode, lhs, rhs, opts)
+}
+
+// SetRawKeymapContext is like SetRawKeymap with a context for cancelling the call.
+func (v *Vim) SetRawKeymapContext(ctx context.Context, mode string, lhs string, rhs string, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_set_keymap", nil, mode, lhs, rhs, opts)
+}
+
+// SetRawKeymap calls the nvim_set_keymap API function.
+//
+//	:help nvim_set_keymap()
+func (p *Pipeline) SetRawKeymap(mode string, lhs string, rhs string, opts map[string]interface{}) {
+	p.call("nvim_set_keymap", nil, mode, lhs, rhs, opts)
+}
+
+// DeleteKeymap calls the nvim_del_keymap API function.
+//
+//	:help nvim_del_keymap()
+func (v *Vim) DeleteKeymap(mode string, lhs string) error {
+	return v.call("nvim_del_keymap", nil, mode, lhs)
+}
+
+// DeleteKeymapContext is like DeleteKeymap with a context for cancelling the call.
+func (v *Vim) DeleteKeymapContext(ctx context.Context, mode string, lhs string) error {
+	return v.callContext(ctx, "nvim_del_keymap", nil, mode, lhs)
+}
+
+// DeleteKeymap calls the nvim_del_keymap API function.
+//
+//	:help nvim_del_keymap()
+func (p *Pipeline) DeleteKeymap(mode string, lhs string) {
+	p.call("nvim_del_keymap", nil, mode, lhs)
+}
+
+// APIInfo calls the nvim_get_api_info API function.
+//
+//	:help nvim_get_api_info()
+func (v *Vim) APIInfo() ([]interface{}, error) {
+	var result []interface{}
+	err := v.call("nvim_get_api_info", &result)
+	return result, err
+}
+
+// APIInfoContext is like APIInfo with a context for cancelling the call.
+func (v *Vim) APIInfoContext(ctx context.Context) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_get_api_info", &result)
+	return result, err
+}
+
+// APIInfo calls the nvim_get_api_info API function.
+//
+//	:help nvim_get_api_info()
+func (p *Pipeline) APIInfo(result *[]interface{}) {
+	p.call("nvim_get_api_info", result)
+}
+
+// SetClientInfo calls the nvim_set_client_info API function.
+//
+//	:help nvim_set_client_info()
+func (v *Vim) SetClientInfo(name string, version map[string]interface{}, typ string, methods map[string]interface{}, attributes map[string]interface{}) error {
+	return v.call("nvim_set_client_info", nil, name, version, typ, methods, attributes)
+}
+
+// SetClientInfoContext is like SetClientInfo with a context for cancelling the call.
+func (v *Vim) SetClientInfoContext(ctx context.Context, name string, version map[string]interface{}, typ string, methods map[string]interface{}, attributes map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_set_client_info", nil, name, version, typ, methods, attributes)
+}
+
+// SetClientInfo calls the nvim_set_client_info API function.
+//
+//	:help nvim_set_client_info()
+func (p *Pipeline) SetClientInfo(name string, version map[string]interface{}, typ string, methods map[string]interface{}, attributes map[string]interface{}) {
+	p.call("nvim_set_client_info", nil, name, version, typ, methods, attributes)
+}
+
+// ChannelInfo calls the nvim_get_chan_info API function.
+//
+//	:help nvim_get_chan_info()
+func (v *Vim) ChannelInfo(channel int) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_get_chan_info", &result, channel)
+	return result, err
+}
+
+// ChannelInfoContext is like ChannelInfo with a context for cancelling the call.
+func (v *Vim) ChannelInfoContext(ctx context.Context, channel int) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_get_chan_info", &result, channel)
+	return result, err
+}
+
+// ChannelInfo calls the nvim_get_chan_info API function.
+//
+//	:help nvim_get_chan_info()
+func (p *Pipeline) ChannelInfo(channel int, result *map[string]interface{}) {
+	p.call("nvim_get_chan_info", result, channel)
+}
+
+// Channels calls the nvim_list_chans API function.
+//
+//	:help nvim_list_chans()
+func (v *Vim) Channels() ([]interface{}, error) {
+	var result []interface{}
+	err := v.call("nvim_list_chans", &result)
+	return result, err
+}
+
+// ChannelsContext is like Channels with a context for cancelling the call.
+func (v *Vim) ChannelsContext(ctx context.Context) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_list_chans", &result)
+	return result, err
+}
+
+// Channels calls the nvim_list_chans API function.
+//
+//	:help nvim_list_chans()
+func (p *Pipeline) Channels(result *[]interface{}) {
+	p.call("nvim_list_chans", result)
+}
+
+// UIs calls the nvim_list_uis API function.
+//
+//	:help nvim_list_uis()
+func (v *Vim) UIs() ([]interface{}, error) {
+	var result []interface{}
+	err := v.call("nvim_list_uis", &result)
+	return result, err
+}
+
+// UIsContext is like UIs with a context for cancelling the call.
+func (v *Vim) UIsContext(ctx context.Context) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_list_uis", &result)
+	return result, err
+}
+
+// UIs calls the nvim_list_uis API function.
+//
+//	:help nvim_list_uis()
+func (p *Pipeline) UIs(result *[]interface{}) {
+	p.call("nvim_list_uis", result)
+}
+
+// ProcessChildren calls the nvim_get_proc_children API function.
+//
+//	:help nvim_get_proc_children()
+func (v *Vim) ProcessChildren(pid int) ([]interface{}, error) {
+	var result []interface{}
+	err := v.call("nvim_get_proc_children", &result, pid)
+	return result, err
+}
+
+// ProcessChildrenContext is like ProcessChildren with a context for cancelling the call.
+func (v *Vim) ProcessChildrenContext(ctx context.Context, pid int) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_get_proc_children", &result, pid)
+	return result, err
+}
+
+// ProcessChildren calls the nvim_get_proc_children API function.
+//
+//	:help nvim_get_proc_children()
+func (p *Pipeline) ProcessChildren(pid int, result *[]interface{}) {
+	p.call("nvim_get_proc_children", result, pid)
+}
+
+// Process calls the nvim_get_proc API function.
+//
+//	:help nvim_get_proc()
+func (v *Vim) Process(pid int, result interface{}) error {
+	return v.call("nvim_get_proc", result, pid)
+}
+
+// ProcessContext is like Process with a context for cancelling the call.
+func (v *Vim) ProcessContext(ctx context.Context, pid int, result interface{}) error {
+	return v.callContext(ctx, "nvim_get_proc", result, pid)
+}
+
+// Process calls the nvim_get_proc API function.
+//
+//	:help nvim_get_proc()
+func (p *Pipeline) Process(pid int, result interface{}) {
+	p.call("nvim_get_proc", result, pid)
+}
+
+// SelectPopupmenuItem calls the nvim_select_popupmenu_item API function.
+//
+//	:help nvim_select_popupmenu_item()
+func (v *Vim) SelectPopupmenuItem(item int, insert bool, finish bool, opts map[string]interface{}) error {
+	return v.call("nvim_select_popupmenu_item", nil, item, insert, finish, opts)
+}
+
+// SelectPopupmenuItemContext is like SelectPopupmenuItem with a context for cancelling the call.
+func (v *Vim) SelectPopupmenuItemContext(ctx context.Context, item int, insert bool, finish bool, opts map[string]interface{}) error {
+	return v.callContext(ctx, "nvim_select_popupmenu_item", nil, item, insert, finish, opts)
+}
+
+// SelectPopupmenuItem calls the nvim_select_popupmenu_item API function.
+//
+//	:help nvim_select_popupmenu_item()
+func (p *Pipeline) SelectPopupmenuItem(item int, insert bool, finish bool, opts map[string]interface{}) {
+	p.call("nvim_select_popupmenu_item", nil, item, insert, finish, opts)
+}
+
+// DeleteMark calls the nvim_del_mark API function.
+//
+//	:help nvim_del_mark()
+func (v *Vim) DeleteMark(name string) (bool, error) {
+	var result bool
+	err := v.call("nvim_del_mark", &result, name)
+	return result, err
+}
+
+// DeleteMarkContext is like DeleteMark with a context for cancelling the call.
+func (v *Vim) DeleteMarkContext(ctx context.Context, name string) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_del_mark", &result, name)
+	return result, err
+}
+
+// DeleteMark calls the nvim_del_mark API function.
+//
+//	:help nvim_del_mark()
+func (p *Pipeline) DeleteMark(name string, result *bool) {
+	p.call("nvim_del_mark", result, name)
+}
+
+// Mark calls the nvim_get_mark API function.
+//
+//	:help nvim_get_mark()
+func (v *Vim) Mark(name string, opts map[string]interface{}) ([]interface{}, error) {
+	var result []interface{}
+	err := v.call("nvim_get_mark", &result, name, opts)
+	return result, err
+}
+
+// MarkContext is like Mark with a context for cancelling the call.
+func (v *Vim) MarkContext(ctx context.Context, name string, opts map[string]interface{}) ([]interface{}, error) {
+	var result []interface{}
+	err := v.callContext(ctx, "nvim_get_mark", &result, name, opts)
+	return result, err
+}
+
+// Mark calls the nvim_get_mark API function.
+//
+//	:help nvim_get_mark()
+func (p *Pipeline) Mark(name string, opts map[string]interface{}, result *[]interface{}) {
+	p.call("nvim_get_mark", result, name, opts)
+}
+
+// EvalStatusline calls the nvim_eval_statusline API function.
+//
+//	:help nvim_eval_statusline()
+func (v *Vim) EvalStatusline(str string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_eval_statusline", &result, str, opts)
+	return result, err
+}
+
+// EvalStatuslineContext is like EvalStatusline with a context for cancelling the call.
+func (v *Vim) EvalStatuslineContext(ctx context.Context, str string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_eval_statusline", &result, str, opts)
+	return result, err
+}
+
+// EvalStatusline calls the nvim_eval_statusline API function.
+//
+//	:help nvim_eval_statusline()
+func (p *Pipeline) EvalStatusline(str string, opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_eval_statusline", result, str, opts)
+}
+
+// Exec calls the nvim_exec2 API function.
+//
+//	:help nvim_exec2()
+func (v *Vim) Exec(src string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_exec2", &result, src, opts)
+	return result, err
+}
+
+// ExecContext is like Exec with a context for cancelling the call.
+func (v *Vim) ExecContext(ctx context.Context, src string, opts map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_exec2", &result, src, opts)
+	return result, err
+}
+
+// Exec calls the nvim_exec2 API function.
+//
+//	:help nvim_exec2()
+func (p *Pipeline) Exec(src string, opts map[string]interface{}, result *map[string]interface{}) {
+	p.call("nvim_exec2", result, src, opts)
+}
+
+// Command executes a single ex command.
+func (v *Vim) Command(str string) error {
+	return v.call("nvim_command", nil, str)
+}
+
+// CommandContext is like Command with a context for cancelling the call.
+func (v *Vim) CommandContext(ctx context.Context, str string) error {
+	return v.callContext(ctx, "nvim_command", nil, str)
+}
+
+// Command executes a single ex command.
+func (p *Pipeline) Command(str string) {
+	p.call("nvim_command", nil, str)
+}
+
+// Eval evaluates the expression str using the Vim internal expression
+// evaluator.
+//
+//	:help expression
+func (v *Vim) Eval(str string, result interface{}) error {
+	return v.call("nvim_eval", result, str)
+}
+
+// EvalContext is like Eval with a context for cancelling the call.
+func (v *Vim) EvalContext(ctx context.Context, str string, result interface{}) error {
+	return v.callContext(ctx, "nvim_eval", result, str)
+}
+
+// Eval evaluates the expression str using the Vim internal expression
+// evaluator.
+//
+//	:help expression
+func (p *Pipeline) Eval(str string, result interface{}) {
+	p.call("nvim_eval", result, str)
+}
+
+// CallDictFunction calls the nvim_call_dict_function API function.
+//
+//	:help nvim_call_dict_function()
+func (v *Vim) CallDictFunction(dict interface{}, fn string, args []interface{}, result interface{}) error {
+	return v.call("nvim_call_dict_function", result, dict, fn, args)
+}
+
+// CallDictFunctionContext is like CallDictFunction with a context for cancelling the call.
+func (v *Vim) CallDictFunctionContext(ctx context.Context, dict interface{}, fn string, args []interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_call_dict_function", result, dict, fn, args)
+}
+
+// CallDictFunction calls the nvim_call_dict_function API function.
+//
+//	:help nvim_call_dict_function()
+func (p *Pipeline) CallDictFunction(dict interface{}, fn string, args []interface{}, result interface{}) {
+	p.call("nvim_call_dict_function", result, dict, fn, args)
+}
+
+// ParseExpression calls the nvim_parse_expression API function.
+//
+//	:help nvim_parse_expression()
+func (v *Vim) ParseExpression(expr string, flags string, highlight bool) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.call("nvim_parse_expression", &result, expr, flags, highlight)
+	return result, err
+}
+
+// ParseExpressionContext is like ParseExpression with a context for cancelling the call.
+func (v *Vim) ParseExpressionContext(ctx context.Context, expr string, flags string, highlight bool) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := v.callContext(ctx, "nvim_parse_expression", &result, expr, flags, highlight)
+	return result, err
+}
+
+// ParseExpression calls the nvim_parse_expression API function.
+//
+//	:help nvim_parse_expression()
+func (p *Pipeline) ParseExpression(expr string, flags string, highlight bool, result *map[string]interface{}) {
+	p.call("nvim_parse_expression", result, expr, flags, highlight)
+}
+
+// WindowBuffer returns the current buffer in a window.
+func (v *Vim) WindowBuffer(window Window) (Buffer, error) {
+	var result Buffer
+	err := v.call("nvim_win_get_buf", &result, window)
+	return result, err
+}
+
+// WindowBufferContext is like WindowBuffer with a context for cancelling the call.
+func (v *Vim) WindowBufferContext(ctx context.Context, window Window) (Buffer, error) {
+	var result Buffer
+	err := v.callContext(ctx, "nvim_win_get_buf", &result, window)
+	return result, err
+}
+
+// WindowBuffer returns the current buffer in a window.
+func (p *Pipeline) WindowBuffer(window Window, result *Buffer) {
+	p.call("nvim_win_get_buf", result, window)
+}
+
+// SetWindowBuffer calls the nvim_win_set_buf API function.
+//
+//	:help nvim_win_set_buf()
+func (v *Vim) SetWindowBuffer(window Window, buffer Buffer) error {
+	return v.call("nvim_win_set_buf", nil, window, buffer)
+}
+
+// SetWindowBufferContext is like SetWindowBuffer with a context for cancelling the call.
+func (v *Vim) SetWindowBufferContext(ctx context.Context, window Window, buffer Buffer) error {
+	return v.callContext(ctx, "nvim_win_set_buf", nil, window, buffer)
+}
+
+// SetWindowBuffer calls the nvim_win_set_buf API function.
+//
+//	:help nvim_win_set_buf()
+func (p *Pipeline) SetWindowBuffer(window Window, buffer Buffer) {
+	p.call("nvim_win_set_buf", nil, window, buffer)
+}
+
+// WindowCursor returns the cursor position in the window.
+func (v *Vim) WindowCursor(window Window) ([2]int, error) {
+	var result [2]int
+	err := v.call("nvim_win_get_cursor", &result, window)
+	return result, err
+}
+
+// WindowCursorContext is like WindowCursor with a context for cancelling the call.
+func (v *Vim) WindowCursorContext(ctx context.Context, window Window) ([2]int, error) {
+	var result [2]int
+	err := v.callContext(ctx, "nvim_win_get_cursor", &result, window)
+	return result, err
+}
+
+// WindowCursor returns the cursor position in the window.
+func (p *Pipeline) WindowCursor(window Window, result *[2]int) {
+	p.call("nvim_win_get_cursor", result, window)
+}
+
+// SetWindowCursor sets the cursor position in the window to the given position.
+func (v *Vim) SetWindowCursor(window Window, pos [2]int) error {
+	return v.call("nvim_win_set_cursor", nil, window, pos)
+}
+
+// SetWindowCursorContext is like SetWindowCursor with a context for cancelling the call.
+func (v *Vim) SetWindowCursorContext(ctx context.Context, window Window, pos [2]int) error {
+	return v.callContext(ctx, "nvim_win_set_cursor", nil, window, pos)
+}
+
+// SetWindowCursor sets the cursor position in the window to the given position.
+func (p *Pipeline) SetWindowCursor(window Window, pos [2]int) {
+	p.call("nvim_win_set_cursor", nil, window, pos)
+}
+
+// WindowHeight returns the window height.
+func (v *Vim) WindowHeight(window Window) (int, error) {
+	var result int
+	err := v.call("nvim_win_get_height", &result, window)
+	return result, err
+}
+
+// WindowHeightContext is like WindowHeight with a context for cancelling the call.
+func (v *Vim) WindowHeightContext(ctx context.Context, window Window) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_win_get_height", &result, window)
 	return result, err
 }
 
-// AddBufferHighlight adds a highlight to buffer and returns the source id of
-// the highlight.
-//
-// AddBufferHighlight can be used for plugins which dynamically generate
-// highlights to a buffer (like a semantic highlighter or linter). The function
-// adds a single highlight to a buffer. Unlike matchaddpos() highlights follow
-// changes to line numbering (as lines are inserted/removed above the
-// highlighted line), like signs and marks do.
-//
-// The srcID is useful for batch deletion/updating of a set of highlights. When
-// called with srcID = 0, an unique source id is generated and returned.
-// Succesive calls can pass in it as srcID to add new highlights to the same
-// source group. All highlights in the same group can then be cleared with
-// ClearBufferHighlight. If the highlight never will be manually deleted pass
-// in -1 for srcID.
-//
-// If hlGroup is the empty string no highlight is added, but a new srcID is
-// still returned. This is useful for an external plugin to synchrounously
-// request an unique srcID at initialization, and later asynchronously add and
-// clear highlights in response to buffer changes.
-//
-// The startCol and endCol parameters specify the range of columns to
-// highlight. Use endCol = -1 to highlight to the end of the line.
-func (p *Pipeline) AddBufferHighlight(buffer Buffer, srcID int, hlGroup string, line int, startCol int, endCol int, result *int) {
-	p.call("buffer_add_highlight", result, buffer, srcID, hlGroup, line, startCol, endCol)
+// WindowHeight returns the window height.
+func (p *Pipeline) WindowHeight(window Window, result *int) {
+	p.call("nvim_win_get_height", result, window)
 }
 
-// ClearBufferHighlight clears highlights from a given source group and a range
-// of lines.
-//
-// To clear a source group in the entire buffer, pass in 1 and -1 to startLine
-// and endLine respectively.
-//
-// The lineStart and lineEnd parameters specify the range of lines to clear.
-// The end of range is exclusive. Specify -1 to clear to the end of the file.
-func (v *Vim) ClearBufferHighlight(buffer Buffer, srcID int, startLine int, endLine int) error {
-	return v.call("buffer_clear_highlight", nil, buffer, srcID, startLine, endLine)
+// SetWindowHeight sets the window height.
+func (v *Vim) SetWindowHeight(window Window, height int) error {
+	return v.call("nvim_win_set_height", nil, window, height)
 }
 
-// ClearBufferHighlight clears highlights from a given source group and a range
-// of lines.
-//
-// To clear a source group in the entire buffer, pass in 1 and -1 to startLine
-// and endLine respectively.
-//
-// The lineStart and lineEnd parameters specify the range of lines to clear.
-// The end of range is exclusive. Specify -1 to clear to the end of the file.
-func (p *Pipeline) ClearBufferHighlight(buffer Buffer, srcID int, startLine int, endLine int) {
-	p.call("buffer_clear_highlight", nil, buffer, srcID, startLine, endLine)
+// SetWindowHeightContext is like SetWindowHeight with a context for cancelling the call.
+func (v *Vim) SetWindowHeightContext(ctx context.Context, window Window, height int) error {
+	return v.callContext(ctx, "nvim_win_set_height", nil, window, height)
 }
 
-// TabpageWindows returns the windows in a tabpage.
-func (v *Vim) TabpageWindows(tabpage Tabpage) ([]Window, error) {
-	var result []Window
-	err := v.call("tabpage_get_windows", &result, tabpage)
+// SetWindowHeight sets the window height.
+func (p *Pipeline) SetWindowHeight(window Window, height int) {
+	p.call("nvim_win_set_height", nil, window, height)
+}
+
+// WindowWidth returns the window width.
+func (v *Vim) WindowWidth(window Window) (int, error) {
+	var result int
+	err := v.call("nvim_win_get_width", &result, window)
 	return result, err
 }
 
-// TabpageWindows returns the windows in a tabpage.
-func (p *Pipeline) TabpageWindows(tabpage Tabpage, result *[]Window) {
-	p.call("tabpage_get_windows", result, tabpage)
+// WindowWidthContext is like WindowWidth with a context for cancelling the call.
+func (v *Vim) WindowWidthContext(ctx context.Context, window Window) (int, error) {
+	var result int
+	err := v.callContext(ctx, "nvim_win_get_width", &result, window)
+	return result, err
 }
 
-// TabpageVar gets a tab-scoped (t:) variable.
-func (v *Vim) TabpageVar(tabpage Tabpage, name string, result interface{}) error {
-	return v.call("tabpage_get_var", result, tabpage, name)
+// WindowWidth returns the window width.
+func (p *Pipeline) WindowWidth(window Window, result *int) {
+	p.call("nvim_win_get_width", result, window)
 }
 
-// TabpageVar gets a tab-scoped (t:) variable.
-func (p *Pipeline) TabpageVar(tabpage Tabpage, name string, result interface{}) {
-	p.call("tabpage_get_var", result, tabpage, name)
+// SetWindowWidth sets the window width.
+func (v *Vim) SetWindowWidth(window Window, width int) error {
+	return v.call("nvim_win_set_width", nil, window, width)
 }
 
-// SetTabpageVar isets a tab-scoped (t:) variable. A nil value deletes the variable.
-func (v *Vim) SetTabpageVar(tabpage Tabpage, name string, value interface{}, result interface{}) error {
-	return v.call("tabpage_set_var", result, tabpage, name, value)
+// SetWindowWidthContext is like SetWindowWidth with a context for cancelling the call.
+func (v *Vim) SetWindowWidthContext(ctx context.Context, window Window, width int) error {
+	return v.callContext(ctx, "nvim_win_set_width", nil, window, width)
 }
 
-// SetTabpageVar isets a tab-scoped (t:) variable. A nil value deletes the variable.
-func (p *Pipeline) SetTabpageVar(tabpage Tabpage, name string, value interface{}, result interface{}) {
-	p.call("tabpage_set_var", result, tabpage, name, value)
+// SetWindowWidth sets the window width.
+func (p *Pipeline) SetWindowWidth(window Window, width int) {
+	p.call("nvim_win_set_width", nil, window, width)
 }
 
-// TabpageWindow gets the current window in a tab page.
-func (v *Vim) TabpageWindow(tabpage Tabpage) (Window, error) {
-	var result Window
-	err := v.call("tabpage_get_window", &result, tabpage)
-	return result, err
+// WindowVar gets a window-scoped (w:) variable.
+func (v *Vim) WindowVar(window Window, name string, result interface{}) error {
+	return v.call("nvim_win_get_var", result, window, name)
 }
 
-// TabpageWindow gets the current window in a tab page.
-func (p *Pipeline) TabpageWindow(tabpage Tabpage, result *Window) {
-	p.call("tabpage_get_window", result, tabpage)
+// WindowVarContext is like WindowVar with a context for cancelling the call.
+func (v *Vim) WindowVarContext(ctx context.Context, window Window, name string, result interface{}) error {
+	return v.callContext(ctx, "nvim_win_get_var", result, window, name)
 }
 
-// IsTabpageValid checks if a tab page is valid.
-func (v *Vim) IsTabpageValid(tabpage Tabpage) (bool, error) {
-	var result bool
-	err := v.call("tabpage_is_valid", &result, tabpage)
-	return result, err
+// WindowVar gets a window-scoped (w:) variable.
+func (p *Pipeline) WindowVar(window Window, name string, result interface{}) {
+	p.call("nvim_win_get_var", result, window, name)
 }
 
-// IsTabpageValid checks if a tab page is valid.
-func (p *Pipeline) IsTabpageValid(tabpage Tabpage, result *bool) {
-	p.call("tabpage_is_valid", result, tabpage)
+// SetWindowVar sets a window-scoped (w:) variable. Use DeleteWindowVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+func (v *Vim) SetWindowVar(window Window, name string, value interface{}, result interface{}) error {
+	return v.call("nvim_win_set_var", result, window, name, value)
 }
 
-// Command executes a single ex command.
-func (v *Vim) Command(str string) error {
-	return v.call("vim_command", nil, str)
+// SetWindowVarContext is like SetWindowVar with a context for cancelling the call.
+func (v *Vim) SetWindowVarContext(ctx context.Context, window Window, name string, value interface{}, result interface{}) error {
+	return v.callContext(ctx, "nvim_win_set_var", result, window, name, value)
 }
 
-// Command executes a single ex command.
-func (p *Pipeline) Command(str string) {
-	p.call("vim_command", nil, str)
+// SetWindowVar sets a window-scoped (w:) variable. Use DeleteWindowVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+func (p *Pipeline) SetWindowVar(window Window, name string, value interface{}, result interface{}) {
+	p.call("nvim_win_set_var", result, window, name, value)
 }
 
-// FeedKeys Pushes keys to the Neovim user input buffer. Options can be a string
-// with the following character flags:
+// DeleteWindowVar calls the nvim_win_del_var API function.
 //
-//  m:  Remap keys. This is default.
-//  n:  Do not remap keys.
-//  t:  Handle keys as if typed; otherwise they are handled as if coming from a
-//     mapping. This matters for undo, opening folds, etc.
-func (v *Vim) FeedKeys(keys string, mode string, escapeCsi bool) error {
-	return v.call("vim_feedkeys", nil, keys, mode, escapeCsi)
+//	:help nvim_win_del_var()
+func (v *Vim) DeleteWindowVar(window Window, name string) error {
+	return v.call("nvim_win_del_var", nil, window, name)
 }
 
-// FeedKeys Pushes keys to the Neovim user input buffer. Options can be a string
-// with the following character flags:
-//
-//  m:  Remap keys. This is default.
-//  n:  Do not remap keys.
-//  t:  Handle keys as if typed; otherwise they are handled as if coming from a
-//     mapping. This matters for undo, opening folds, etc.
-func (p *Pipeline) FeedKeys(keys string, mode string, escapeCsi bool) {
-	p.call("vim_feedkeys", nil, keys, mode, escapeCsi)
+// DeleteWindowVarContext is like DeleteWindowVar with a context for cancelling the call.
+func (v *Vim) DeleteWindowVarContext(ctx context.Context, window Window, name string) error {
+	return v.callContext(ctx, "nvim_win_del_var", nil, window, name)
 }
 
-// Input pushes bytes to the Neovim low level input buffer.
+// DeleteWindowVar calls the nvim_win_del_var API function.
 //
-// Unlike FeedKeys, this uses the lowest level input buffer and the call is not
-// deferred. It returns the number of bytes actually written(which can be less
-// than what was requested if the buffer is full).
-func (v *Vim) Input(keys string) (int, error) {
-	var result int
-	err := v.call("vim_input", &result, keys)
-	return result, err
+//	:help nvim_win_del_var()
+func (p *Pipeline) DeleteWindowVar(window Window, name string) {
+	p.call("nvim_win_del_var", nil, window, name)
 }
 
-// Input pushes bytes to the Neovim low level input buffer.
-//
-// Unlike FeedKeys, this uses the lowest level input buffer and the call is not
-// deferred. It returns the number of bytes actually written(which can be less
-// than what was requested if the buffer is full).
-func (p *Pipeline) Input(keys string, result *int) {
-	p.call("vim_input", result, keys)
+// WindowPosition gets the window position in display cells. First position is zero.
+func (v *Vim) WindowPosition(window Window) ([2]int, error) {
+	var result [2]int
+	err := v.call("nvim_win_get_position", &result, window)
+	return result, err
 }
 
-// ReplaceTermcodes replaces any terminal code strings by byte sequences. The
-// returned sequences are Nvim's internal representation of keys, for example:
-//
-//  <esc> -> '\x1b'
-//  <cr>  -> '\r'
-//  <c-l> -> '\x0c'
-//  <up>  -> '\x80ku'
-//
-// The returned sequences can be used as input to feedkeys.
-func (v *Vim) ReplaceTermcodes(str string, fromPart bool, doLt bool, special bool) (string, error) {
-	var result string
-	err := v.call("vim_replace_termcodes", &result, str, fromPart, doLt, special)
+// WindowPositionContext is like WindowPosition with a context for cancelling the call.
+func (v *Vim) WindowPositionContext(ctx context.Context, window Window) ([2]int, error) {
+	var result [2]int
+	err := v.callContext(ctx, "nvim_win_get_position", &result, window)
 	return result, err
 }
 
-// ReplaceTermcodes replaces any terminal code strings by byte sequences. The
-// returned sequences are Nvim's internal representation of keys, for example:
-//
-//  <esc> -> '\x1b'
-//  <cr>  -> '\r'
-//  <c-l> -> '\x0c'
-//  <up>  -> '\x80ku'
-//
-// The returned sequences can be used as input to feedkeys.
-func (p *Pipeline) ReplaceTermcodes(str string, fromPart bool, doLt bool, special bool, result *string) {
-	p.call("vim_replace_termcodes", result, str, fromPart, doLt, special)
+// WindowPosition gets the window position in display cells. First position is zero.
+func (p *Pipeline) WindowPosition(window Window, result *[2]int) {
+	p.call("nvim_win_get_position", result, window)
 }
 
-// CommandOutput executes a single ex command and returns the output.
-func (v *Vim) CommandOutput(str string) (string, error) {
-	var result string
-	err := v.call("vim_command_output", &result, str)
+// WindowTabpage gets the tab page that contains the window.
+func (v *Vim) WindowTabpage(window Window) (Tabpage, error) {
+	var result Tabpage
+	err := v.call("nvim_win_get_tabpage", &result, window)
 	return result, err
 }
 
-// CommandOutput executes a single ex command and returns the output.
-func (p *Pipeline) CommandOutput(str string, result *string) {
-	p.call("vim_command_output", result, str)
+// WindowTabpageContext is like WindowTabpage with a context for cancelling the call.
+func (v *Vim) WindowTabpageContext(ctx context.Context, window Window) (Tabpage, error) {
+	var result Tabpage
+	err := v.callContext(ctx, "nvim_win_get_tabpage", &result, window)
+	return result, err
 }
 
-// Eval evaluates the expression str using the Vim internal expression
-// evaluator.
-//
-//  :help expression
-func (v *Vim) Eval(str string, result interface{}) error {
-	return v.call("vim_eval", result, str)
+// WindowTabpage gets the tab page that contains the window.
+func (p *Pipeline) WindowTabpage(window Window, result *Tabpage) {
+	p.call("nvim_win_get_tabpage", result, window)
 }
 
-// Eval evaluates the expression str using the Vim internal expression
-// evaluator.
+// WindowNumber calls the nvim_win_get_number API function.
 //
-//  :help expression
-func (p *Pipeline) Eval(str string, result interface{}) {
-	p.call("vim_eval", result, str)
+//	:help nvim_win_get_number()
+func (v *Vim) WindowNumber(window Window) (int, error) {
+	var result int
+	err := v.call("nvim_win_get_number", &result, window)
+	return result, err
 }
 
-// Strwidth returns the number of display cells the string occupies. Tab is
-// counted as one cell.
-func (v *Vim) Strwidth(str string) (int, error) {
+// WindowNumberContext is like WindowNumber with a context for cancelling the call.
+func (v *Vim) WindowNumberContext(ctx context.Context, window Window) (int, error) {
 	var result int
-	err := v.call("vim_strwidth", &result, str)
+	err := v.callContext(ctx, "nvim_win_get_number", &result, window)
 	return result, err
 }
 
-// Strwidth returns the number of display cells the string occupies. Tab is
-// counted as one cell.
-func (p *Pipeline) Strwidth(str string, result *int) {
-	p.call("vim_strwidth", result, str)
+// WindowNumber calls the nvim_win_get_number API function.
+//
+//	:help nvim_win_get_number()
+func (p *Pipeline) WindowNumber(window Window, result *int) {
+	p.call("nvim_win_get_number", result, window)
 }
 
-// RuntimePaths returns a list of paths contained in the runtimepath option.
-func (v *Vim) RuntimePaths() ([]string, error) {
-	var result []string
-	err := v.call("vim_list_runtime_paths", &result)
+// IsWindowValid returns true if the window is valid.
+func (v *Vim) IsWindowValid(window Window) (bool, error) {
+	var result bool
+	err := v.call("nvim_win_is_valid", &result, window)
 	return result, err
 }
 
-// RuntimePaths returns a list of paths contained in the runtimepath option.
-func (p *Pipeline) RuntimePaths(result *[]string) {
-	p.call("vim_list_runtime_paths", result)
+// IsWindowValidContext is like IsWindowValid with a context for cancelling the call.
+func (v *Vim) IsWindowValidContext(ctx context.Context, window Window) (bool, error) {
+	var result bool
+	err := v.callContext(ctx, "nvim_win_is_valid", &result, window)
+	return result, err
 }
 
-// ChangeDirectory changes Vim working directory.
-func (v *Vim) ChangeDirectory(dir string) error {
-	return v.call("vim_change_directory", nil, dir)
+// IsWindowValid returns true if the window is valid.
+func (p *Pipeline) IsWindowValid(window Window, result *bool) {
+	p.call("nvim_win_is_valid", result, window)
 }
 
-// ChangeDirectory changes Vim working directory.
-func (p *Pipeline) ChangeDirectory(dir string) {
-	p.call("vim_change_directory", nil, dir)
+// HideWindow calls the nvim_win_hide API function.
+//
+//	:help nvim_win_hide()
+func (v *Vim) HideWindow(window Window) error {
+	return v.call("nvim_win_hide", nil, window)
 }
 
-// CurrentLine gets the current line in the current buffer.
-func (v *Vim) CurrentLine() ([]byte, error) {
-	var result []byte
-	err := v.call("vim_get_current_line", &result)
-	return result, err
+// HideWindowContext is like HideWindow with a context for cancelling the call.
+func (v *Vim) HideWindowContext(ctx context.Context, window Window) error {
+	return v.callContext(ctx, "nvim_win_hide", nil, window)
 }
 
-// CurrentLine gets the current line in the current buffer.
-func (p *Pipeline) CurrentLine(result *[]byte) {
-	p.call("vim_get_current_line", result)
+// HideWindow calls the nvim_win_hide API function.
+//
+//	:help nvim_win_hide()
+func (p *Pipeline) HideWindow(window Window) {
+	p.call("nvim_win_hide", nil, window)
 }
 
-// SetCurrentLine sets the current line in the current buffer.
-func (v *Vim) SetCurrentLine(line []byte) error {
-	return v.call("vim_set_current_line", nil, line)
+// CloseWindow calls the nvim_win_close API function.
+//
+//	:help nvim_win_close()
+func (v *Vim) CloseWindow(window Window, force bool) error {
+	return v.call("nvim_win_close", nil, window, force)
 }
 
-// SetCurrentLine sets the current line in the current buffer.
-func (p *Pipeline) SetCurrentLine(line []byte) {
-	p.call("vim_set_current_line", nil, line)
+// CloseWindowContext is like CloseWindow with a context for cancelling the call.
+func (v *Vim) CloseWindowContext(ctx context.Context, window Window, force bool) error {
+	return v.callContext(ctx, "nvim_win_close", nil, window, force)
 }
 
-// DeleteCurrentLine deletes the current line in the current buffer.
-func (v *Vim) DeleteCurrentLine() error {
-	return v.call("vim_del_current_line", nil)
+// CloseWindow calls the nvim_win_close API function.
+//
+//	:help nvim_win_close()
+func (p *Pipeline) CloseWindow(window Window, force bool) {
+	p.call("nvim_win_close", nil, window, force)
 }
 
-// DeleteCurrentLine deletes the current line in the current buffer.
-func (p *Pipeline) DeleteCurrentLine() {
-	p.call("vim_del_current_line", nil)
+// SetWindowHighlightNamespace calls the nvim_win_set_hl_ns API function.
+//
+//	:help nvim_win_set_hl_ns()
+func (v *Vim) SetWindowHighlightNamespace(window Window, nsID int) error {
+	return v.call("nvim_win_set_hl_ns", nil, window, nsID)
 }
 
-// Var gets a global (g:) variable.
-func (v *Vim) Var(name string, result interface{}) error {
-	return v.call("vim_get_var", result, name)
+// SetWindowHighlightNamespaceContext is like SetWindowHighlightNamespace with a context for cancelling the call.
+func (v *Vim) SetWindowHighlightNamespaceContext(ctx context.Context, window Window, nsID int) error {
+	return v.callContext(ctx, "nvim_win_set_hl_ns", nil, window, nsID)
 }
 
-// Var gets a global (g:) variable.
-func (p *Pipeline) Var(name string, result interface{}) {
-	p.call("vim_get_var", result, name)
+// SetWindowHighlightNamespace calls the nvim_win_set_hl_ns API function.
+//
+//	:help nvim_win_set_hl_ns()
+func (p *Pipeline) SetWindowHighlightNamespace(window Window, nsID int) {
+	p.call("nvim_win_set_hl_ns", nil, window, nsID)
+}
+
+// redrawEventTypes maps UI event names to functions that return a new event
+// of the corresponding Go type.
+var redrawEventTypes = map[string]func() RedrawEvent{
+	"mode_info_set":        func() RedrawEvent { return new(ModeInfoSetEvent) },
+	"update_menu":          func() RedrawEvent { return new(UpdateMenuEvent) },
+	"busy_start":           func() RedrawEvent { return new(BusyStartEvent) },
+	"busy_stop":            func() RedrawEvent { return new(BusyStopEvent) },
+	"mouse_on":             func() RedrawEvent { return new(MouseOnEvent) },
+	"mouse_off":            func() RedrawEvent { return new(MouseOffEvent) },
+	"mode_change":          func() RedrawEvent { return new(ModeChangeEvent) },
+	"bell":                 func() RedrawEvent { return new(BellEvent) },
+	"visual_bell":          func() RedrawEvent { return new(VisualBellEvent) },
+	"flush":                func() RedrawEvent { return new(FlushEvent) },
+	"suspend":              func() RedrawEvent { return new(SuspendEvent) },
+	"set_title":            func() RedrawEvent { return new(SetTitleEvent) },
+	"set_icon":             func() RedrawEvent { return new(SetIconEvent) },
+	"screenshot":           func() RedrawEvent { return new(ScreenshotEvent) },
+	"option_set":           func() RedrawEvent { return new(OptionSetEvent) },
+	"update_fg":            func() RedrawEvent { return new(UpdateFgEvent) },
+	"update_bg":            func() RedrawEvent { return new(UpdateBgEvent) },
+	"update_sp":            func() RedrawEvent { return new(UpdateSpEvent) },
+	"resize":               func() RedrawEvent { return new(ResizeEvent) },
+	"clear":                func() RedrawEvent { return new(ClearEvent) },
+	"eol_clear":            func() RedrawEvent { return new(EolClearEvent) },
+	"cursor_goto":          func() RedrawEvent { return new(CursorGotoEvent) },
+	"highlight_set":        func() RedrawEvent { return new(HighlightSetEvent) },
+	"put":                  func() RedrawEvent { return new(PutEvent) },
+	"set_scroll_region":    func() RedrawEvent { return new(SetScrollRegionEvent) },
+	"scroll":               func() RedrawEvent { return new(ScrollEvent) },
+	"default_colors_set":   func() RedrawEvent { return new(DefaultColorsSetEvent) },
+	"hl_attr_define":       func() RedrawEvent { return new(HighlightAttrDefineEvent) },
+	"hl_group_set":         func() RedrawEvent { return new(HighlightGroupSetEvent) },
+	"grid_resize":          func() RedrawEvent { return new(GridResizeEvent) },
+	"grid_clear":           func() RedrawEvent { return new(GridClearEvent) },
+	"grid_cursor_goto":     func() RedrawEvent { return new(GridCursorGotoEvent) },
+	"grid_line":            func() RedrawEvent { return new(GridLineEvent) },
+	"grid_scroll":          func() RedrawEvent { return new(GridScrollEvent) },
+	"grid_destroy":         func() RedrawEvent { return new(GridDestroyEvent) },
+	"win_pos":              func() RedrawEvent { return new(WindowPosEvent) },
+	"win_float_pos":        func() RedrawEvent { return new(WindowFloatPosEvent) },
+	"win_external_pos":     func() RedrawEvent { return new(WindowExternalPosEvent) },
+	"win_hide":             func() RedrawEvent { return new(WindowHideEvent) },
+	"win_close":            func() RedrawEvent { return new(WindowCloseEvent) },
+	"msg_set_pos":          func() RedrawEvent { return new(MsgSetPosEvent) },
+	"win_viewport":         func() RedrawEvent { return new(WindowViewportEvent) },
+	"win_extmark":          func() RedrawEvent { return new(WindowExtmarkEvent) },
+	"popupmenu_show":       func() RedrawEvent { return new(PopupmenuShowEvent) },
+	"popupmenu_hide":       func() RedrawEvent { return new(PopupmenuHideEvent) },
+	"popupmenu_select":     func() RedrawEvent { return new(PopupmenuSelectEvent) },
+	"tabline_update":       func() RedrawEvent { return new(TablineUpdateEvent) },
+	"cmdline_show":         func() RedrawEvent { return new(CmdlineShowEvent) },
+	"cmdline_pos":          func() RedrawEvent { return new(CmdlinePosEvent) },
+	"cmdline_special_char": func() RedrawEvent { return new(CmdlineSpecialCharEvent) },
+	"cmdline_hide":         func() RedrawEvent { return new(CmdlineHideEvent) },
+	"cmdline_block_show":   func() RedrawEvent { return new(CmdlineBlockShowEvent) },
+	"cmdline_block_append": func() RedrawEvent { return new(CmdlineBlockAppendEvent) },
+	"cmdline_block_hide":   func() RedrawEvent { return new(CmdlineBlockHideEvent) },
+	"wildmenu_show":        func() RedrawEvent { return new(WildmenuShowEvent) },
+	"wildmenu_select":      func() RedrawEvent { return new(WildmenuSelectEvent) },
+	"wildmenu_hide":        func() RedrawEvent { return new(WildmenuHideEvent) },
+	"msg_show":             func() RedrawEvent { return new(MsgShowEvent) },
+	"msg_clear":            func() RedrawEvent { return new(MsgClearEvent) },
+	"msg_showcmd":          func() RedrawEvent { return new(MsgShowcmdEvent) },
+	"msg_showmode":         func() RedrawEvent { return new(MsgShowmodeEvent) },
+	"msg_ruler":            func() RedrawEvent { return new(MsgRulerEvent) },
+	"msg_history_show":     func() RedrawEvent { return new(MsgHistoryShowEvent) },
+	"msg_history_clear":    func() RedrawEvent { return new(MsgHistoryClearEvent) },
+}
+
+// ModeInfoSetEvent is the mode_info_set UI event.
+//
+//	:help ui-event-mode_info_set
+type ModeInfoSetEvent struct {
+	Enabled      bool `msgpack:",array"`
+	CursorStyles []map[string]interface{}
 }
 
-// SetVar sets a global (g:) variable. The value nil deletes the variable.
-// Result is the previous value of the variable.
-func (v *Vim) SetVar(name string, value interface{}, result interface{}) error {
-	return v.call("vim_set_var", result, name, value)
-}
+func (*ModeInfoSetEvent) redrawEvent() {}
 
-// SetVar sets a global (g:) variable. The value nil deletes the variable.
-// Result is the previous value of the variable.
-func (p *Pipeline) SetVar(name string, value interface{}, result interface{}) {
-	p.call("vim_set_var", result, name, value)
-}
+// UpdateMenuEvent is the update_menu UI event.
+//
+//	:help ui-event-update_menu
+type UpdateMenuEvent struct{}
 
-// Vvar gets a vim (v:) variable.
-func (v *Vim) Vvar(name string, result interface{}) error {
-	return v.call("vim_get_vvar", result, name)
+func (*UpdateMenuEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// Vvar gets a vim (v:) variable.
-func (p *Pipeline) Vvar(name string, result interface{}) {
-	p.call("vim_get_vvar", result, name)
-}
+func (*UpdateMenuEvent) redrawEvent() {}
 
-// Option gets an option.
-func (v *Vim) Option(name string, result interface{}) error {
-	return v.call("vim_get_option", result, name)
-}
+// BusyStartEvent is the busy_start UI event.
+//
+//	:help ui-event-busy_start
+type BusyStartEvent struct{}
 
-// Option gets an option.
-func (p *Pipeline) Option(name string, result interface{}) {
-	p.call("vim_get_option", result, name)
+func (*BusyStartEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// SetOption sets an option.
-func (v *Vim) SetOption(name string, value interface{}) error {
-	return v.call("vim_set_option", nil, name, value)
+func (*BusyStartEvent) redrawEvent() {}
+
+// BusyStopEvent is the busy_stop UI event.
+//
+//	:help ui-event-busy_stop
+type BusyStopEvent struct{}
+
+func (*BusyStopEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// SetOption sets an option.
-func (p *Pipeline) SetOption(name string, value interface{}) {
-	p.call("vim_set_option", nil, name, value)
+func (*BusyStopEvent) redrawEvent() {}
+
+// MouseOnEvent is the mouse_on UI event.
+//
+//	:help ui-event-mouse_on
+type MouseOnEvent struct{}
+
+func (*MouseOnEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// WriteOut writes a message to vim output buffer. The string is split and
-// flushed after each newline. Incomplete lines are kept for writing later.
-func (v *Vim) WriteOut(str string) error {
-	return v.call("vim_out_write", nil, str)
+func (*MouseOnEvent) redrawEvent() {}
+
+// MouseOffEvent is the mouse_off UI event.
+//
+//	:help ui-event-mouse_off
+type MouseOffEvent struct{}
+
+func (*MouseOffEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// WriteOut writes a message to vim output buffer. The string is split and
-// flushed after each newline. Incomplete lines are kept for writing later.
-func (p *Pipeline) WriteOut(str string) {
-	p.call("vim_out_write", nil, str)
+func (*MouseOffEvent) redrawEvent() {}
+
+// ModeChangeEvent is the mode_change UI event.
+//
+//	:help ui-event-mode_change
+type ModeChangeEvent struct {
+	Mode    string `msgpack:",array"`
+	ModeIdx int
 }
 
-// WriteErr writes a message to vim error buffer. The string is split and
-// flushed after each newline. Incomplete lines are kept for writing later.
-func (v *Vim) WriteErr(str string) error {
-	return v.call("vim_err_write", nil, str)
+func (*ModeChangeEvent) redrawEvent() {}
+
+// BellEvent is the bell UI event.
+//
+//	:help ui-event-bell
+type BellEvent struct{}
+
+func (*BellEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// WriteErr writes a message to vim error buffer. The string is split and
-// flushed after each newline. Incomplete lines are kept for writing later.
-func (p *Pipeline) WriteErr(str string) {
-	p.call("vim_err_write", nil, str)
+func (*BellEvent) redrawEvent() {}
+
+// VisualBellEvent is the visual_bell UI event.
+//
+//	:help ui-event-visual_bell
+type VisualBellEvent struct{}
+
+func (*VisualBellEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// ReportError writes prints str and a newline as an error message.
-func (v *Vim) ReportError(str string) error {
-	return v.call("vim_report_error", nil, str)
+func (*VisualBellEvent) redrawEvent() {}
+
+// FlushEvent is the flush UI event.
+//
+//	:help ui-event-flush
+type FlushEvent struct{}
+
+func (*FlushEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// ReportError writes prints str and a newline as an error message.
-func (p *Pipeline) ReportError(str string) {
-	p.call("vim_report_error", nil, str)
-}
+func (*FlushEvent) redrawEvent() {}
 
-// Buffers returns the current list of buffers.
-func (v *Vim) Buffers() ([]Buffer, error) {
-	var result []Buffer
-	err := v.call("vim_get_buffers", &result)
-	return result, err
-}
+// SuspendEvent is the suspend UI event.
+//
+//	:help ui-event-suspend
+type SuspendEvent struct{}
 
-// Buffers returns the current list of buffers.
-func (p *Pipeline) Buffers(result *[]Buffer) {
-	p.call("vim_get_buffers", result)
+func (*SuspendEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// CurrentBuffer returns the current buffer.
-func (v *Vim) CurrentBuffer() (Buffer, error) {
-	var result Buffer
-	err := v.call("vim_get_current_buffer", &result)
-	return result, err
-}
+func (*SuspendEvent) redrawEvent() {}
 
-// CurrentBuffer returns the current buffer.
-func (p *Pipeline) CurrentBuffer(result *Buffer) {
-	p.call("vim_get_current_buffer", result)
+// SetTitleEvent is the set_title UI event.
+//
+//	:help ui-event-set_title
+type SetTitleEvent struct {
+	Title string `msgpack:",array"`
 }
 
-// SetCurrentBuffer sets the current buffer.
-func (v *Vim) SetCurrentBuffer(buffer Buffer) error {
-	return v.call("vim_set_current_buffer", nil, buffer)
-}
+func (*SetTitleEvent) redrawEvent() {}
 
-// SetCurrentBuffer sets the current buffer.
-func (p *Pipeline) SetCurrentBuffer(buffer Buffer) {
-	p.call("vim_set_current_buffer", nil, buffer)
+// SetIconEvent is the set_icon UI event.
+//
+//	:help ui-event-set_icon
+type SetIconEvent struct {
+	Icon string `msgpack:",array"`
 }
 
-// Windows returns the current list of windows.
-func (v *Vim) Windows() ([]Window, error) {
-	var result []Window
-	err := v.call("vim_get_windows", &result)
-	return result, err
+func (*SetIconEvent) redrawEvent() {}
+
+// ScreenshotEvent is the screenshot UI event.
+//
+//	:help ui-event-screenshot
+type ScreenshotEvent struct {
+	Path string `msgpack:",array"`
 }
 
-// Windows returns the current list of windows.
-func (p *Pipeline) Windows(result *[]Window) {
-	p.call("vim_get_windows", result)
+func (*ScreenshotEvent) redrawEvent() {}
+
+// OptionSetEvent is the option_set UI event.
+//
+//	:help ui-event-option_set
+type OptionSetEvent struct {
+	Name  string `msgpack:",array"`
+	Value interface{}
 }
 
-// CurrentWindow returns the current window.
-func (v *Vim) CurrentWindow() (Window, error) {
-	var result Window
-	err := v.call("vim_get_current_window", &result)
-	return result, err
+func (*OptionSetEvent) redrawEvent() {}
+
+// UpdateFgEvent is the update_fg UI event.
+//
+//	:help ui-event-update_fg
+type UpdateFgEvent struct {
+	Fg int `msgpack:",array"`
 }
 
-// CurrentWindow returns the current window.
-func (p *Pipeline) CurrentWindow(result *Window) {
-	p.call("vim_get_current_window", result)
+func (*UpdateFgEvent) redrawEvent() {}
+
+// UpdateBgEvent is the update_bg UI event.
+//
+//	:help ui-event-update_bg
+type UpdateBgEvent struct {
+	Bg int `msgpack:",array"`
 }
 
-// SetCurrentWindow sets the current window.
-func (v *Vim) SetCurrentWindow(window Window) error {
-	return v.call("vim_set_current_window", nil, window)
+func (*UpdateBgEvent) redrawEvent() {}
+
+// UpdateSpEvent is the update_sp UI event.
+//
+//	:help ui-event-update_sp
+type UpdateSpEvent struct {
+	Sp int `msgpack:",array"`
 }
 
-// SetCurrentWindow sets the current window.
-func (p *Pipeline) SetCurrentWindow(window Window) {
-	p.call("vim_set_current_window", nil, window)
+func (*UpdateSpEvent) redrawEvent() {}
+
+// ResizeEvent is the resize UI event.
+//
+//	:help ui-event-resize
+type ResizeEvent struct {
+	Width  int `msgpack:",array"`
+	Height int
 }
 
-// Tabpages returns the current list of tabpages.
-func (v *Vim) Tabpages() ([]Tabpage, error) {
-	var result []Tabpage
-	err := v.call("vim_get_tabpages", &result)
-	return result, err
+func (*ResizeEvent) redrawEvent() {}
+
+// ClearEvent is the clear UI event.
+//
+//	:help ui-event-clear
+type ClearEvent struct{}
+
+func (*ClearEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// Tabpages returns the current list of tabpages.
-func (p *Pipeline) Tabpages(result *[]Tabpage) {
-	p.call("vim_get_tabpages", result)
+func (*ClearEvent) redrawEvent() {}
+
+// EolClearEvent is the eol_clear UI event.
+//
+//	:help ui-event-eol_clear
+type EolClearEvent struct{}
+
+func (*EolClearEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// CurrentTabpage returns the current tabpage.
-func (v *Vim) CurrentTabpage() (Tabpage, error) {
-	var result Tabpage
-	err := v.call("vim_get_current_tabpage", &result)
-	return result, err
+func (*EolClearEvent) redrawEvent() {}
+
+// CursorGotoEvent is the cursor_goto UI event.
+//
+//	:help ui-event-cursor_goto
+type CursorGotoEvent struct {
+	Row int `msgpack:",array"`
+	Col int
 }
 
-// CurrentTabpage returns the current tabpage.
-func (p *Pipeline) CurrentTabpage(result *Tabpage) {
-	p.call("vim_get_current_tabpage", result)
+func (*CursorGotoEvent) redrawEvent() {}
+
+// HighlightSetEvent is the highlight_set UI event.
+//
+//	:help ui-event-highlight_set
+type HighlightSetEvent struct {
+	Attrs HighlightAttrs `msgpack:",array"`
 }
 
-// SetCurrentTabpage sets the current tabpage.
-func (v *Vim) SetCurrentTabpage(tabpage Tabpage) error {
-	return v.call("vim_set_current_tabpage", nil, tabpage)
+func (*HighlightSetEvent) redrawEvent() {}
+
+// PutEvent is the put UI event.
+//
+//	:help ui-event-put
+type PutEvent struct {
+	Str string `msgpack:",array"`
 }
 
-// SetCurrentTabpage sets the current tabpage.
-func (p *Pipeline) SetCurrentTabpage(tabpage Tabpage) {
-	p.call("vim_set_current_tabpage", nil, tabpage)
+func (*PutEvent) redrawEvent() {}
+
+// SetScrollRegionEvent is the set_scroll_region UI event.
+//
+//	:help ui-event-set_scroll_region
+type SetScrollRegionEvent struct {
+	Top   int `msgpack:",array"`
+	Bot   int
+	Left  int
+	Right int
 }
 
-// Subscribe subscribes to a Neovim event.
-func (v *Vim) Subscribe(event string) error {
-	return v.call("vim_subscribe", nil, event)
+func (*SetScrollRegionEvent) redrawEvent() {}
+
+// ScrollEvent is the scroll UI event.
+//
+//	:help ui-event-scroll
+type ScrollEvent struct {
+	Count int `msgpack:",array"`
 }
 
-// Subscribe subscribes to a Neovim event.
-func (p *Pipeline) Subscribe(event string) {
-	p.call("vim_subscribe", nil, event)
+func (*ScrollEvent) redrawEvent() {}
+
+// DefaultColorsSetEvent is the default_colors_set UI event.
+//
+//	:help ui-event-default_colors_set
+type DefaultColorsSetEvent struct {
+	RGBFg   int `msgpack:",array"`
+	RGBBg   int
+	RGBSp   int
+	CtermFg int
+	CtermBg int
 }
 
-// Unsubscribe unsubscribes to a Neovim event.
-func (v *Vim) Unsubscribe(event string) error {
-	return v.call("vim_unsubscribe", nil, event)
+func (*DefaultColorsSetEvent) redrawEvent() {}
+
+// HighlightAttrDefineEvent is the hl_attr_define UI event.
+//
+//	:help ui-event-hl_attr_define
+type HighlightAttrDefineEvent struct {
+	ID         int `msgpack:",array"`
+	RGBAttrs   HighlightAttrs
+	CtermAttrs HighlightAttrs
+	Info       []map[string]interface{}
 }
 
-// Unsubscribe unsubscribes to a Neovim event.
-func (p *Pipeline) Unsubscribe(event string) {
-	p.call("vim_unsubscribe", nil, event)
+func (*HighlightAttrDefineEvent) redrawEvent() {}
+
+// HighlightGroupSetEvent is the hl_group_set UI event.
+//
+//	:help ui-event-hl_group_set
+type HighlightGroupSetEvent struct {
+	Name string `msgpack:",array"`
+	ID   int
 }
 
-func (v *Vim) NameToColor(name string) (int, error) {
-	var result int
-	err := v.call("vim_name_to_color", &result, name)
-	return result, err
+func (*HighlightGroupSetEvent) redrawEvent() {}
+
+// GridResizeEvent is the grid_resize UI event.
+//
+//	:help ui-event-grid_resize
+type GridResizeEvent struct {
+	Grid   int `msgpack:",array"`
+	Width  int
+	Height int
 }
 
-func (p *Pipeline) NameToColor(name string, result *int) {
-	p.call("vim_name_to_color", result, name)
+func (*GridResizeEvent) redrawEvent() {}
+
+// GridClearEvent is the grid_clear UI event.
+//
+//	:help ui-event-grid_clear
+type GridClearEvent struct {
+	Grid int `msgpack:",array"`
 }
 
-func (v *Vim) ColorMap() (map[string]interface{}, error) {
-	var result map[string]interface{}
-	err := v.call("vim_get_color_map", &result)
-	return result, err
+func (*GridClearEvent) redrawEvent() {}
+
+// GridCursorGotoEvent is the grid_cursor_goto UI event.
+//
+//	:help ui-event-grid_cursor_goto
+type GridCursorGotoEvent struct {
+	Grid int `msgpack:",array"`
+	Row  int
+	Col  int
 }
 
-func (p *Pipeline) ColorMap(result *map[string]interface{}) {
-	p.call("vim_get_color_map", result)
+func (*GridCursorGotoEvent) redrawEvent() {}
+
+func (*GridLineEvent) redrawEvent() {}
+
+// GridScrollEvent is the grid_scroll UI event.
+//
+//	:help ui-event-grid_scroll
+type GridScrollEvent struct {
+	Grid  int `msgpack:",array"`
+	Top   int
+	Bot   int
+	Left  int
+	Right int
+	Rows  int
+	Cols  int
 }
 
-func (v *Vim) APIInfo() ([]interface{}, error) {
-	var result []interface{}
-	err := v.call("vim_get_api_info", &result)
-	return result, err
+func (*GridScrollEvent) redrawEvent() {}
+
+// GridDestroyEvent is the grid_destroy UI event.
+//
+//	:help ui-event-grid_destroy
+type GridDestroyEvent struct {
+	Grid int `msgpack:",array"`
 }
 
-func (p *Pipeline) APIInfo(result *[]interface{}) {
-	p.call("vim_get_api_info", result)
+func (*GridDestroyEvent) redrawEvent() {}
+
+// WindowPosEvent is the win_pos UI event.
+//
+//	:help ui-event-win_pos
+type WindowPosEvent struct {
+	Grid     int `msgpack:",array"`
+	Window   Window
+	Startrow int
+	Startcol int
+	Width    int
+	Height   int
 }
 
-// WindowBuffer returns the current buffer in a window.
-func (v *Vim) WindowBuffer(window Window) (Buffer, error) {
-	var result Buffer
-	err := v.call("window_get_buffer", &result, window)
-	return result, err
+func (*WindowPosEvent) redrawEvent() {}
+
+// WindowFloatPosEvent is the win_float_pos UI event.
+//
+//	:help ui-event-win_float_pos
+type WindowFloatPosEvent struct {
+	Grid       int `msgpack:",array"`
+	Window     Window
+	Anchor     string
+	AnchorGrid int
+	AnchorRow  float64
+	AnchorCol  float64
+	Focusable  bool
+	Zindex     int
 }
 
-// WindowBuffer returns the current buffer in a window.
-func (p *Pipeline) WindowBuffer(window Window, result *Buffer) {
-	p.call("window_get_buffer", result, window)
+func (*WindowFloatPosEvent) redrawEvent() {}
+
+// WindowExternalPosEvent is the win_external_pos UI event.
+//
+//	:help ui-event-win_external_pos
+type WindowExternalPosEvent struct {
+	Grid   int `msgpack:",array"`
+	Window Window
 }
 
-// WindowCursor returns the cursor position in the window.
-func (v *Vim) WindowCursor(window Window) ([2]int, error) {
-	var result [2]int
-	err := v.call("window_get_cursor", &result, window)
-	return result, err
+func (*WindowExternalPosEvent) redrawEvent() {}
+
+// WindowHideEvent is the win_hide UI event.
+//
+//	:help ui-event-win_hide
+type WindowHideEvent struct {
+	Grid int `msgpack:",array"`
 }
 
-// WindowCursor returns the cursor position in the window.
-func (p *Pipeline) WindowCursor(window Window, result *[2]int) {
-	p.call("window_get_cursor", result, window)
+func (*WindowHideEvent) redrawEvent() {}
+
+// WindowCloseEvent is the win_close UI event.
+//
+//	:help ui-event-win_close
+type WindowCloseEvent struct {
+	Grid int `msgpack:",array"`
 }
 
-// SetWindowCursor sets the cursor position in the window to the given position.
-func (v *Vim) SetWindowCursor(window Window, pos [2]int) error {
-	return v.call("window_set_cursor", nil, window, pos)
+func (*WindowCloseEvent) redrawEvent() {}
+
+// MsgSetPosEvent is the msg_set_pos UI event.
+//
+//	:help ui-event-msg_set_pos
+type MsgSetPosEvent struct {
+	Grid     int `msgpack:",array"`
+	Row      int
+	Scrolled bool
+	SepChar  string
 }
 
-// SetWindowCursor sets the cursor position in the window to the given position.
-func (p *Pipeline) SetWindowCursor(window Window, pos [2]int) {
-	p.call("window_set_cursor", nil, window, pos)
+func (*MsgSetPosEvent) redrawEvent() {}
+
+// WindowViewportEvent is the win_viewport UI event.
+//
+//	:help ui-event-win_viewport
+type WindowViewportEvent struct {
+	Grid      int `msgpack:",array"`
+	Window    Window
+	Topline   int
+	Botline   int
+	Curline   int
+	Curcol    int
+	LineCount int
 }
 
-// WindowHeight returns the window height.
-func (v *Vim) WindowHeight(window Window) (int, error) {
-	var result int
-	err := v.call("window_get_height", &result, window)
-	return result, err
+func (*WindowViewportEvent) redrawEvent() {}
+
+// WindowExtmarkEvent is the win_extmark UI event.
+//
+//	:help ui-event-win_extmark
+type WindowExtmarkEvent struct {
+	Grid        int `msgpack:",array"`
+	Window      Window
+	NamespaceID int
+	MarkID      int
+	Row         int
+	Col         int
 }
 
-// WindowHeight returns the window height.
-func (p *Pipeline) WindowHeight(window Window, result *int) {
-	p.call("window_get_height", result, window)
+func (*WindowExtmarkEvent) redrawEvent() {}
+
+// PopupmenuShowEvent is the popupmenu_show UI event.
+//
+//	:help ui-event-popupmenu_show
+type PopupmenuShowEvent struct {
+	Items    []PopupmenuItem `msgpack:",array"`
+	Selected int
+	Row      int
+	Col      int
+	Grid     int
 }
 
-// SetWindowHeight sets the window height.
-func (v *Vim) SetWindowHeight(window Window, height int) error {
-	return v.call("window_set_height", nil, window, height)
+func (*PopupmenuShowEvent) redrawEvent() {}
+
+// PopupmenuHideEvent is the popupmenu_hide UI event.
+//
+//	:help ui-event-popupmenu_hide
+type PopupmenuHideEvent struct{}
+
+func (*PopupmenuHideEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// SetWindowHeight sets the window height.
-func (p *Pipeline) SetWindowHeight(window Window, height int) {
-	p.call("window_set_height", nil, window, height)
+func (*PopupmenuHideEvent) redrawEvent() {}
+
+// PopupmenuSelectEvent is the popupmenu_select UI event.
+//
+//	:help ui-event-popupmenu_select
+type PopupmenuSelectEvent struct {
+	Selected int `msgpack:",array"`
 }
 
-// WindowWidth returns the window width.
-func (v *Vim) WindowWidth(window Window) (int, error) {
-	var result int
-	err := v.call("window_get_width", &result, window)
-	return result, err
+func (*PopupmenuSelectEvent) redrawEvent() {}
+
+// TablineUpdateEvent is the tabline_update UI event.
+//
+//	:help ui-event-tabline_update
+type TablineUpdateEvent struct {
+	Current       Tabpage `msgpack:",array"`
+	Tabs          []map[string]interface{}
+	CurrentBuffer Buffer
+	Buffers       []map[string]interface{}
 }
 
-// WindowWidth returns the window width.
-func (p *Pipeline) WindowWidth(window Window, result *int) {
-	p.call("window_get_width", result, window)
+func (*TablineUpdateEvent) redrawEvent() {}
+
+// CmdlineShowEvent is the cmdline_show UI event.
+//
+//	:help ui-event-cmdline_show
+type CmdlineShowEvent struct {
+	Content []TextChunk `msgpack:",array"`
+	Pos     int
+	Firstc  string
+	Prompt  string
+	Indent  int
+	Level   int
 }
 
-// SetWindowWidth sets the window width.
-func (v *Vim) SetWindowWidth(window Window, width int) error {
-	return v.call("window_set_width", nil, window, width)
+func (*CmdlineShowEvent) redrawEvent() {}
+
+// CmdlinePosEvent is the cmdline_pos UI event.
+//
+//	:help ui-event-cmdline_pos
+type CmdlinePosEvent struct {
+	Pos   int `msgpack:",array"`
+	Level int
 }
 
-// SetWindowWidth sets the window width.
-func (p *Pipeline) SetWindowWidth(window Window, width int) {
-	p.call("window_set_width", nil, window, width)
+func (*CmdlinePosEvent) redrawEvent() {}
+
+// CmdlineSpecialCharEvent is the cmdline_special_char UI event.
+//
+//	:help ui-event-cmdline_special_char
+type CmdlineSpecialCharEvent struct {
+	C     string `msgpack:",array"`
+	Shift bool
+	Level int
 }
 
-// WindowVar gets a window-scoped (w:) variable.
-func (v *Vim) WindowVar(window Window, name string, result interface{}) error {
-	return v.call("window_get_var", result, window, name)
+func (*CmdlineSpecialCharEvent) redrawEvent() {}
+
+// CmdlineHideEvent is the cmdline_hide UI event.
+//
+//	:help ui-event-cmdline_hide
+type CmdlineHideEvent struct {
+	Level int `msgpack:",array"`
 }
 
-// WindowVar gets a window-scoped (w:) variable.
-func (p *Pipeline) WindowVar(window Window, name string, result interface{}) {
-	p.call("window_get_var", result, window, name)
+func (*CmdlineHideEvent) redrawEvent() {}
+
+// CmdlineBlockShowEvent is the cmdline_block_show UI event.
+//
+//	:help ui-event-cmdline_block_show
+type CmdlineBlockShowEvent struct {
+	Lines [][]TextChunk `msgpack:",array"`
 }
 
-// SetWindowVar sets a window-scoped (w:) variable.
-func (v *Vim) SetWindowVar(window Window, name string, value interface{}, result interface{}) error {
-	return v.call("window_set_var", result, window, name, value)
+func (*CmdlineBlockShowEvent) redrawEvent() {}
+
+// CmdlineBlockAppendEvent is the cmdline_block_append UI event.
+//
+//	:help ui-event-cmdline_block_append
+type CmdlineBlockAppendEvent struct {
+	Lines []TextChunk `msgpack:",array"`
 }
 
-// SetWindowVar sets a window-scoped (w:) variable.
-func (p *Pipeline) SetWindowVar(window Window, name string, value interface{}, result interface{}) {
-	p.call("window_set_var", result, window, name, value)
+func (*CmdlineBlockAppendEvent) redrawEvent() {}
+
+// CmdlineBlockHideEvent is the cmdline_block_hide UI event.
+//
+//	:help ui-event-cmdline_block_hide
+type CmdlineBlockHideEvent struct{}
+
+func (*CmdlineBlockHideEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// WindowOption gets a window option.
-func (v *Vim) WindowOption(window Window, name string, result interface{}) error {
-	return v.call("window_get_option", result, window, name)
+func (*CmdlineBlockHideEvent) redrawEvent() {}
+
+// WildmenuShowEvent is the wildmenu_show UI event.
+//
+//	:help ui-event-wildmenu_show
+type WildmenuShowEvent struct {
+	Items []string `msgpack:",array"`
 }
 
-// WindowOption gets a window option.
-func (p *Pipeline) WindowOption(window Window, name string, result interface{}) {
-	p.call("window_get_option", result, window, name)
+func (*WildmenuShowEvent) redrawEvent() {}
+
+// WildmenuSelectEvent is the wildmenu_select UI event.
+//
+//	:help ui-event-wildmenu_select
+type WildmenuSelectEvent struct {
+	Selected int `msgpack:",array"`
 }
 
-// SetWindowOption sets a window option.
-func (v *Vim) SetWindowOption(window Window, name string, value interface{}) error {
-	return v.call("window_set_option", nil, window, name, value)
+func (*WildmenuSelectEvent) redrawEvent() {}
+
+// WildmenuHideEvent is the wildmenu_hide UI event.
+//
+//	:help ui-event-wildmenu_hide
+type WildmenuHideEvent struct{}
+
+func (*WildmenuHideEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// SetWindowOption sets a window option.
-func (p *Pipeline) SetWindowOption(window Window, name string, value interface{}) {
-	p.call("window_set_option", nil, window, name, value)
+func (*WildmenuHideEvent) redrawEvent() {}
+
+// MsgShowEvent is the msg_show UI event.
+//
+//	:help ui-event-msg_show
+type MsgShowEvent struct {
+	Kind        string `msgpack:",array"`
+	Content     []TextChunk
+	ReplaceLast bool
 }
 
-// WindowPosition gets the window position in display cells. First position is zero.
-func (v *Vim) WindowPosition(window Window) ([2]int, error) {
-	var result [2]int
-	err := v.call("window_get_position", &result, window)
-	return result, err
+func (*MsgShowEvent) redrawEvent() {}
+
+// MsgClearEvent is the msg_clear UI event.
+//
+//	:help ui-event-msg_clear
+type MsgClearEvent struct{}
+
+func (*MsgClearEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
 
-// WindowPosition gets the window position in display cells. First position is zero.
-func (p *Pipeline) WindowPosition(window Window, result *[2]int) {
-	p.call("window_get_position", result, window)
+func (*MsgClearEvent) redrawEvent() {}
+
+// MsgShowcmdEvent is the msg_showcmd UI event.
+//
+//	:help ui-event-msg_showcmd
+type MsgShowcmdEvent struct {
+	Content []TextChunk `msgpack:",array"`
 }
 
-// WindowTabpage gets the tab page that contains the window.
-func (v *Vim) WindowTabpage(window Window) (Tabpage, error) {
-	var result Tabpage
-	err := v.call("window_get_tabpage", &result, window)
-	return result, err
+func (*MsgShowcmdEvent) redrawEvent() {}
+
+// MsgShowmodeEvent is the msg_showmode UI event.
+//
+//	:help ui-event-msg_showmode
+type MsgShowmodeEvent struct {
+	Content []TextChunk `msgpack:",array"`
 }
 
-// WindowTabpage gets the tab page that contains the window.
-func (p *Pipeline) WindowTabpage(window Window, result *Tabpage) {
-	p.call("window_get_tabpage", result, window)
+func (*MsgShowmodeEvent) redrawEvent() {}
+
+// MsgRulerEvent is the msg_ruler UI event.
+//
+//	:help ui-event-msg_ruler
+type MsgRulerEvent struct {
+	Content []TextChunk `msgpack:",array"`
 }
 
-// IsWindowValid returns true if the window is valid.
-func (v *Vim) IsWindowValid(window Window) (bool, error) {
-	var result bool
-	err := v.call("window_is_valid", &result, window)
-	return result, err
+func (*MsgRulerEvent) redrawEvent() {}
+
+// MsgHistoryShowEvent is the msg_history_show UI event.
+//
+//	:help ui-event-msg_history_show
+type MsgHistoryShowEvent struct {
+	Entries []interface{} `msgpack:",array"`
 }
 
-// IsWindowValid returns true if the window is valid.
-func (p *Pipeline) IsWindowValid(window Window, result *bool) {
-	p.call("window_is_valid", result, window)
+func (*MsgHistoryShowEvent) redrawEvent() {}
+
+// MsgHistoryClearEvent is the msg_history_clear UI event.
+//
+//	:help ui-event-msg_history_clear
+type MsgHistoryClearEvent struct{}
+
+func (*MsgHistoryClearEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return dec.Skip()
 }
+
+func (*MsgHistoryClearEvent) redrawEvent() {}
@@ -0,0 +1,341 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// This program compares two snapshots of Neovim's API info.
+//
+// Usage:
+//
+//  go run apidiff.go [-json] [-pkg dir] old.json new.json
+//
+// The program reports the functions and UI events that are added, removed,
+// deprecated or changed in the new snapshot. A function or UI event is
+// changed when the types of its parameters or its return type change. The
+// program also reports the methods in the package directory that call a
+// removed or changed function. The methods include the generated methods in
+// api.go and the methods written by hand in the other files of the package.
+//
+// Use "-" as a file name to read a snapshot from stdin. To compare the
+// checked in snapshot with the installed version of Neovim, run:
+//
+//  go run dumpapi.go | go run apidiff.go api.json -
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type apiFunction struct {
+	Name            string      `json:"name"`
+	Parameters      [][2]string `json:"parameters"`
+	ReturnType      string      `json:"return_type"`
+	Since           int         `json:"since"`
+	DeprecatedSince int         `json:"deprecated_since"`
+}
+
+type apiInfo struct {
+	Version struct {
+		APILevel int `json:"api_level"`
+	} `json:"version"`
+	Functions []*apiFunction `json:"functions"`
+	UIEvents  []*apiFunction `json:"ui_events"`
+}
+
+func readAPIInfo(fname string) (*apiInfo, error) {
+	var (
+		p   []byte
+		err error
+	)
+	if fname == "-" {
+		p, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		p, err = ioutil.ReadFile(fname)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var info apiInfo
+	if err := json.Unmarshal(p, &info); err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	return &info, nil
+}
+
+// signature returns a readable signature for the function or UI event.
+func signature(f *apiFunction) string {
+	params := make([]string, len(f.Parameters))
+	for i, p := range f.Parameters {
+		params[i] = p[0] + " " + p[1]
+	}
+	s := f.Name + "(" + strings.Join(params, ", ") + ")"
+	if f.ReturnType != "" && f.ReturnType != "void" {
+		s += " " + f.ReturnType
+	}
+	return s
+}
+
+// compatible returns true if calls to old work with new. Parameter names do
+// not matter to callers.
+func compatible(old, new *apiFunction) bool {
+	if len(old.Parameters) != len(new.Parameters) || old.ReturnType != new.ReturnType {
+		return false
+	}
+	for i := range old.Parameters {
+		if old.Parameters[i][0] != new.Parameters[i][0] {
+			return false
+		}
+	}
+	return true
+}
+
+type change struct {
+	Name string `json:"name"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+
+	// DeprecatedSince is set for deprecated functions.
+	DeprecatedSince int `json:"deprecated_since,omitempty"`
+}
+
+type changes struct {
+	Added      []*change `json:"added"`
+	Removed    []*change `json:"removed"`
+	Deprecated []*change `json:"deprecated"`
+	Changed    []*change `json:"changed"`
+}
+
+func (c *changes) empty() bool {
+	return len(c.Added)+len(c.Removed)+len(c.Deprecated)+len(c.Changed) == 0
+}
+
+func diff(old, new []*apiFunction) *changes {
+	oldByName := make(map[string]*apiFunction)
+	for _, f := range old {
+		oldByName[f.Name] = f
+	}
+	newByName := make(map[string]*apiFunction)
+	for _, f := range new {
+		newByName[f.Name] = f
+	}
+
+	c := &changes{
+		Added:      []*change{},
+		Removed:    []*change{},
+		Deprecated: []*change{},
+		Changed:    []*change{},
+	}
+	for _, f := range new {
+		o := oldByName[f.Name]
+		switch {
+		case o == nil:
+			c.Added = append(c.Added, &change{Name: f.Name, New: signature(f)})
+		case !compatible(o, f):
+			c.Changed = append(c.Changed, &change{Name: f.Name, Old: signature(o), New: signature(f)})
+		}
+		if f.DeprecatedSince != 0 && (o == nil || o.DeprecatedSince == 0) {
+			c.Deprecated = append(c.Deprecated, &change{Name: f.Name, DeprecatedSince: f.DeprecatedSince})
+		}
+	}
+	for _, f := range old {
+		if newByName[f.Name] == nil {
+			c.Removed = append(c.Removed, &change{Name: f.Name, Old: signature(f)})
+		}
+	}
+	for _, l := range [][]*change{c.Added, c.Removed, c.Deprecated, c.Changed} {
+		sort.Slice(l, func(i, j int) bool { return l[i].Name < l[j].Name })
+	}
+	return c
+}
+
+// methodCalls returns a map from API function names to the names of the
+// methods in the package in dir that call the function. Test files and files
+// excluded by build constraints are ignored.
+func methodCalls(dir string) (map[string][]string, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	calls := make(map[string][]string)
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		fileMethodCalls(file, calls)
+	}
+	for _, methods := range calls {
+		sort.Strings(methods)
+	}
+	return calls, nil
+}
+
+// fileMethodCalls adds the calls from the methods in file to calls.
+func fileMethodCalls(file *ast.File, calls map[string][]string) {
+	for _, decl := range file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv == nil || fd.Body == nil {
+			continue
+		}
+		// The Pipeline methods have the same names as the Vim methods.
+		if star, ok := fd.Recv.List[0].Type.(*ast.StarExpr); !ok || fmt.Sprint(star.X) != "Vim" {
+			continue
+		}
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || (sel.Sel.Name != "call" && sel.Sel.Name != "callContext") {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			if sm, err := strconv.Unquote(lit.Value); err == nil {
+				calls[sm] = append(calls[sm], fd.Name.Name)
+			}
+			return true
+		})
+	}
+}
+
+type brokenMethod struct {
+	Method   string `json:"method"`
+	Function string `json:"function"`
+	Reason   string `json:"reason"`
+}
+
+type report struct {
+	OldAPILevel   int             `json:"old_api_level"`
+	NewAPILevel   int             `json:"new_api_level"`
+	Functions     *changes        `json:"functions"`
+	UIEvents      *changes        `json:"ui_events"`
+	BrokenMethods []*brokenMethod `json:"broken_methods"`
+}
+
+func newReport(old, new *apiInfo, calls map[string][]string) *report {
+	r := &report{
+		OldAPILevel:   old.Version.APILevel,
+		NewAPILevel:   new.Version.APILevel,
+		Functions:     diff(old.Functions, new.Functions),
+		UIEvents:      diff(old.UIEvents, new.UIEvents),
+		BrokenMethods: []*brokenMethod{},
+	}
+	for _, x := range []struct {
+		changes []*change
+		reason  string
+	}{
+		{r.Functions.Removed, "removed"},
+		{r.Functions.Changed, "signature changed"},
+	} {
+		for _, c := range x.changes {
+			for _, m := range calls[c.Name] {
+				r.BrokenMethods = append(r.BrokenMethods, &brokenMethod{Method: m, Function: c.Name, Reason: x.reason})
+			}
+		}
+	}
+	sort.Slice(r.BrokenMethods, func(i, j int) bool { return r.BrokenMethods[i].Method < r.BrokenMethods[j].Method })
+	return r
+}
+
+func writeChanges(w io.Writer, what string, c *changes) {
+	if c.empty() {
+		fmt.Fprintf(w, "\nNo changes to %s.\n", what)
+		return
+	}
+	if len(c.Added) > 0 {
+		fmt.Fprintf(w, "\nAdded %s:\n", what)
+		for _, c := range c.Added {
+			fmt.Fprintf(w, "  %s\n", c.New)
+		}
+	}
+	if len(c.Removed) > 0 {
+		fmt.Fprintf(w, "\nRemoved %s:\n", what)
+		for _, c := range c.Removed {
+			fmt.Fprintf(w, "  %s\n", c.Old)
+		}
+	}
+	if len(c.Deprecated) > 0 {
+		fmt.Fprintf(w, "\nDeprecated %s:\n", what)
+		for _, c := range c.Deprecated {
+			fmt.Fprintf(w, "  %s (since API level %d)\n", c.Name, c.DeprecatedSince)
+		}
+	}
+	if len(c.Changed) > 0 {
+		fmt.Fprintf(w, "\nChanged %s:\n", what)
+		for _, c := range c.Changed {
+			fmt.Fprintf(w, "  %s\n    old: %s\n    new: %s\n", c.Name, c.Old, c.New)
+		}
+	}
+}
+
+func (r *report) writeText(w io.Writer) {
+	fmt.Fprintf(w, "API level %d -> %d\n", r.OldAPILevel, r.NewAPILevel)
+	writeChanges(w, "functions", r.Functions)
+	writeChanges(w, "UI events", r.UIEvents)
+	if len(r.BrokenMethods) > 0 {
+		fmt.Fprintf(w, "\nBroken methods:\n")
+		for _, m := range r.BrokenMethods {
+			fmt.Fprintf(w, "  %s (%s %s)\n", m.Method, m.Function, m.Reason)
+		}
+	}
+}
+
+func main() {
+	log.SetFlags(0)
+	jsonOutput := flag.Bool("json", false, "Write the report as JSON")
+	pkgDir := flag.String("pkg", ".", "Directory of the package with the API methods")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: go run apidiff.go [-json] [-pkg dir] old.json new.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	old, err := readAPIInfo(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	new, err := readAPIInfo(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	calls, err := methodCalls(*pkgDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := newReport(old, new, calls)
+	if *jsonOutput {
+		p, err := json.MarshalIndent(r, "", "    ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(append(p, '\n'))
+		return
+	}
+	r.writeText(os.Stdout)
+}
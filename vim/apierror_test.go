@@ -0,0 +1,60 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestErrors(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	_, err := v.BufferLineCount(vim.Buffer(100))
+	var e *vim.APIError
+	if !errors.As(err, &e) {
+		t.Fatalf("BufferLineCount(invalid) returned %T %v, want *vim.APIError", err, err)
+	}
+	if e.Kind != vim.ValidationError || e.Method != "nvim_buf_line_count" {
+		t.Errorf("error kind, method = %v, %q, want %v, %q", e.Kind, e.Method, vim.ValidationError, "nvim_buf_line_count")
+	}
+
+	p := v.NewPipeline()
+	var n int
+	p.BufferLineCount(1, &n)
+	p.BufferLineCount(100, &n)
+	err = p.Wait()
+	el, ok := err.(vim.ErrorList)
+	if !ok || len(el) != 1 {
+		t.Fatalf("Wait() returned %T %v, want ErrorList with one error", err, err)
+	}
+	ce, ok := el[0].(*vim.CallError)
+	if !ok || ce.Index != 1 {
+		t.Errorf("el[0] = %#v, want *CallError with Index 1", el[0])
+	}
+	if !errors.As(err, &e) || e.Kind != vim.ValidationError {
+		t.Errorf("errors.As(ErrorList) did not find validation error")
+	}
+
+	// Handlers return typed errors to Neovim.
+	err = v.RegisterHandler("fail", func(v *vim.Vim) error {
+		return fmt.Errorf("wrapped: %w", &vim.APIError{Kind: vim.ValidationError, Message: "bad arg"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Call("fail", nil)
+	want := []interface{}{int64(vim.ValidationError), "bad arg"}
+	if re, ok := err.(rpc.Error); !ok || !reflect.DeepEqual(re.Value, want) {
+		t.Errorf("handler error = %#v, want %#v", err, want)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"fmt"
+)
+
+// APIMetadata describes the API provided by a Neovim instance.
+//
+//	:help api-metadata
+type APIMetadata struct {
+	// ChannelID is Neovim's channel id for the client.
+	ChannelID int
+
+	// Version is the version of the Neovim instance and its API.
+	Version APIVersion
+
+	// Functions is the list of API functions.
+	Functions []*APIFunction
+
+	// UIEvents is the list of events sent to attached UIs.
+	UIEvents []*UIEvent
+
+	// UIOptions is the list of options supported by nvim_ui_attach.
+	UIOptions []string
+
+	// ErrorTypes maps error type names to the error type.
+	ErrorTypes map[string]APIErrorType
+
+	// Types maps extension type names to the extension type.
+	Types map[string]APIType
+}
+
+// APIVersion is the version of a Neovim instance and its API.
+type APIVersion struct {
+	Major int `msgpack:"major"`
+	Minor int `msgpack:"minor"`
+	Patch int `msgpack:"patch"`
+
+	// APILevel is the API level of the Neovim instance.
+	APILevel int `msgpack:"api_level"`
+
+	// APICompatible is the lowest API level that the API is compatible with.
+	APICompatible int `msgpack:"api_compatible"`
+
+	// APIPrerelease is true if the API is not final.
+	APIPrerelease bool `msgpack:"api_prerelease"`
+}
+
+// APIFunction describes an API function.
+type APIFunction struct {
+	Name       string     `msgpack:"name"`
+	Parameters []APIParam `msgpack:"parameters"`
+	ReturnType string     `msgpack:"return_type"`
+
+	// Method is true if the function is a method on the type of the first
+	// parameter.
+	Method bool `msgpack:"method"`
+
+	// Since is the API level where the function was introduced.
+	Since int `msgpack:"since"`
+
+	// DeprecatedSince is the API level where the function was deprecated or
+	// zero if the function is not deprecated.
+	DeprecatedSince int `msgpack:"deprecated_since"`
+}
+
+// APIParam is the type and name of a parameter to an API function or UI
+// event.
+type APIParam struct {
+	Type string `msgpack:",array"`
+	Name string
+}
+
+// UIEvent describes an event sent to attached UIs.
+type UIEvent struct {
+	Name       string     `msgpack:"name"`
+	Parameters []APIParam `msgpack:"parameters"`
+	Since      int        `msgpack:"since"`
+}
+
+// APIErrorType describes a kind of error returned by the API.
+type APIErrorType struct {
+	ID int `msgpack:"id"`
+}
+
+// APIType describes an extension type used by the API.
+type APIType struct {
+	ID     int    `msgpack:"id"`
+	Prefix string `msgpack:"prefix"`
+}
+
+// Function returns the function with the given name or nil if the API does
+// not have the function.
+func (info *APIMetadata) Function(name string) *APIFunction {
+	for _, f := range info.Functions {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// apiInfoReply is the reply to nvim_get_api_info.
+type apiInfoReply struct {
+	ChannelID int `msgpack:",array"`
+	Info      struct {
+		Version    APIVersion              `msgpack:"version"`
+		Functions  []*APIFunction          `msgpack:"functions"`
+		UIEvents   []*UIEvent              `msgpack:"ui_events"`
+		UIOptions  []string                `msgpack:"ui_options"`
+		ErrorTypes map[string]APIErrorType `msgpack:"error_types"`
+		Types      map[string]APIType      `msgpack:"types"`
+	}
+}
+
+// APIMetadata returns information about the API provided by Neovim. The
+// information is fetched with nvim_get_api_info on the first call and cached
+// for the lifetime of the client.
+func (v *Vim) APIMetadata() (*APIMetadata, error) {
+	v.mu.Lock()
+	info := v.apiMetadata
+	v.mu.Unlock()
+	if info != nil {
+		return info, nil
+	}
+
+	// Fetch the information without holding the lock. Concurrent callers
+	// may fetch the information more than once. The first to complete wins.
+	const sm = "nvim_get_api_info"
+	var reply apiInfoReply
+	if err := fixError(sm, v.ep.Call(sm, &reply)); err != nil {
+		return nil, err
+	}
+	info = &APIMetadata{
+		ChannelID:  reply.ChannelID,
+		Version:    reply.Info.Version,
+		Functions:  reply.Info.Functions,
+		UIEvents:   reply.Info.UIEvents,
+		UIOptions:  reply.Info.UIOptions,
+		ErrorTypes: reply.Info.ErrorTypes,
+		Types:      reply.Info.Types,
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.apiMetadata == nil {
+		v.apiMetadata = info
+	}
+	return v.apiMetadata, nil
+}
+
+// APILevel returns the API level of the connected Neovim instance.
+func (v *Vim) APILevel() (int, error) {
+	info, err := v.APIMetadata()
+	if err != nil {
+		return 0, err
+	}
+	return info.Version.APILevel, nil
+}
+
+// HasFunction returns true if the connected Neovim instance provides the API
+// function with the given name.
+func (v *Vim) HasFunction(name string) (bool, error) {
+	info, err := v.APIMetadata()
+	if err != nil {
+		return false, err
+	}
+	return info.Function(name) != nil, nil
+}
+
+// APILevelError is the error returned when an API function is called on a
+// Neovim instance with an API level lower than the level where the function
+// was introduced. The function is not called.
+type APILevelError struct {
+	// Method is the name of the API function.
+	Method string
+
+	// Required is the API level where the function was introduced.
+	Required int
+
+	// Level is the API level of the connected Neovim instance.
+	Level int
+}
+
+func (e *APILevelError) Error() string {
+	return fmt.Sprintf("nvim: %s requires api level %d, connected Neovim has api level %d", e.Method, e.Required, e.Level)
+}
+
+// checkLevel returns an *APILevelError if the connected Neovim instance does
+// not support API function sm. Every Neovim instance supports the functions
+// at API level 1, so calls to these functions do not fetch the API info.
+func (v *Vim) checkLevel(sm string) error {
+	required := functionLevels[sm]
+	if required == 0 {
+		return nil
+	}
+	level, err := v.APILevel()
+	if err != nil {
+		return err
+	}
+	if level < required {
+		return &APILevelError{Method: sm, Required: required, Level: level}
+	}
+	return nil
+}
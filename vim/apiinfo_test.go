@@ -0,0 +1,65 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestAPILevel(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	f.SetAPILevel(4)
+	v := f.Vim()
+
+	level, err := v.APILevel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != 4 {
+		t.Errorf("APILevel() = %d, want 4", level)
+	}
+	for name, want := range map[string]bool{"nvim_buf_line_count": true, "nvim_no_such_function": false} {
+		ok, err := v.HasFunction(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != want {
+			t.Errorf("HasFunction(%q) = %v, want %v", name, ok, want)
+		}
+	}
+
+	// nvim_create_namespace was added at API level 5.
+	_, err = v.CreateNamespace("test")
+	var e *vim.APILevelError
+	if !errors.As(err, &e) || e.Method != "nvim_create_namespace" || e.Required != 5 || e.Level != 4 {
+		t.Errorf("CreateNamespace() returned %v, want APILevelError", err)
+	}
+
+	var ns int
+	p := v.NewPipeline()
+	p.SetVar("x", 1, nil)
+	p.CreateNamespace("test", &ns)
+	err = p.Wait()
+	el, ok := err.(vim.ErrorList)
+	if !ok || len(el) != 1 || !errors.As(el[0], &e) || el[0].(*vim.CallError).Index != 1 {
+		t.Errorf("Wait() returned %v, want error for call 1", err)
+	}
+
+	p = v.NewAtomicPipeline()
+	p.SetVar("y", 1, nil)
+	p.CreateNamespace("test", &ns)
+	err = p.Wait()
+	if el, ok := err.(vim.ErrorList); !ok || len(el) != 1 || !errors.As(el[0], &e) {
+		t.Errorf("atomic Wait() returned %v, want APILevelError", err)
+	}
+	var y interface{}
+	if err := v.Var("y", &y); err == nil {
+		t.Errorf("atomic pipeline sent calls after API level error, y = %v", y)
+	}
+}
@@ -0,0 +1,692 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+// +build ignore
+
+// This file declares the overrides used by genapi.go to generate an
+// idiomatic and convenient API for Go programmers from the API metadata in
+// api.json.
+
+package main
+
+var extensions = []*struct {
+	Type string
+	Doc  string
+}{
+	{"Buffer", `// Buffer represents a remote Neovim buffer.`},
+	{"Window", `// Window represents a remote Neovim window.`},
+	{"Tabpage", `// Tabpage represents a remote Neovim tabpage.`},
+}
+
+type param struct{ Name, Type string }
+
+// override specifies the Go method for an API function. Fields with the zero
+// value are derived from the API metadata.
+type override struct {
+	// Name is the name of the method.
+	Name string
+
+	// Sm is the name of the API function.
+	Sm string
+
+	// Return is the Go type of the return value.
+	Return string
+
+	// Doc is the documentation for the method.
+	Doc string
+
+	// Params specifies the names and Go types of all parameters.
+	Params []param
+}
+
+var overrides = []*override{
+	{
+		Name:   "BufferLineCount",
+		Sm:     "nvim_buf_line_count",
+		Return: "int",
+		Params: []param{{"buffer", "Buffer"}},
+		Doc:    `// BufferLineCount returns the number of lines in the buffer.`,
+	},
+	{
+		Name:   "BufferLines",
+		Sm:     "nvim_buf_get_lines",
+		Return: "[][]byte",
+		Params: []param{{"buffer", "Buffer"}, {"start", "int"}, {"end", "int"}, {"strict", "bool"}},
+		Doc: `
+// BufferLines retrieves a line range from a buffer.
+//
+// Indexing is zero-based, end-exclusive. Negative indices are interpreted as
+// length+1+index, i e -1 refers to the index past the end. So to get the last
+// element set start=-2 and end=-1.
+//
+// Out-of-bounds indices are clamped to the nearest valid value, unless strict
+// = true.
+`,
+	},
+	{
+		Name:   "SetBufferLines",
+		Sm:     "nvim_buf_set_lines",
+		Params: []param{{"buffer", "Buffer"}, {"start", "int"}, {"end", "int"}, {"strict", "bool"}, {"replacement", "[][]byte"}},
+		Doc: `
+// SetBufferLines replaces a line range on a buffer.
+//
+// Indexing is zero-based, end-exclusive. Negative indices are interpreted as
+// length+1+index, ie -1 refers to the index past the end. So to change or
+// delete the last element set start=-2 and end=-1.
+//
+// To insert lines at a given index, set both start and end to the same index.
+// To delete a range of lines, set replacement to an empty array.
+//
+// Out-of-bounds indices are clamped to the nearest valid value, unless strict
+// = true.
+`,
+	},
+	{
+		Name:   "BufferVar",
+		Sm:     "nvim_buf_get_var",
+		Return: "interface{}",
+		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
+		Doc:    `// BufferVar gets a buffer-scoped (b:) variable.`,
+	},
+	{
+		Name:   "SetBufferVar",
+		Sm:     "nvim_buf_set_var",
+		Return: "interface{}",
+		Params: []param{{"buffer", "Buffer"}, {"name", "string"}, {"value", "interface{}"}},
+		Doc: `
+// SetBufferVar sets a buffer-scoped (b:) variable. Use DeleteBufferVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+`,
+	},
+	{
+		Name:   "BufferOption",
+		Sm:     "nvim_buf_get_option",
+		Return: "interface{}",
+		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
+		Doc:    `// BufferOption gets a buffer option value.`,
+	},
+	{
+		Name:   "SetBufferOption",
+		Sm:     "nvim_buf_set_option",
+		Params: []param{{"buffer", "Buffer"}, {"name", "string"}, {"value", "interface{}"}},
+		Doc: `
+// SetBufferOption sets a buffer option value. The value nil deletes the option
+// in the case where there's a global fallback.
+`,
+	},
+	{
+		Name:   "BufferNumber",
+		Sm:     "nvim_buf_get_number",
+		Return: "int",
+		Params: []param{{"buffer", "Buffer"}},
+		Doc: `
+// BufferNumber gets a buffer's number.
+//
+// Deprecated: The buffer number is the value of the Buffer.
+`,
+	},
+	{
+		Name:   "BufferName",
+		Sm:     "nvim_buf_get_name",
+		Return: "string",
+		Params: []param{{"buffer", "Buffer"}},
+		Doc:    `// BufferName gets the full file name of a buffer.`,
+	},
+	{
+		Name:   "SetBufferName",
+		Sm:     "nvim_buf_set_name",
+		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
+		Doc: `
+// SetBufferName sets the full file name of a buffer.
+// BufFilePre/BufFilePost are triggered.
+`,
+	},
+	{
+		Name:   "IsBufferValid",
+		Sm:     "nvim_buf_is_valid",
+		Return: "bool",
+		Params: []param{{"buffer", "Buffer"}},
+		Doc:    `// IsBufferValid returns true if the buffer is valid.`,
+	},
+	{
+		Name:   "BufferMark",
+		Sm:     "nvim_buf_get_mark",
+		Return: "[2]int",
+		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
+		Doc:    `// BufferMark returns the (row,col) of the named mark.`,
+	},
+	{
+		Name:   "AddBufferHighlight",
+		Sm:     "nvim_buf_add_highlight",
+		Return: "int",
+		Params: []param{{"buffer", "Buffer"}, {"srcID", "int"}, {"hlGroup", "string"}, {"line", "int"}, {"startCol", "int"}, {"endCol", "int"}},
+		Doc: `
+// AddBufferHighlight adds a highlight to buffer and returns the source id of
+// the highlight.
+//
+// AddBufferHighlight can be used for plugins which dynamically generate
+// highlights to a buffer (like a semantic highlighter or linter). The function
+// adds a single highlight to a buffer. Unlike matchaddpos() highlights follow
+// changes to line numbering (as lines are inserted/removed above the
+// highlighted line), like signs and marks do.
+//
+// The srcID is useful for batch deletion/updating of a set of highlights. When
+// called with srcID = 0, an unique source id is generated and returned.
+// Succesive calls can pass in it as srcID to add new highlights to the same
+// source group. All highlights in the same group can then be cleared with
+// ClearBufferHighlight. If the highlight never will be manually deleted pass
+// in -1 for srcID.
+//
+// If hlGroup is the empty string no highlight is added, but a new srcID is
+// still returned. This is useful for an external plugin to synchrounously
+// request an unique srcID at initialization, and later asynchronously add and
+// clear highlights in response to buffer changes.
+//
+// The startCol and endCol parameters specify the range of columns to
+// highlight. Use endCol = -1 to highlight to the end of the line.
+`,
+	},
+	{
+		Name:   "ClearBufferHighlight",
+		Sm:     "nvim_buf_clear_highlight",
+		Params: []param{{"buffer", "Buffer"}, {"srcID", "int"}, {"startLine", "int"}, {"endLine", "int"}},
+		Doc: `
+// ClearBufferHighlight clears highlights from a given source group and a range
+// of lines.
+//
+// To clear a source group in the entire buffer, pass in 1 and -1 to startLine
+// and endLine respectively.
+//
+// The lineStart and lineEnd parameters specify the range of lines to clear.
+// The end of range is exclusive. Specify -1 to clear to the end of the file.
+`,
+	},
+	{
+		Name:   "TabpageWindows",
+		Sm:     "nvim_tabpage_list_wins",
+		Return: "[]Window",
+		Params: []param{{"tabpage", "Tabpage"}},
+		Doc:    `// TabpageWindows returns the windows in a tabpage.`,
+	},
+	{
+		Name:   "TabpageVar",
+		Sm:     "nvim_tabpage_get_var",
+		Return: "interface{}",
+		Params: []param{{"tabpage", "Tabpage"}, {"name", "string"}},
+		Doc:    `// TabpageVar gets a tab-scoped (t:) variable.`,
+	},
+	{
+		Name:   "SetTabpageVar",
+		Sm:     "nvim_tabpage_set_var",
+		Return: "interface{}",
+		Params: []param{{"tabpage", "Tabpage"}, {"name", "string"}, {"value", "interface{}"}},
+		Doc: `
+// SetTabpageVar sets a tab-scoped (t:) variable. Use DeleteTabpageVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+`,
+	},
+	{
+		Name:   "TabpageWindow",
+		Sm:     "nvim_tabpage_get_win",
+		Return: "Window",
+		Params: []param{{"tabpage", "Tabpage"}},
+		Doc:    `// TabpageWindow gets the current window in a tab page.`,
+	},
+	{
+		Name:   "IsTabpageValid",
+		Sm:     "nvim_tabpage_is_valid",
+		Return: "bool",
+		Params: []param{{"tabpage", "Tabpage"}},
+		Doc:    `// IsTabpageValid checks if a tab page is valid.`,
+	},
+	{
+		Name:   "Command",
+		Sm:     "nvim_command",
+		Params: []param{{"str", "string"}},
+		Doc:    `// Command executes a single ex command.`,
+	},
+	{
+		Name:   "FeedKeys",
+		Sm:     "nvim_feedkeys",
+		Params: []param{{"keys", "string"}, {"mode", "string"}, {"escapeCsi", "bool"}},
+		Doc: `
+// FeedKeys Pushes keys to the Neovim user input buffer. Options can be a string
+// with the following character flags:
+//
+//  m:  Remap keys. This is default.
+//  n:  Do not remap keys.
+//  t:  Handle keys as if typed; otherwise they are handled as if coming from a
+//     mapping. This matters for undo, opening folds, etc. 
+`,
+	},
+	{
+		Name:   "Input",
+		Sm:     "nvim_input",
+		Return: "int",
+		Params: []param{{"keys", "string"}},
+		Doc: `
+// Input pushes bytes to the Neovim low level input buffer.
+// 
+// Unlike FeedKeys, this uses the lowest level input buffer and the call is not
+// deferred. It returns the number of bytes actually written(which can be less
+// than what was requested if the buffer is full).
+`,
+	},
+	{
+		Name:   "ReplaceTermcodes",
+		Sm:     "nvim_replace_termcodes",
+		Return: "string",
+		Params: []param{{"str", "string"}, {"fromPart", "bool"}, {"doLt", "bool"}, {"special", "bool"}},
+		Doc: `
+// ReplaceTermcodes replaces any terminal code strings by byte sequences. The
+// returned sequences are Nvim's internal representation of keys, for example:
+//
+//  <esc> -> '\x1b'
+//  <cr>  -> '\r'
+//  <c-l> -> '\x0c'
+//  <up>  -> '\x80ku'
+//
+// The returned sequences can be used as input to feedkeys.
+`,
+	},
+	{
+		Name:   "CommandOutput",
+		Sm:     "nvim_command_output",
+		Return: "string",
+		Params: []param{{"str", "string"}},
+		Doc: `
+// CommandOutput executes a single ex command and returns the output.
+`,
+	},
+	{
+		Name:   "Eval",
+		Sm:     "nvim_eval",
+		Return: "interface{}",
+		Params: []param{{"str", "string"}},
+		Doc: `
+// Eval evaluates the expression str using the Vim internal expression
+// evaluator. 
+//
+//  :help expression
+`,
+	},
+	{
+		Name:   "Strwidth",
+		Sm:     "nvim_strwidth",
+		Return: "int",
+		Params: []param{{"str", "string"}},
+		Doc: `
+// Strwidth returns the number of display cells the string occupies. Tab is
+// counted as one cell.
+`,
+	},
+	{
+		Name:   "RuntimePaths",
+		Sm:     "nvim_list_runtime_paths",
+		Return: "[]string",
+		Doc: `
+// RuntimePaths returns a list of paths contained in the runtimepath option.
+`,
+	},
+	{
+		Name:   "ChangeDirectory",
+		Sm:     "nvim_set_current_dir",
+		Params: []param{{"dir", "string"}},
+		Doc:    `// ChangeDirectory changes Vim working directory.`,
+	},
+	{
+		Name:   "CurrentLine",
+		Sm:     "nvim_get_current_line",
+		Return: "[]byte",
+		Doc:    `// CurrentLine gets the current line in the current buffer.`,
+	},
+	{
+		Name:   "SetCurrentLine",
+		Sm:     "nvim_set_current_line",
+		Params: []param{{"line", "[]byte"}},
+		Doc:    `// SetCurrentLine sets the current line in the current buffer.`,
+	},
+	{
+		Name: "DeleteCurrentLine",
+		Sm:   "nvim_del_current_line",
+		Doc:  `// DeleteCurrentLine deletes the current line in the current buffer.`,
+	},
+	{
+		Name:   "Var",
+		Sm:     "nvim_get_var",
+		Return: "interface{}",
+		Params: []param{{"name", "string"}},
+		Doc:    `// Var gets a global (g:) variable.`,
+	},
+	{
+		Name:   "SetVar",
+		Sm:     "nvim_set_var",
+		Return: "interface{}",
+		Params: []param{{"name", "string"}, {"value", "interface{}"}},
+		Doc: `
+// SetVar sets a global (g:) variable. Use DeleteVar to delete the variable.
+// Neovim does not return the previous value of the variable; result is set
+// to nil.
+`,
+	},
+	{
+		Name:   "Vvar",
+		Sm:     "nvim_get_vvar",
+		Return: "interface{}",
+		Params: []param{{"name", "string"}},
+		Doc:    `// Vvar gets a vim (v:) variable.`,
+	},
+	{
+		Name:   "Option",
+		Sm:     "nvim_get_option",
+		Return: "interface{}",
+		Params: []param{{"name", "string"}},
+		Doc:    `// Option gets an option.`,
+	},
+	{
+		Name:   "SetOption",
+		Sm:     "nvim_set_option",
+		Params: []param{{"name", "string"}, {"value", "interface{}"}},
+		Doc:    `// SetOption sets an option.`,
+	},
+	{
+		Name:   "WriteOut",
+		Sm:     "nvim_out_write",
+		Params: []param{{"str", "string"}},
+		Doc: `
+// WriteOut writes a message to vim output buffer. The string is split and
+// flushed after each newline. Incomplete lines are kept for writing later.
+`,
+	},
+	{
+		Name:   "WriteErr",
+		Sm:     "nvim_err_write",
+		Params: []param{{"str", "string"}},
+		Doc: `
+// WriteErr writes a message to vim error buffer. The string is split and
+// flushed after each newline. Incomplete lines are kept for writing later.
+`,
+	},
+	{
+		Name:   "ReportError",
+		Sm:     "nvim_err_writeln",
+		Params: []param{{"str", "string"}},
+		Doc:    `// ReportError writes prints str and a newline as an error message.`,
+	},
+	{
+		Name:   "Buffers",
+		Sm:     "nvim_list_bufs",
+		Return: "[]Buffer",
+		Doc:    `// Buffers returns the current list of buffers.`,
+	},
+	{
+		Name:   "CurrentBuffer",
+		Sm:     "nvim_get_current_buf",
+		Return: "Buffer",
+		Doc:    `// CurrentBuffer returns the current buffer.`,
+	},
+	{
+		Name:   "SetCurrentBuffer",
+		Sm:     "nvim_set_current_buf",
+		Params: []param{{"buffer", "Buffer"}},
+		Doc:    `// SetCurrentBuffer sets the current buffer.`,
+	},
+	{
+		Name:   "Windows",
+		Sm:     "nvim_list_wins",
+		Return: "[]Window",
+		Doc:    `// Windows returns the current list of windows.`,
+	},
+	{
+		Name:   "CurrentWindow",
+		Sm:     "nvim_get_current_win",
+		Return: "Window",
+		Doc:    `// CurrentWindow returns the current window.`,
+	},
+	{
+		Name:   "SetCurrentWindow",
+		Sm:     "nvim_set_current_win",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// SetCurrentWindow sets the current window.`,
+	},
+	{
+		Name:   "Tabpages",
+		Sm:     "nvim_list_tabpages",
+		Return: "[]Tabpage",
+		Doc:    `// Tabpages returns the current list of tabpages.`,
+	},
+	{
+		Name:   "CurrentTabpage",
+		Sm:     "nvim_get_current_tabpage",
+		Return: "Tabpage",
+		Doc:    `// CurrentTabpage returns the current tabpage.`,
+	},
+	{
+		Name:   "SetCurrentTabpage",
+		Sm:     "nvim_set_current_tabpage",
+		Params: []param{{"tabpage", "Tabpage"}},
+		Doc:    `// SetCurrentTabpage sets the current tabpage.`,
+	},
+	{
+		Name:   "Subscribe",
+		Sm:     "nvim_subscribe",
+		Params: []param{{"event", "string"}},
+		Doc:    `// Subscribe subscribes to a Neovim event.`,
+	},
+	{
+		Name:   "Unsubscribe",
+		Sm:     "nvim_unsubscribe",
+		Params: []param{{"event", "string"}},
+		Doc:    `// Unsubscribe unsubscribes to a Neovim event.`,
+	},
+	{
+		Name:   "NameToColor",
+		Sm:     "nvim_get_color_by_name",
+		Return: "int",
+		Params: []param{{"name", "string"}},
+	},
+	{
+		Name:   "ColorMap",
+		Sm:     "nvim_get_color_map",
+		Return: "map[string]interface{}",
+	},
+	{
+		Name:   "APIInfo",
+		Sm:     "nvim_get_api_info",
+		Return: "[]interface{}",
+	},
+	{
+		Name:   "WindowBuffer",
+		Sm:     "nvim_win_get_buf",
+		Return: "Buffer",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// WindowBuffer returns the current buffer in a window.`,
+	},
+	{
+		Name:   "WindowCursor",
+		Sm:     "nvim_win_get_cursor",
+		Return: "[2]int",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// WindowCursor returns the cursor position in the window.`,
+	},
+	{
+		Name:   "SetWindowCursor",
+		Sm:     "nvim_win_set_cursor",
+		Params: []param{{"window", "Window"}, {"pos", "[2]int"}},
+		Doc:    `// SetWindowCursor sets the cursor position in the window to the given position.`,
+	},
+	{
+		Name:   "WindowHeight",
+		Sm:     "nvim_win_get_height",
+		Return: "int",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// WindowHeight returns the window height.`,
+	},
+	{
+		Name:   "SetWindowHeight",
+		Sm:     "nvim_win_set_height",
+		Params: []param{{"window", "Window"}, {"height", "int"}},
+		Doc:    `// SetWindowHeight sets the window height.`,
+	},
+	{
+		Name:   "WindowWidth",
+		Sm:     "nvim_win_get_width",
+		Return: "int",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// WindowWidth returns the window width.`,
+	},
+	{
+		Name:   "SetWindowWidth",
+		Sm:     "nvim_win_set_width",
+		Params: []param{{"window", "Window"}, {"width", "int"}},
+		Doc:    `// SetWindowWidth sets the window width.`,
+	},
+	{
+		Name:   "WindowVar",
+		Sm:     "nvim_win_get_var",
+		Return: "interface{}",
+		Params: []param{{"window", "Window"}, {"name", "string"}},
+		Doc:    `// WindowVar gets a window-scoped (w:) variable.`,
+	},
+	{
+		Name:   "SetWindowVar",
+		Sm:     "nvim_win_set_var",
+		Return: "interface{}",
+		Params: []param{{"window", "Window"}, {"name", "string"}, {"value", "interface{}"}},
+		Doc: `
+// SetWindowVar sets a window-scoped (w:) variable. Use DeleteWindowVar to
+// delete the variable. Neovim does not return the previous value of the
+// variable; result is set to nil.
+`,
+	},
+	{
+		Name:   "WindowOption",
+		Sm:     "nvim_win_get_option",
+		Return: "interface{}",
+		Params: []param{{"window", "Window"}, {"name", "string"}},
+		Doc:    `// WindowOption gets a window option.`,
+	},
+	{
+		Name:   "SetWindowOption",
+		Sm:     "nvim_win_set_option",
+		Params: []param{{"window", "Window"}, {"name", "string"}, {"value", "interface{}"}},
+		Doc:    `// SetWindowOption sets a window option.`,
+	},
+	{
+		Name:   "WindowPosition",
+		Sm:     "nvim_win_get_position",
+		Return: "[2]int",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// WindowPosition gets the window position in display cells. First position is zero.`,
+	},
+	{
+		Name:   "WindowTabpage",
+		Sm:     "nvim_win_get_tabpage",
+		Return: "Tabpage",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// WindowTabpage gets the tab page that contains the window.`,
+	},
+	{
+		Name:   "IsWindowValid",
+		Sm:     "nvim_win_is_valid",
+		Return: "bool",
+		Params: []param{{"window", "Window"}},
+		Doc:    `// IsWindowValid returns true if the window is valid.`,
+	},
+	{Name: "Exec", Sm: "nvim_exec2"},
+	{Name: "Namespaces", Sm: "nvim_get_namespaces", Return: "map[string]int"},
+
+	// The names without Raw are used by the methods in callback.go that bind
+	// Go functions.
+	{Name: "SetRawKeymap", Sm: "nvim_set_keymap"},
+	{Name: "CreateRawUserCommand", Sm: "nvim_create_user_command"},
+	{Name: "CreateRawAutocmd", Sm: "nvim_create_autocmd"},
+	{Name: "OptionInfo", Sm: "nvim_get_option_info2"},
+	{Name: "TryResizeUI", Sm: "nvim_ui_try_resize"},
+	{Name: "TryResizeUIGrid", Sm: "nvim_ui_try_resize_grid"},
+	{Name: "SetPopupmenuHeight", Sm: "nvim_ui_pum_set_height"},
+	{Name: "SetPopupmenuBounds", Sm: "nvim_ui_pum_set_bounds"},
+}
+
+// skipped is the set of API functions that are not generated because the
+// vim package implements a more convenient method for the function.
+var skipped = map[string]bool{
+	"nvim_call_function": true, // Call
+	"nvim_call_atomic":   true, // NewAtomicPipeline
+	"nvim_ui_attach":     true, // AttachUI
+	"nvim_ui_detach":     true, // DetachUI
+
+	"nvim_buf_set_extmark":       true, // SetBufferExtmark
+	"nvim_buf_get_extmark_by_id": true, // BufferExtmark
+	"nvim_buf_get_extmarks":      true, // BufferExtmarks
+
+	"nvim_open_win":       true, // OpenWindow
+	"nvim_win_set_config": true, // SetWindowConfig
+	"nvim_win_get_config": true, // WindowConfig
+
+	"nvim_exec_lua": true, // ExecLua
+}
+
+// replacements maps deprecated API functions to the function that replaces
+// the deprecated function. Functions not in this map are replaced by the
+// function with the same name in the nvim_ namespace.
+var replacements = map[string]string{
+	"buffer_del_line":           "nvim_buf_set_lines",
+	"buffer_get_line":           "nvim_buf_get_lines",
+	"buffer_get_line_slice":     "nvim_buf_get_lines",
+	"buffer_insert":             "nvim_buf_set_lines",
+	"buffer_set_line":           "nvim_buf_set_lines",
+	"buffer_set_line_slice":     "nvim_buf_set_lines",
+	"nvim_buf_clear_highlight":  "nvim_buf_clear_namespace",
+	"nvim_buf_get_number":       "",
+	"nvim_buf_set_virtual_text": "nvim_buf_set_extmark",
+	"nvim_command_output":       "nvim_exec2",
+	"nvim_exec":                 "nvim_exec2",
+	"nvim_execute_lua":          "nvim_exec_lua",
+	"nvim_get_hl_by_id":         "nvim_get_hl",
+	"nvim_get_hl_by_name":       "nvim_get_hl",
+	"nvim_get_option_info":      "nvim_get_option_info2",
+	"tabpage_get_window":        "nvim_tabpage_get_win",
+	"tabpage_get_windows":       "nvim_tabpage_list_wins",
+	"vim_change_directory":      "nvim_set_current_dir",
+	"vim_get_buffers":           "nvim_list_bufs",
+	"vim_get_current_buffer":    "nvim_get_current_buf",
+	"vim_get_current_window":    "nvim_get_current_win",
+	"vim_get_tabpages":          "nvim_list_tabpages",
+	"vim_get_windows":           "nvim_list_wins",
+	"vim_name_to_color":         "nvim_get_color_by_name",
+	"vim_report_error":          "nvim_err_writeln",
+	"vim_set_current_buffer":    "nvim_set_current_buf",
+	"vim_set_current_window":    "nvim_set_current_win",
+	"window_get_buffer":         "nvim_win_get_buf",
+}
+
+// uiParamTypes maps UI event parameters, specified as event.param, to Go
+// types. The types of other parameters are derived from the metadata.
+var uiParamTypes = map[string]string{
+	"mode_info_set.cursor_styles": "[]map[string]interface{}",
+	"highlight_set.attrs":         "HighlightAttrs",
+	"hl_attr_define.rgb_attrs":    "HighlightAttrs",
+	"hl_attr_define.cterm_attrs":  "HighlightAttrs",
+	"hl_attr_define.info":         "[]map[string]interface{}",
+	"popupmenu_show.items":        "[]PopupmenuItem",
+	"tabline_update.tabs":         "[]map[string]interface{}",
+	"tabline_update.buffers":      "[]map[string]interface{}",
+	"cmdline_show.content":        "[]TextChunk",
+	"cmdline_block_show.lines":    "[][]TextChunk",
+	"cmdline_block_append.lines":  "[]TextChunk",
+	"wildmenu_show.items":         "[]string",
+	"msg_show.content":            "[]TextChunk",
+	"msg_showcmd.content":         "[]TextChunk",
+	"msg_showmode.content":        "[]TextChunk",
+	"msg_ruler.content":           "[]TextChunk",
+}
+
+// uiHandWritten is the set of UI events with hand written Go types.
+var uiHandWritten = map[string]bool{
+	"grid_line": true,
+}
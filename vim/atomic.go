@@ -0,0 +1,170 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/garyburd/neovim-go/msgpack"
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+)
+
+// NewAtomicPipeline creates a pipeline that sends its calls to Neovim as a
+// single nvim_call_atomic request. Neovim executes the calls in order with no
+// other events between the calls. Neovim stops executing the calls at the
+// first call that fails.
+//
+// The calls are sent in the pipeline's Wait method. The result arguments are
+// updated when Wait returns. As with other pipelines, if there is more than
+// one call in the pipeline, then Wait returns the errors for the failed calls
+// as *CallError values in an ErrorList. Otherwise, Wait returns the error for
+// the call. Errors sending the batch or decoding the reply are returned as is.
+//
+//	:help nvim_call_atomic()
+func (v *Vim) NewAtomicPipeline() *Pipeline {
+	return &Pipeline{v: v, ep: v.ep, atomic: true}
+}
+
+type atomicCall struct {
+	sm     string
+	result interface{}
+	args   []interface{}
+}
+
+// MarshalMsgPack encodes the call as the array [method, args] expected by
+// nvim_call_atomic.
+func (c *atomicCall) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackArrayLen(2); err != nil {
+		return err
+	}
+	if err := enc.PackString(c.sm); err != nil {
+		return err
+	}
+	args := c.args
+	if args == nil {
+		args = []interface{}{}
+	}
+	return enc.Encode(args)
+}
+
+// atomicReply decodes the nvim_call_atomic reply, the array [results, error],
+// directly to the result arguments of the calls in the batch.
+type atomicReply struct {
+	batch []atomicCall
+	errs  ErrorList
+
+	// failed is the error [index, kind, message] for the failed call or nil
+	// if all calls succeeded.
+	failed []interface{}
+}
+
+// atomicResults decodes the results array in the nvim_call_atomic reply.
+type atomicResults atomicReply
+
+func (r *atomicReply) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.Type() != msgpack.ArrayLen || dec.Len() != 2 {
+		return fmt.Errorf("nvim: unexpected nvim_call_atomic reply %s", dec.Type())
+	}
+	if err := dec.Decode((*atomicResults)(r)); err != nil {
+		return err
+	}
+	return dec.Decode(&r.failed)
+}
+
+func (r *atomicResults) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.Type() != msgpack.ArrayLen || dec.Len() > len(r.batch) {
+		return fmt.Errorf("nvim: unexpected nvim_call_atomic results %s", dec.Type())
+	}
+	n := dec.Len()
+	for i := 0; i < n; i++ {
+		result := r.batch[i].result
+		if result == nil {
+			var discard interface{}
+			result = &discard
+		}
+		if err := dec.Decode(result); err != nil {
+			if _, ok := err.(*msgpack.DecodeConvertError); !ok {
+				return err
+			}
+			r.errs = append(r.errs, &CallError{Index: i, Err: err})
+		}
+	}
+	return nil
+}
+
+func (p *Pipeline) waitAtomic(ctx context.Context) error {
+	batch := p.batch
+	p.batch = nil
+	if err := p.err; err != nil {
+		// Neovim does not support a call in the batch. Send none of the
+		// calls.
+		p.err = nil
+		return atomicResult(len(batch), ErrorList{err})
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	calls := make([]interface{}, len(batch))
+	for i := range batch {
+		calls[i] = &batch[i]
+	}
+
+	reply := &atomicReply{batch: batch}
+	const sm = "nvim_call_atomic"
+	if err := fixError(sm, p.ep.CallContext(ctx, sm, reply, calls)); err != nil {
+		return err
+	}
+
+	if reply.failed != nil {
+		i, e, ok := atomicError(batch, reply.failed)
+		if !ok {
+			return fmt.Errorf("nvim: unexpected nvim_call_atomic error %v", reply.failed)
+		}
+		reply.errs = append(reply.errs, &CallError{Index: i, Err: e})
+	}
+	return atomicResult(len(batch), reply.errs)
+}
+
+// atomicError returns the index and error for the failed call in the
+// nvim_call_atomic error [index, kind, message].
+func atomicError(batch []atomicCall, failed []interface{}) (int, error, bool) {
+	if len(failed) != 3 {
+		return 0, nil, false
+	}
+	i, ok := toInt(failed[0])
+	if !ok || i < 0 || i >= len(batch) {
+		return 0, nil, false
+	}
+	e, ok := fixError(batch[i].sm, rpc.Error{Value: failed[1:]}).(*APIError)
+	if !ok {
+		return 0, nil, false
+	}
+	return i, e, true
+}
+
+// atomicResult returns the errors for a batch of n calls in the same form as
+// the errors returned from a pipeline that is not atomic.
+func atomicResult(n int, el ErrorList) error {
+	switch {
+	case len(el) == 0:
+		return nil
+	case n > 1:
+		return el
+	default:
+		return el[0].(*CallError).Err
+	}
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	}
+	return 0, false
+}
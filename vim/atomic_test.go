@@ -0,0 +1,132 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+var pipelineErrorTests = []struct {
+	name string
+	// calls adds calls to the pipeline. Calls to buffer 100 fail.
+	calls func(p *vim.Pipeline, b vim.Buffer, n *int)
+	// index is the index of the failed call or -1 if Wait returns the
+	// error for the call.
+	index int
+}{
+	{
+		name: "one call",
+		calls: func(p *vim.Pipeline, b vim.Buffer, n *int) {
+			p.BufferLineCount(vim.Buffer(100), n)
+		},
+		index: -1,
+	},
+	{
+		name: "first of many",
+		calls: func(p *vim.Pipeline, b vim.Buffer, n *int) {
+			p.BufferLineCount(vim.Buffer(100), n)
+			p.BufferLineCount(b, n)
+		},
+		index: 0,
+	},
+	{
+		name: "last of many",
+		calls: func(p *vim.Pipeline, b vim.Buffer, n *int) {
+			p.BufferLineCount(b, n)
+			p.BufferLineCount(b, n)
+			p.BufferLineCount(vim.Buffer(100), n)
+		},
+		index: 2,
+	},
+}
+
+func TestPipelineErrors(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+	b := f.NewBuffer("", "a")
+
+	for _, atomic := range []bool{false, true} {
+		for _, tt := range pipelineErrorTests {
+			p := v.NewPipeline()
+			if atomic {
+				p = v.NewAtomicPipeline()
+			}
+			var n int
+			tt.calls(p, b, &n)
+			err := p.Wait()
+
+			var e *vim.APIError
+			if !errors.As(err, &e) || e.Kind != vim.ValidationError {
+				t.Errorf("%s, atomic=%v: Wait() returned %v, want validation error", tt.name, atomic, err)
+				continue
+			}
+			if tt.index < 0 {
+				if _, ok := err.(*vim.APIError); !ok {
+					t.Errorf("%s, atomic=%v: Wait() returned %T, want *vim.APIError", tt.name, atomic, err)
+				}
+				continue
+			}
+			el, ok := err.(vim.ErrorList)
+			if !ok || len(el) != 1 {
+				t.Errorf("%s, atomic=%v: Wait() returned %#v, want ErrorList with one error", tt.name, atomic, err)
+				continue
+			}
+			if ce, ok := el[0].(*vim.CallError); !ok || ce.Index != tt.index {
+				t.Errorf("%s, atomic=%v: Wait() returned %#v, want *CallError with Index %d", tt.name, atomic, el[0], tt.index)
+			}
+		}
+	}
+}
+
+func TestAtomicPipeline(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b := f.NewBuffer("", "a", "b")
+	p := v.NewAtomicPipeline()
+	var (
+		n     int
+		lines [][]byte
+	)
+	p.SetBufferLines(b, 0, 1, true, [][]byte{[]byte("x")})
+	p.BufferLineCount(b, &n)
+	p.BufferLines(b, 0, -1, true, &lines)
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("BufferLineCount() = %d, want 2", n)
+	}
+	if want := [][]byte{[]byte("x"), []byte("b")}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("BufferLines() = %q, want %q", lines, want)
+	}
+
+	var name string
+	p.BufferName(b, &name)
+	p.BufferLineCount(vim.Buffer(100), &n)
+	p.SetBufferName(b, "not set")
+	err := p.Wait()
+	el, ok := err.(vim.ErrorList)
+	if !ok || len(el) != 1 || el[0].(*vim.CallError).Index != 1 {
+		t.Fatalf("Wait() returned %#v, want ErrorList with *CallError at Index 1", err)
+	}
+	var e *vim.APIError
+	if !errors.As(err, &e) || e.Kind != vim.ValidationError || e.Method != "nvim_buf_line_count" {
+		t.Errorf("Wait() returned %v, want validation error for buffer_line_count", err)
+	}
+	name, err = v.BufferName(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "" {
+		t.Errorf("call after failed call in batch was executed, name = %q", name)
+	}
+}
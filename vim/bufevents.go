@@ -0,0 +1,170 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"errors"
+
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+)
+
+// BufferEvent is an event sent by Neovim to a client attached to a buffer
+// with nvim_buf_attach. The concrete type of a BufferEvent is one of
+// *BufferLinesEvent, *ChangedTickEvent or *DetachEvent.
+//
+//	:help api-buffer-updates
+type BufferEvent interface {
+	// EventBuffer returns the buffer for the event.
+	EventBuffer() Buffer
+}
+
+// BufferLinesEvent is sent when lines in the buffer change. The lines from
+// FirstLine to LastLine, zero-based and end-exclusive, are replaced by
+// LineData. A LastLine of -1 is the end of the buffer.
+//
+//	:help nvim_buf_lines_event
+type BufferLinesEvent struct {
+	Buffer Buffer
+
+	// ChangedTick is the value of b:changedtick after the change. It is zero
+	// when Neovim does not send a changedtick.
+	ChangedTick int
+
+	FirstLine int
+	LastLine  int
+	LineData  [][]byte
+
+	// More is true if the change is split across multiple events. Apply the
+	// events in order. The buffer is consistent after the event where More
+	// is false.
+	More bool
+}
+
+// ChangedTickEvent is sent when b:changedtick is incremented without a
+// change to the text, for example when the buffer is written.
+//
+//	:help nvim_buf_changedtick_event
+type ChangedTickEvent struct {
+	Buffer      Buffer
+	ChangedTick int
+}
+
+// DetachEvent is sent when Neovim stops sending updates for the buffer. No
+// further events are sent for the buffer.
+//
+//	:help nvim_buf_detach_event
+type DetachEvent struct {
+	Buffer Buffer
+}
+
+// EventBuffer returns the buffer for the event.
+func (e *BufferLinesEvent) EventBuffer() Buffer { return e.Buffer }
+
+// EventBuffer returns the buffer for the event.
+func (e *ChangedTickEvent) EventBuffer() Buffer { return e.Buffer }
+
+// EventBuffer returns the buffer for the event.
+func (e *DetachEvent) EventBuffer() Buffer { return e.Buffer }
+
+// AttachBufferEvents attaches to buffer b with nvim_buf_attach and calls fn
+// with the events for the buffer. If b = 0, then the current buffer is used.
+// AttachBufferEvents returns the attached buffer. If sendBuffer is true, then
+// the first event is a *BufferLinesEvent with the contents of the buffer.
+//
+// The events for all buffers are delivered in order from a single goroutine.
+// The function fn should return promptly. The last event for the buffer is a
+// *DetachEvent. Call DetachBuffer to stop the events.
+//
+// Attaching again to a buffer replaces the function for the buffer.
+//
+//	:help nvim_buf_attach()
+func (v *Vim) AttachBufferEvents(b Buffer, sendBuffer bool, opts map[string]interface{}, fn func(BufferEvent)) (Buffer, error) {
+	if b == 0 {
+		var err error
+		b, err = v.CurrentBuffer()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := v.registerBufferEventHandlers(); err != nil {
+		return 0, err
+	}
+
+	// Events can arrive before the reply to nvim_buf_attach. Add fn before
+	// attaching.
+	v.eventMu.Lock()
+	v.bufferEvents[b] = fn
+	v.eventMu.Unlock()
+
+	if opts == nil {
+		opts = map[string]interface{}{}
+	}
+	ok, err := v.AttachBuffer(b, sendBuffer, opts)
+	if err == nil && !ok {
+		err = errors.New("nvim: could not attach to buffer")
+	}
+	if err != nil {
+		v.eventMu.Lock()
+		delete(v.bufferEvents, b)
+		v.eventMu.Unlock()
+		return 0, err
+	}
+	return b, nil
+}
+
+func (v *Vim) registerBufferEventHandlers() error {
+	v.eventMu.Lock()
+	defer v.eventMu.Unlock()
+	if v.bufferEvents != nil {
+		return nil
+	}
+
+	// The queue runs the handlers one at a time in the order received.
+	q := rpc.InQueue(rpc.NewQueue())
+	err := v.ep.RegisterHandler("nvim_buf_lines_event",
+		func(v *Vim, b Buffer, changedTick interface{}, firstLine, lastLine int, lineData [][]byte, more bool) {
+			tick, _ := toInt(changedTick)
+			v.dispatchBufferEvent(&BufferLinesEvent{
+				Buffer:      b,
+				ChangedTick: tick,
+				FirstLine:   firstLine,
+				LastLine:    lastLine,
+				LineData:    lineData,
+				More:        more,
+			})
+		}, q)
+	if err != nil {
+		return err
+	}
+	err = v.ep.RegisterHandler("nvim_buf_changedtick_event",
+		func(v *Vim, b Buffer, changedTick int) {
+			v.dispatchBufferEvent(&ChangedTickEvent{Buffer: b, ChangedTick: changedTick})
+		}, q)
+	if err != nil {
+		return err
+	}
+	err = v.ep.RegisterHandler("nvim_buf_detach_event",
+		func(v *Vim, b Buffer) {
+			v.dispatchBufferEvent(&DetachEvent{Buffer: b})
+		}, q)
+	if err != nil {
+		return err
+	}
+	v.bufferEvents = make(map[Buffer]func(BufferEvent))
+	return nil
+}
+
+func (v *Vim) dispatchBufferEvent(e BufferEvent) {
+	v.eventMu.Lock()
+	fn := v.bufferEvents[e.EventBuffer()]
+	if _, ok := e.(*DetachEvent); ok {
+		delete(v.bufferEvents, e.EventBuffer())
+	}
+	v.eventMu.Unlock()
+	if fn != nil {
+		fn(e)
+	}
+}
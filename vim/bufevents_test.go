@@ -0,0 +1,170 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestBufferEvents(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+	b := f.NewBuffer("test.txt", "a", "b")
+
+	events := make(chan vim.BufferEvent, 10)
+	if _, err := v.AttachBufferEvents(b, true, nil, func(e vim.BufferEvent) { events <- e }); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetBufferLines(b, 1, 2, true, [][]byte{[]byte("x")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.DetachBuffer(b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []vim.BufferEvent{
+		&vim.BufferLinesEvent{Buffer: b, ChangedTick: 1, FirstLine: 0, LastLine: -1, LineData: [][]byte{[]byte("a"), []byte("b")}},
+		&vim.BufferLinesEvent{Buffer: b, ChangedTick: 2, FirstLine: 1, LastLine: 2, LineData: [][]byte{[]byte("x")}},
+		&vim.DetachEvent{Buffer: b},
+	}
+	for i, w := range want {
+		select {
+		case e := <-events:
+			if !reflect.DeepEqual(e, w) {
+				t.Errorf("event %d = %+v, want %+v", i, e, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for event %d", i)
+		}
+	}
+}
+
+func TestBufferMirror(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+	b := f.NewBuffer("test.txt", "a", "b", "c")
+
+	m, err := vim.NewBufferMirror(v, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	check := func(want ...string) {
+		t.Helper()
+		tick, err := v.BufferChangedtick(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.WaitChangedTick(ctx, tick); err != nil {
+			t.Fatal(err)
+		}
+		lines, mtick := m.Snapshot()
+		if mtick != tick {
+			t.Errorf("changedtick = %d, want %d", mtick, tick)
+		}
+		var got []string
+		for _, l := range lines {
+			got = append(got, string(l))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("lines = %q, want %q", got, want)
+		}
+	}
+
+	check("a", "b", "c")
+	if err := v.SetBufferLines(b, 1, 2, true, [][]byte{[]byte("x"), []byte("y")}); err != nil {
+		t.Fatal(err)
+	}
+	check("a", "x", "y", "c")
+	if err := v.SetBufferLines(b, 0, -1, true, [][]byte{[]byte("z")}); err != nil {
+		t.Fatal(err)
+	}
+	check("z")
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WaitChangedTick(ctx, 1000); err != vim.ErrDetached {
+		t.Errorf("WaitChangedTick after Close returned %v, want ErrDetached", err)
+	}
+}
+
+func TestBufferEventOrder(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+	b := f.NewBuffer("test.txt", "a")
+
+	var (
+		mu     sync.Mutex
+		events []vim.BufferEvent
+		done   = make(chan struct{})
+	)
+	if _, err := v.AttachBufferEvents(b, false, nil, func(e vim.BufferEvent) {
+		// A slow function gives events for other methods a chance to run
+		// out of order.
+		time.Sleep(100 * time.Microsecond)
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+		if _, ok := e.(*vim.DetachEvent); ok {
+			close(done)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake sends notifications for subscribed events only.
+	for _, event := range []string{"nvim_buf_lines_event", "nvim_buf_changedtick_event", "nvim_buf_detach_event"} {
+		if err := v.Subscribe(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var want []vim.BufferEvent
+	for tick := 2; tick < 200; tick += 2 {
+		if err := f.Notify("nvim_buf_lines_event", b, tick, 0, 1, [][]byte{[]byte("x")}, false); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Notify("nvim_buf_changedtick_event", b, tick+1); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want,
+			&vim.BufferLinesEvent{Buffer: b, ChangedTick: tick, FirstLine: 0, LastLine: 1, LineData: [][]byte{[]byte("x")}},
+			&vim.ChangedTickEvent{Buffer: b, ChangedTick: tick + 1})
+	}
+	if err := f.Notify("nvim_buf_detach_event", b); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, &vim.DetachEvent{Buffer: b})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for detach event")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, want) {
+		for i := range events {
+			if i >= len(want) || !reflect.DeepEqual(events[i], want[i]) {
+				t.Fatalf("got %d events, event %d = %+v, want %d events in order sent", len(events), i, events[i], len(want))
+			}
+		}
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDetached is returned by BufferMirror.WaitChangedTick when Neovim stops
+// sending updates for the buffer.
+var ErrDetached = errors.New("nvim: buffer detached")
+
+// BufferMirror maintains a local copy of a buffer's lines. The mirror applies
+// the changes sent by Neovim to the copy, so following a buffer costs time
+// proportional to the size of the changes instead of the size of the buffer.
+type BufferMirror struct {
+	v      *Vim
+	buffer Buffer
+
+	mu          sync.Mutex
+	lines       [][]byte
+	changedTick int
+	detached    bool
+
+	// changed is closed and replaced when the mirror is updated.
+	changed chan struct{}
+
+	// pending holds the parts of a change split across multiple events.
+	pending []*BufferLinesEvent
+}
+
+// NewBufferMirror attaches to buffer b and returns a mirror of the buffer.
+// If b = 0, then the current buffer is used. The mirror is empty until the
+// buffer contents are received from Neovim. Use WaitChangedTick to wait for
+// the contents.
+func NewBufferMirror(v *Vim, b Buffer) (*BufferMirror, error) {
+	m := &BufferMirror{v: v, changed: make(chan struct{})}
+	var err error
+	m.buffer, err = v.AttachBufferEvents(b, true, nil, m.handleEvent)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Buffer returns the mirrored buffer.
+func (m *BufferMirror) Buffer() Buffer {
+	return m.buffer
+}
+
+// Snapshot returns the lines of the buffer and the value of b:changedtick
+// for the lines. The caller must not modify the contents of the returned
+// lines.
+func (m *BufferMirror) Snapshot() (lines [][]byte, changedTick int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([][]byte(nil), m.lines...), m.changedTick
+}
+
+// ChangedTick returns the value of b:changedtick for the mirrored lines.
+func (m *BufferMirror) ChangedTick() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.changedTick
+}
+
+// WaitChangedTick waits for the mirror to reach the value changedTick of
+// b:changedtick. Use WaitChangedTick after changing the buffer to wait for
+// the mirror to include the change.
+func (m *BufferMirror) WaitChangedTick(ctx context.Context, changedTick int) error {
+	for {
+		m.mu.Lock()
+		current, detached, changed := m.changedTick, m.detached, m.changed
+		m.mu.Unlock()
+		switch {
+		case current >= changedTick:
+			return nil
+		case detached:
+			return ErrDetached
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close stops the updates to the mirror.
+func (m *BufferMirror) Close() error {
+	_, err := m.v.DetachBuffer(m.buffer)
+	return err
+}
+
+func (m *BufferMirror) handleEvent(e BufferEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e := e.(type) {
+	case *BufferLinesEvent:
+		m.pending = append(m.pending, e)
+		if e.More {
+			return
+		}
+		for _, e := range m.pending {
+			m.lines = replaceLines(m.lines, e.FirstLine, e.LastLine, e.LineData)
+			if e.ChangedTick != 0 {
+				m.changedTick = e.ChangedTick
+			}
+		}
+		m.pending = nil
+	case *ChangedTickEvent:
+		m.changedTick = e.ChangedTick
+	case *DetachEvent:
+		m.detached = true
+	}
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+// replaceLines replaces lines[first:last] with data. A negative last is the
+// end of lines.
+func replaceLines(lines [][]byte, first, last int, data [][]byte) [][]byte {
+	if last < 0 || last > len(lines) {
+		last = len(lines)
+	}
+	if first > last {
+		first = last
+	}
+	n := len(lines) - (last - first) + len(data)
+	if n > cap(lines) {
+		result := make([][]byte, n, n+n/4)
+		copy(result, lines[:first])
+		copy(result[first:], data)
+		copy(result[first+len(data):], lines[last:])
+		return result
+	}
+	tail := lines[last:]
+	old := len(lines)
+	if n > old {
+		lines = lines[:n]
+	}
+	copy(lines[first+len(data):], tail)
+	copy(lines[first:], data)
+	for i := n; i < old; i++ {
+		// Release the removed lines.
+		lines[i] = nil
+	}
+	return lines[:n]
+}
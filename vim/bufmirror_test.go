@@ -0,0 +1,52 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func splitLines(s string) [][]byte {
+	if s == "" {
+		return [][]byte{}
+	}
+	var lines [][]byte
+	for _, l := range strings.Split(s, ",") {
+		lines = append(lines, []byte(l))
+	}
+	return lines
+}
+
+var replaceLinesTests = []struct {
+	lines       string
+	first, last int
+	data        string
+	want        string
+}{
+	{"", 0, -1, "a,b,c", "a,b,c"},
+	{"a,b,c", 0, -1, "x", "x"},
+	{"a,b,c", 1, 2, "x", "a,x,c"},
+	{"a,b,c", 1, 2, "x,y,z", "a,x,y,z,c"},
+	{"a,b,c", 1, 1, "x", "a,x,b,c"},
+	{"a,b,c", 0, 2, "", "c"},
+	{"a,b,c", 3, 3, "d", "a,b,c,d"},
+	{"a,b,c,d,e", 1, 4, "x", "a,x,e"},
+}
+
+func TestReplaceLines(t *testing.T) {
+	for _, tt := range replaceLinesTests {
+		// Test with and without spare capacity.
+		for _, extra := range []int{0, 10} {
+			lines := splitLines(tt.lines)
+			lines = append(make([][]byte, 0, len(lines)+extra), lines...)
+			got := replaceLines(lines, tt.first, tt.last, splitLines(tt.data))
+			if want := splitLines(tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("replaceLines(%q, %d, %d, %q) = %q, want %q", tt.lines, tt.first, tt.last, tt.data, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,493 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Callback is a Go function bound to a keymap, user command or autocmd. Use
+// the Delete method to remove the keymap, user command or autocmd and to
+// release the function.
+//
+// Callbacks for a buffer are released when Neovim wipes out the buffer.
+type Callback struct {
+	v      *Vim
+	method string
+	buffer Buffer
+
+	// remove adds the calls that remove the trampoline from Neovim.
+	remove func(p *Pipeline)
+}
+
+// Delete removes the keymap, user command or autocmd and releases the
+// function. Delete does nothing if the callback is released.
+func (c *Callback) Delete() error {
+	wipeout, ok := c.v.releaseCallback(c)
+	if !ok {
+		return nil
+	}
+	p := c.v.NewPipeline()
+	c.remove(p)
+	if wipeout != 0 {
+		p.DeleteAutocmd(wipeout)
+	}
+	return p.Wait()
+}
+
+// KeymapOptions specifies options for SetKeymap.
+type KeymapOptions struct {
+	// Buffer is the buffer for a buffer-local mapping. If Buffer is zero,
+	// then the mapping is global.
+	Buffer Buffer
+
+	// Silent specifies that the mapping is not echoed on the command line.
+	Silent bool
+
+	// Nowait specifies that Neovim does not wait for more characters when
+	// lhs is a prefix of a longer mapping.
+	Nowait bool
+
+	// Desc is a description of the mapping.
+	Desc string
+}
+
+// SetKeymap maps lhs in the given mode to a call to fn. The mode is a mode
+// short-name as in the map commands, for example "n" or "i". The mapping
+// calls fn with rpcrequest. An error returned from fn is reported as an error
+// in Neovim.
+//
+//	:help nvim_set_keymap()
+func (v *Vim) SetKeymap(mode, lhs string, opts *KeymapOptions, fn func(v *Vim) error) (*Callback, error) {
+	if opts == nil {
+		opts = &KeymapOptions{}
+	}
+	m := map[string]interface{}{"noremap": true}
+	if opts.Silent {
+		m["silent"] = true
+	}
+	if opts.Nowait {
+		m["nowait"] = true
+	}
+	if opts.Desc != "" {
+		m["desc"] = opts.Desc
+	}
+	b := opts.Buffer
+	return v.bindCallback("keymap", b, fn, func(c *Callback, chanID int) error {
+		rhs := fmt.Sprintf("<Cmd>call rpcrequest(%d, '%s')<CR>", chanID, c.method)
+		if b != 0 {
+			c.remove = func(p *Pipeline) { p.DeleteBufferKeymap(b, mode, lhs) }
+			return v.SetBufferKeymap(b, mode, lhs, rhs, m)
+		}
+		c.remove = func(p *Pipeline) { p.DeleteKeymap(mode, lhs) }
+		return v.SetRawKeymap(mode, lhs, rhs, m)
+	})
+}
+
+// UserCommandOptions specifies options for CreateUserCommand.
+type UserCommandOptions struct {
+	// Buffer is the buffer for a buffer-local command. If Buffer is zero,
+	// then the command is global.
+	Buffer Buffer
+
+	// NArgs specifies the number of command arguments: "0", "1", "*", "?"
+	// or "+". The default is "0".
+	//
+	//  :help :command-nargs
+	NArgs string
+
+	// Range specifies that the command accepts a range.
+	//
+	//  .   Range allowed, default is current line
+	//  %   Range allowed, default is whole file (1,$)
+	//  N   A count (default N) which is specified in the line
+	//      number position
+	//
+	//  :help :command-range
+	Range string
+
+	// Count specifies that the command accepts a count with default N.
+	//
+	//  :help :command-count
+	Count string
+
+	// Addr specifies the domain for the range option.
+	//
+	//  :help :command-addr
+	Addr string
+
+	// Bang specifies that the command can take a ! modifier.
+	Bang bool
+
+	// Register specifies that the first argument to the command can be an
+	// optional register name.
+	Register bool
+
+	// Bar specifies that the command can be followed by a "|" and another
+	// command.
+	Bar bool
+
+	// Complete specifies command completion.
+	//
+	//  :help :command-complete
+	Complete string
+
+	// Desc is a description of the command.
+	Desc string
+}
+
+// CommandArgs are the arguments to a user command created with
+// CreateUserCommand.
+type CommandArgs struct {
+	// Args is the argument string.
+	Args string `msgpack:"args"`
+
+	// FArgs are the arguments split by unescaped whitespace.
+	FArgs []string `msgpack:"fargs"`
+
+	// Bang is true if the command was executed with a ! modifier.
+	Bang bool `msgpack:"bang"`
+
+	// Line1 and Line2 are the start and end of the range.
+	Line1 int `msgpack:"line1"`
+	Line2 int `msgpack:"line2"`
+
+	// Range is the number of items in the range: 0, 1 or 2.
+	Range int `msgpack:"range"`
+
+	// Count is the count.
+	Count int `msgpack:"count"`
+
+	// Register is the optional register.
+	Register string `msgpack:"reg"`
+
+	// Mods are the command modifiers, for example "vertical".
+	Mods string `msgpack:"mods"`
+}
+
+// commandArgs is the Vimscript expression for CommandArgs.
+const commandArgs = `{'args': <q-args>, 'fargs': [<f-args>], 'bang': <q-bang> ==# '!' ? v:true : v:false, 'line1': <line1>, 'line2': <line2>, 'range': <range>, 'count': <count>, 'reg': <q-reg>, 'mods': <q-mods>}`
+
+// CreateUserCommand creates a user command that calls fn. The name must
+// start with an uppercase letter. The command calls fn with rpcrequest. An
+// error returned from fn is reported as an error in Neovim.
+//
+//	:help nvim_create_user_command()
+func (v *Vim) CreateUserCommand(name string, opts *UserCommandOptions, fn func(v *Vim, args *CommandArgs) error) (*Callback, error) {
+	if opts == nil {
+		opts = &UserCommandOptions{}
+	}
+	m := make(map[string]interface{})
+	if opts.NArgs != "" {
+		m["nargs"] = opts.NArgs
+	}
+	if opts.Range != "" {
+		r, err := commandRange(opts.Range)
+		if err != nil {
+			return nil, err
+		}
+		m["range"] = r
+	} else if opts.Count != "" {
+		n, err := strconv.Atoi(opts.Count)
+		if err != nil {
+			return nil, fmt.Errorf("nvim: invalid command count %q", opts.Count)
+		}
+		m["count"] = n
+	}
+	if opts.Addr != "" {
+		m["addr"] = opts.Addr
+	}
+	if opts.Bang {
+		m["bang"] = true
+	}
+	if opts.Register {
+		m["register"] = true
+	}
+	if opts.Bar {
+		m["bar"] = true
+	}
+	if opts.Complete != "" {
+		m["complete"] = opts.Complete
+	}
+	if opts.Desc != "" {
+		m["desc"] = opts.Desc
+	}
+	b := opts.Buffer
+	return v.bindCallback("command", b, fn, func(c *Callback, chanID int) error {
+		command := fmt.Sprintf("call rpcrequest(%d, '%s', %s)", chanID, c.method, commandArgs)
+		if b != 0 {
+			c.remove = func(p *Pipeline) { p.DeleteBufferUserCommand(b, name) }
+			return v.CreateBufferUserCommand(b, name, command, m)
+		}
+		c.remove = func(p *Pipeline) { p.DeleteUserCommand(name) }
+		return v.CreateRawUserCommand(name, command, m)
+	})
+}
+
+// commandRange converts the Range option to the value used by the API.
+func commandRange(r string) (interface{}, error) {
+	switch r {
+	case ".":
+		return true, nil
+	case "%":
+		return r, nil
+	}
+	n, err := strconv.Atoi(r)
+	if err != nil {
+		return nil, fmt.Errorf("nvim: invalid command range %q", r)
+	}
+	return n, nil
+}
+
+// AutocmdOptions specifies options for CreateAutocmd.
+type AutocmdOptions struct {
+	// Group is the name of the autocmd group. The group must exist.
+	Group string
+
+	// Pattern are the patterns matched against the file name or other
+	// event-specific value. Pattern cannot be used with Buffer.
+	//
+	//  :help autocmd-pattern
+	Pattern []string
+
+	// Buffer is the buffer for a buffer-local autocmd.
+	//
+	//  :help autocmd-buflocal
+	Buffer Buffer
+
+	// Once specifies that the autocmd runs once. The function is released
+	// after the autocmd runs.
+	Once bool
+
+	// Nested specifies that the autocmd triggers other autocmds.
+	//
+	//  :help autocmd-nested
+	Nested bool
+
+	// Desc is a description of the autocmd.
+	Desc string
+}
+
+// AutocmdEvent describes the event that triggered an autocmd created with
+// CreateAutocmd.
+type AutocmdEvent struct {
+	// Buffer is the value of <abuf>.
+	Buffer Buffer
+
+	// File is the value of <afile>.
+	File string
+
+	// Match is the value of <amatch>.
+	Match string
+}
+
+// autocmdArgs is the argument sent by the autocmd trampoline. Vimscript
+// cannot create a Buffer value, so the trampoline sends the buffer number.
+type autocmdArgs struct {
+	Buffer int    `msgpack:"buf"`
+	File   string `msgpack:"file"`
+	Match  string `msgpack:"match"`
+}
+
+// autocmdArgsExpr is the Vimscript expression for autocmdArgs.
+const autocmdArgsExpr = `{'buf': str2nr(expand('<abuf>')), 'file': expand('<afile>'), 'match': expand('<amatch>')}`
+
+// CreateAutocmd creates an autocmd for the events that calls fn. The
+// autocmd calls fn with rpcrequest, so Neovim waits for fn to return before
+// continuing with the event. An error returned from fn is reported as an
+// error in Neovim.
+//
+//	:help nvim_create_autocmd()
+func (v *Vim) CreateAutocmd(events []string, opts *AutocmdOptions, fn func(v *Vim, ev *AutocmdEvent) error) (*Callback, error) {
+	if opts == nil {
+		opts = &AutocmdOptions{}
+	}
+	m := make(map[string]interface{})
+	if opts.Group != "" {
+		m["group"] = opts.Group
+	}
+	if len(opts.Pattern) > 0 {
+		m["pattern"] = opts.Pattern
+	}
+	if opts.Buffer != 0 {
+		m["buffer"] = int(opts.Buffer)
+	}
+	if opts.Once {
+		m["once"] = true
+	}
+	if opts.Nested {
+		m["nested"] = true
+	}
+	if opts.Desc != "" {
+		m["desc"] = opts.Desc
+	}
+	var c *Callback
+	once := opts.Once
+	handler := func(v *Vim, args *autocmdArgs) error {
+		if once {
+			// Neovim deleted the autocmd before running the command.
+			if wipeout, ok := v.releaseCallback(c); ok && wipeout != 0 {
+				if err := v.DeleteAutocmd(wipeout); err != nil {
+					return err
+				}
+			}
+		}
+		return fn(v, &AutocmdEvent{Buffer: Buffer(args.Buffer), File: args.File, Match: args.Match})
+	}
+	return v.bindCallback("autocmd", opts.Buffer, handler, func(cb *Callback, chanID int) error {
+		c = cb
+		m["command"] = fmt.Sprintf("call rpcrequest(%d, '%s', %s)", chanID, c.method, autocmdArgsExpr)
+		id, err := v.CreateRawAutocmd(events, m)
+		c.remove = func(p *Pipeline) { p.DeleteAutocmd(id) }
+		return err
+	})
+}
+
+// bindCallback registers fn as the handler for a new callback and calls
+// create to create the trampoline in Neovim. The create function sets the
+// callback's remove function. The calls to Neovim are made without holding
+// callbackMu.
+func (v *Vim) bindCallback(kind string, b Buffer, fn interface{}, create func(c *Callback, chanID int) error) (*Callback, error) {
+	chanID, err := v.ChannelID()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := v.addCallback(kind, b, fn)
+	if err != nil {
+		return nil, err
+	}
+	if err := create(c, chanID); err != nil {
+		v.releaseCallback(c)
+		return nil, err
+	}
+	if !v.claimWipeout(c) {
+		return c, nil
+	}
+	id, err := v.watchWipeout(b, chanID)
+	if err != nil {
+		c.Delete()
+		return nil, err
+	}
+	if !v.setWipeout(b, id) {
+		// The callbacks for the buffer were released while the autocmd
+		// was created.
+		v.DeleteAutocmd(id)
+	}
+	return c, nil
+}
+
+// addCallback registers fn as the handler for a new callback.
+func (v *Vim) addCallback(kind string, b Buffer, fn interface{}) (*Callback, error) {
+	v.callbackMu.Lock()
+	defer v.callbackMu.Unlock()
+	v.nextCallback++
+	c := &Callback{v: v, method: fmt.Sprintf("nvim_go:%s:%d", kind, v.nextCallback), buffer: b}
+	if err := v.ep.RegisterHandler(c.method, fn); err != nil {
+		return nil, err
+	}
+	if v.callbacks == nil {
+		v.callbacks = make(map[*Callback]bool)
+	}
+	v.callbacks[c] = true
+	return c, nil
+}
+
+// claimWipeout returns true if the caller must create the wipeout autocmd
+// for the buffer of callback c.
+func (v *Vim) claimWipeout(c *Callback) bool {
+	v.callbackMu.Lock()
+	defer v.callbackMu.Unlock()
+	if c.buffer == 0 || !v.callbacks[c] {
+		return false
+	}
+	if _, ok := v.wipeouts[c.buffer]; ok {
+		return false
+	}
+	if v.wipeouts == nil {
+		v.wipeouts = make(map[Buffer]int)
+	}
+	v.wipeouts[c.buffer] = 0
+	return true
+}
+
+// setWipeout records the id of the wipeout autocmd for buffer b. The ok
+// result is false if the callbacks for the buffer were released.
+func (v *Vim) setWipeout(b Buffer, id int) (ok bool) {
+	v.callbackMu.Lock()
+	defer v.callbackMu.Unlock()
+	if _, ok := v.wipeouts[b]; !ok {
+		return false
+	}
+	v.wipeouts[b] = id
+	return true
+}
+
+// wipeoutMethod is the RPC method called when Neovim wipes out a buffer with
+// callbacks.
+const wipeoutMethod = "nvim_go:wipeout"
+
+// watchWipeout creates an autocmd that releases the callbacks for buffer b
+// when Neovim wipes out the buffer.
+func (v *Vim) watchWipeout(b Buffer, chanID int) (int, error) {
+	v.callbackMu.Lock()
+	if !v.wipeoutHandler {
+		err := v.ep.RegisterHandler(wipeoutMethod, func(v *Vim, b int) {
+			v.releaseBuffer(Buffer(b))
+		})
+		if err != nil {
+			v.callbackMu.Unlock()
+			return 0, err
+		}
+		v.wipeoutHandler = true
+	}
+	v.callbackMu.Unlock()
+
+	return v.CreateRawAutocmd([]string{"BufWipeout"}, map[string]interface{}{
+		"buffer":  int(b),
+		"once":    true,
+		"command": fmt.Sprintf("call rpcnotify(%d, '%s', %d)", chanID, wipeoutMethod, b),
+	})
+}
+
+// releaseCallback releases the function for c. If c is the last callback for
+// its buffer, then releaseCallback returns the id of the buffer's wipeout
+// autocmd for the caller to delete. The ok result is false if c was already
+// released. The id is zero if the autocmd is not created yet.
+func (v *Vim) releaseCallback(c *Callback) (wipeout int, ok bool) {
+	v.callbackMu.Lock()
+	defer v.callbackMu.Unlock()
+	if !v.callbacks[c] {
+		return 0, false
+	}
+	delete(v.callbacks, c)
+	v.ep.UnregisterHandler(c.method)
+	if c.buffer == 0 {
+		return 0, true
+	}
+	for x := range v.callbacks {
+		if x.buffer == c.buffer {
+			return 0, true
+		}
+	}
+	wipeout = v.wipeouts[c.buffer]
+	delete(v.wipeouts, c.buffer)
+	return wipeout, true
+}
+
+// releaseBuffer releases the callbacks for buffer b. Neovim deletes the
+// buffer's keymaps, user commands and autocmds when it wipes out the buffer.
+func (v *Vim) releaseBuffer(b Buffer) {
+	v.callbackMu.Lock()
+	defer v.callbackMu.Unlock()
+	for c := range v.callbacks {
+		if c.buffer == b {
+			delete(v.callbacks, c)
+			v.ep.UnregisterHandler(c.method)
+		}
+	}
+	delete(v.wipeouts, b)
+}
@@ -0,0 +1,213 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+var trampolinePattern = regexp.MustCompile(`rpc(request|notify)\(\d+, '([^']+)'`)
+
+// trampoline returns the RPC method called by a keymap, user command or
+// autocmd created by package vim.
+func trampoline(t *testing.T, command string) string {
+	t.Helper()
+	m := trampolinePattern.FindStringSubmatch(command)
+	if m == nil {
+		t.Fatalf("%q does not call rpcrequest or rpcnotify", command)
+	}
+	return m[2]
+}
+
+func TestCallbacks(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b := f.NewBuffer("main.go")
+
+	// Keymap.
+
+	pressed := 0
+	keymap, err := v.SetKeymap("n", "<leader>x", &vim.KeymapOptions{Buffer: b, Silent: true}, func(v *vim.Vim) error {
+		pressed++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rhs, ok := f.Keymap(b, "n", "<leader>x")
+	if !ok || !strings.HasPrefix(rhs, "<Cmd>call rpcrequest(") {
+		t.Fatalf("Keymap() = %q, %v, want rpcrequest mapping", rhs, ok)
+	}
+	keymapMethod := trampoline(t, rhs)
+	if err := f.Call(keymapMethod, nil); err != nil {
+		t.Fatal(err)
+	}
+	if pressed != 1 {
+		t.Errorf("keymap function called %d times, want 1", pressed)
+	}
+
+	// User command.
+
+	var got *vim.CommandArgs
+	command, err := v.CreateUserCommand("Hello", &vim.UserCommandOptions{NArgs: "*", Bang: true}, func(v *vim.Vim, args *vim.CommandArgs) error {
+		got = args
+		return errors.New("hello failed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replacement, ok := f.UserCommand(0, "Hello")
+	if !ok || !strings.Contains(replacement, "[<f-args>]") {
+		t.Fatalf("UserCommand() = %q, %v, want command with f-args", replacement, ok)
+	}
+	want := &vim.CommandArgs{Args: "a b", FArgs: []string{"a", "b"}, Bang: true, Line1: 1, Line2: 1, Count: -1}
+	err = f.Call(trampoline(t, replacement), nil, map[string]interface{}{
+		"args": "a b", "fargs": []string{"a", "b"}, "bang": true,
+		"line1": 1, "line2": 1, "range": 0, "count": -1, "reg": "", "mods": "",
+	})
+	if err == nil || !strings.Contains(err.Error(), "hello failed") {
+		t.Errorf("command returned %v, want hello failed", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("command args = %+v, want %+v", got, want)
+	}
+	if _, err := v.CreateUserCommand("Bad", &vim.UserCommandOptions{Range: "x"}, func(*vim.Vim, *vim.CommandArgs) error { return nil }); err == nil {
+		t.Error("CreateUserCommand with invalid range did not return error")
+	}
+
+	// Once autocmd.
+
+	var events []vim.AutocmdEvent
+	_, err = v.CreateAutocmd([]string{"BufWritePre"}, &vim.AutocmdOptions{Buffer: b, Once: true}, func(v *vim.Vim, ev *vim.AutocmdEvent) error {
+		events = append(events, *ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	autocmds := f.Autocmds("BufWritePre")
+	if len(autocmds) != 1 || autocmds[0].Buffer != b || !autocmds[0].Once {
+		t.Fatalf("Autocmds(BufWritePre) = %+v, want once autocmd for buffer %v", autocmds, b)
+	}
+	autocmdMethod := trampoline(t, autocmds[0].Command)
+	ev := map[string]interface{}{"buf": int(b), "file": "main.go", "match": "/main.go"}
+	if err := f.Call(autocmdMethod, nil, ev); err != nil {
+		t.Fatal(err)
+	}
+	if want := []vim.AutocmdEvent{{Buffer: b, File: "main.go", Match: "/main.go"}}; !reflect.DeepEqual(events, want) {
+		t.Errorf("autocmd events = %+v, want %+v", events, want)
+	}
+	if err := f.Call(autocmdMethod, nil, ev); err == nil {
+		t.Error("once autocmd function not released after first call")
+	}
+
+	// Delete.
+
+	if err := command.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.UserCommand(0, "Hello"); ok {
+		t.Error("user command not deleted")
+	}
+	if err := command.Delete(); err != nil {
+		t.Errorf("second Delete() returned %v", err)
+	}
+
+	// Wipeout releases the callbacks for the buffer.
+
+	wipeouts := f.Autocmds("BufWipeout")
+	if len(wipeouts) != 1 || wipeouts[0].Buffer != b {
+		t.Fatalf("Autocmds(BufWipeout) = %+v, want one autocmd for buffer %v", wipeouts, b)
+	}
+	if err := f.Call(trampoline(t, wipeouts[0].Command), nil, int(b)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Call(keymapMethod, nil); err == nil {
+		t.Error("keymap function not released after wipeout")
+	}
+	if err := keymap.Delete(); err != nil {
+		t.Errorf("Delete() after wipeout returned %v", err)
+	}
+	if _, ok := f.Keymap(b, "n", "<leader>x"); !ok {
+		t.Error("Delete() after wipeout called Neovim")
+	}
+}
+
+func TestGlobalCallbacks(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	pressed := 0
+	keymap, err := v.SetKeymap("n", "<leader>g", nil, func(v *vim.Vim) error {
+		pressed++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rhs, ok := f.Keymap(0, "n", "<leader>g")
+	if !ok {
+		t.Fatal("global keymap not set")
+	}
+	if err := f.Call(trampoline(t, rhs), nil); err != nil {
+		t.Fatal(err)
+	}
+	if pressed != 1 {
+		t.Errorf("keymap function called %d times, want 1", pressed)
+	}
+
+	var events []vim.AutocmdEvent
+	autocmd, err := v.CreateAutocmd([]string{"BufEnter"}, &vim.AutocmdOptions{Pattern: []string{"*.go"}}, func(v *vim.Vim, ev *vim.AutocmdEvent) error {
+		events = append(events, *ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	autocmds := f.Autocmds("BufEnter")
+	if len(autocmds) != 1 || autocmds[0].Buffer != 0 || !reflect.DeepEqual(autocmds[0].Pattern, []string{"*.go"}) || autocmds[0].Once {
+		t.Fatalf("Autocmds(BufEnter) = %+v, want global autocmd for *.go", autocmds)
+	}
+	autocmdMethod := trampoline(t, autocmds[0].Command)
+	ev := map[string]interface{}{"buf": 3, "file": "x.go", "match": "/x.go"}
+	for i := 0; i < 2; i++ {
+		if err := f.Call(autocmdMethod, nil, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(events) != 2 || events[0] != (vim.AutocmdEvent{Buffer: 3, File: "x.go", Match: "/x.go"}) {
+		t.Errorf("autocmd events = %+v, want two events for x.go", events)
+	}
+
+	// Global callbacks are not released on buffer wipeout.
+	if wipeouts := f.Autocmds("BufWipeout"); len(wipeouts) != 0 {
+		t.Errorf("Autocmds(BufWipeout) = %+v, want none for global callbacks", wipeouts)
+	}
+
+	if err := keymap.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.Keymap(0, "n", "<leader>g"); ok {
+		t.Error("global keymap not deleted")
+	}
+	if err := autocmd.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if autocmds := f.Autocmds("BufEnter"); len(autocmds) != 0 {
+		t.Errorf("Autocmds(BufEnter) after Delete = %+v, want none", autocmds)
+	}
+	if err := f.Call(autocmdMethod, nil, ev); err == nil {
+		t.Error("autocmd function not released after Delete")
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestContextMethods(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b := f.NewBuffer("", "a", "b")
+	lines, err := v.BufferLinesContext(context.Background(), b, 0, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]byte{[]byte("a"), []byte("b")}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("BufferLinesContext() = %q, want %q", lines, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := v.CommandContext(ctx, "set nowrap"); err != context.Canceled {
+		t.Errorf("CommandContext(cancelled) returned %v, want %v", err, context.Canceled)
+	}
+	if cmds := f.Commands(); len(cmds) != 0 {
+		t.Errorf("cancelled command was sent: %q", cmds)
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package diag publishes diagnostics, such as compiler errors and lint
+// warnings, to Neovim buffers.
+//
+// A Publisher renders the diagnostics for a buffer as highlights, signs and
+// virtual text using extmarks in the publisher's namespace. The publisher can
+// also set the quickfix list or the location lists of the windows showing a
+// buffer. Publishing a new set of diagnostics for a buffer applies the
+// difference from the previous set for the buffer.
+package diag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity int
+
+const (
+	Error Severity = iota + 1
+	Warning
+	Info
+	Hint
+)
+
+var severityNames = map[Severity]string{
+	Error:   "Error",
+	Warning: "Warn",
+	Info:    "Info",
+	Hint:    "Hint",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// quickfixType returns the quickfix error type for the severity.
+func (s Severity) quickfixType() string {
+	switch s {
+	case Error:
+		return "E"
+	case Warning:
+		return "W"
+	case Info:
+		return "I"
+	default:
+		return "N"
+	}
+}
+
+// Diagnostic is a message about a range of text in a buffer.
+type Diagnostic struct {
+	// Row and Col are the zero-based start of the range. Col is a byte
+	// offset.
+	Row int
+	Col int
+
+	// EndRow and EndCol are the zero-based, exclusive end of the range. The
+	// range is empty when the end is not after the start.
+	EndRow int
+	EndCol int
+
+	Severity Severity
+	Message  string
+
+	// Source is the name of the tool that reported the diagnostic, for
+	// example "vet".
+	Source string
+}
+
+func (d *Diagnostic) hasRange() bool {
+	return d.EndRow > d.Row || (d.EndRow == d.Row && d.EndCol > d.Col)
+}
+
+// text returns the first line of the message with the source.
+func (d *Diagnostic) text() string {
+	msg := d.Message
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		msg = msg[:i]
+	}
+	if d.Source != "" {
+		msg = d.Source + ": " + msg
+	}
+	return msg
+}
+
+// Options specifies how a publisher renders diagnostics.
+type Options struct {
+	// Highlights specifies that the range of a diagnostic is highlighted
+	// with the DiagnosticUnderline* highlight groups.
+	Highlights bool
+
+	// Signs specifies that a sign is shown for a diagnostic. The sign uses
+	// the DiagnosticSign* highlight groups.
+	Signs bool
+
+	// VirtualText specifies that the message of a diagnostic is shown at
+	// the end of the line. The text uses the DiagnosticVirtualText*
+	// highlight groups.
+	VirtualText bool
+
+	// Quickfix specifies that the quickfix list is set to the diagnostics
+	// for all buffers.
+	Quickfix bool
+
+	// LocationList specifies that the location list of each window showing
+	// a buffer in the current tabpage is set to the diagnostics for the
+	// buffer.
+	LocationList bool
+}
+
+// DefaultOptions are the options used when NewPublisher is called with nil
+// options.
+var DefaultOptions = Options{Highlights: true, Signs: true, VirtualText: true}
+
+// Publisher publishes diagnostics to Neovim buffers.
+type Publisher struct {
+	v       *vim.Vim
+	name    string
+	nsID    int
+	options Options
+
+	mu sync.Mutex
+
+	// published maps buffers to the extmark ids for the published
+	// diagnostics.
+	published map[vim.Buffer]map[Diagnostic]int
+
+	// stale maps buffers to the ids of extmarks that were not deleted
+	// because of an error. The next publish for the buffer deletes the
+	// extmarks.
+	stale map[vim.Buffer][]int
+
+	// qfID is the id of the publisher's quickfix list and locIDs maps
+	// windows to the ids of the publisher's location lists. The publisher
+	// creates a list on first use and updates the list after that so that
+	// the lists created by the user are not replaced.
+	qfID   int
+	locIDs map[vim.Window]int
+}
+
+// NewPublisher creates a publisher. The name of the publisher is used for
+// the extmark namespace and the title of quickfix and location lists.
+func NewPublisher(v *vim.Vim, name string, options *Options) (*Publisher, error) {
+	if options == nil {
+		options = &DefaultOptions
+	}
+	nsID, err := v.CreateNamespace(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{
+		v:         v,
+		name:      name,
+		nsID:      nsID,
+		options:   *options,
+		published: make(map[vim.Buffer]map[Diagnostic]int),
+		stale:     make(map[vim.Buffer][]int),
+		locIDs:    make(map[vim.Window]int),
+	}, nil
+}
+
+// NamespaceID returns the id of the namespace for the publisher's extmarks.
+func (p *Publisher) NamespaceID() int {
+	return p.nsID
+}
+
+// Diagnostics returns the published diagnostics for buffer b sorted by
+// position.
+func (p *Publisher) Diagnostics(b vim.Buffer) []Diagnostic {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return sortedDiagnostics(p.published[b])
+}
+
+// Publish replaces the diagnostics for buffer b with diags. Publish removes
+// the extmarks for the diagnostics not in diags and adds the extmarks for the
+// new diagnostics. Duplicate diagnostics are published once.
+func (p *Publisher) Publish(b vim.Buffer, diags []Diagnostic) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	old := p.published[b]
+	next := make(map[Diagnostic]int, len(diags))
+	for _, d := range diags {
+		next[d] = 0
+	}
+
+	pl := p.v.NewPipeline()
+	changed := false
+	deleted := make(map[int]*bool)
+	del := func(id int) {
+		ok := new(bool)
+		deleted[id] = ok
+		pl.DeleteBufferExtmark(b, p.nsID, id, ok)
+	}
+	for _, id := range p.stale[b] {
+		changed = true
+		del(id)
+	}
+	for d, id := range old {
+		if _, ok := next[d]; ok {
+			next[d] = id
+			continue
+		}
+		changed = true
+		if id != 0 {
+			del(id)
+		}
+	}
+	ids := make(map[Diagnostic]*int)
+	for d := range next {
+		if _, ok := old[d]; ok {
+			continue
+		}
+		changed = true
+		if opts := p.extmarkOptions(&d); opts != nil {
+			id := new(int)
+			ids[d] = id
+			pl.SetBufferExtmark(b, p.nsID, d.Row, d.Col, opts, id)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	err := pl.Wait()
+	delete(p.stale, b)
+	if err != nil {
+		// Retry the deletes that did not complete on the next publish. A
+		// delete that returns false without an error is not retried because
+		// the extmark does not exist.
+		for id, ok := range deleted {
+			if !*ok {
+				p.stale[b] = append(p.stale[b], id)
+			}
+		}
+	}
+	for d, id := range ids {
+		if *id == 0 {
+			// The extmark was not created. Try again on the next publish.
+			delete(next, d)
+			continue
+		}
+		next[d] = *id
+	}
+	if len(next) == 0 {
+		delete(p.published, b)
+	} else {
+		p.published[b] = next
+	}
+	if err != nil {
+		return err
+	}
+	return p.updateLists(b)
+}
+
+// Clear removes the diagnostics for buffer b.
+func (p *Publisher) Clear(b vim.Buffer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, published := p.published[b]
+	_, stale := p.stale[b]
+	if !published && !stale {
+		return nil
+	}
+	delete(p.published, b)
+	delete(p.stale, b)
+	if err := p.v.ClearBufferNamespace(b, p.nsID, 0, -1); err != nil {
+		return err
+	}
+	return p.updateLists(b)
+}
+
+// extmarkOptions returns the options for the extmark that renders d or nil
+// if d is not rendered with an extmark.
+func (p *Publisher) extmarkOptions(d *Diagnostic) *vim.ExtmarkOptions {
+	name := d.Severity.String()
+	opts := &vim.ExtmarkOptions{Strict: new(bool)}
+	rendered := false
+	if p.options.Highlights && d.hasRange() {
+		endRow, endCol := d.EndRow, d.EndCol
+		opts.EndRow, opts.EndCol = &endRow, &endCol
+		opts.HighlightGroup = "DiagnosticUnderline" + name
+		rendered = true
+	}
+	if p.options.Signs {
+		opts.SignText = d.Severity.quickfixType()
+		opts.SignHighlightGroup = "DiagnosticSign" + name
+		rendered = true
+	}
+	if p.options.VirtualText {
+		opts.VirtText = []vim.VirtTextChunk{{Text: d.text(), HighlightGroup: "DiagnosticVirtualText" + name}}
+		opts.VirtTextPos = "eol"
+		rendered = true
+	}
+	if !rendered {
+		return nil
+	}
+	// Sort the more severe diagnostics on top.
+	opts.Priority = 100 - int(d.Severity)
+	return opts
+}
+
+func sortedDiagnostics(m map[Diagnostic]int) []Diagnostic {
+	diags := make([]Diagnostic, 0, len(m))
+	for d := range m {
+		diags = append(diags, d)
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		a, b := &diags[i], &diags[j]
+		switch {
+		case a.Row != b.Row:
+			return a.Row < b.Row
+		case a.Col != b.Col:
+			return a.Col < b.Col
+		case a.Severity != b.Severity:
+			return a.Severity < b.Severity
+		default:
+			return a.text() < b.text()
+		}
+	})
+	return diags
+}
+
+func quickfixItems(b vim.Buffer, diags []Diagnostic) []*vim.QuickfixError {
+	items := make([]*vim.QuickfixError, len(diags))
+	for i, d := range diags {
+		items[i] = &vim.QuickfixError{
+			Bufnr: int(b),
+			LNum:  d.Row + 1,
+			Col:   d.Col + 1,
+			Text:  d.text(),
+			Type:  d.Severity.quickfixType(),
+			Valid: 1,
+		}
+	}
+	return items
+}
+
+// updateLists updates the quickfix list and the location lists for the
+// windows showing buffer b.
+func (p *Publisher) updateLists(b vim.Buffer) error {
+	if p.options.Quickfix {
+		buffers := make([]vim.Buffer, 0, len(p.published))
+		for b := range p.published {
+			buffers = append(buffers, b)
+		}
+		sort.Slice(buffers, func(i, j int) bool { return buffers[i] < buffers[j] })
+		items := []*vim.QuickfixError{}
+		for _, b := range buffers {
+			items = append(items, quickfixItems(b, sortedDiagnostics(p.published[b]))...)
+		}
+		id, err := p.setList("qflist", nil, p.qfID, items)
+		if err != nil {
+			return err
+		}
+		p.qfID = id
+	}
+	if p.options.LocationList {
+		t, err := p.v.CurrentTabpage()
+		if err != nil {
+			return err
+		}
+		windows, err := p.v.TabpageWindows(t)
+		if err != nil {
+			return err
+		}
+		buffers := make([]vim.Buffer, len(windows))
+		pl := p.v.NewPipeline()
+		for i, w := range windows {
+			pl.WindowBuffer(w, &buffers[i])
+		}
+		if err := pl.Wait(); err != nil {
+			return err
+		}
+		items := quickfixItems(b, sortedDiagnostics(p.published[b]))
+		for i, w := range windows {
+			if buffers[i] != b {
+				continue
+			}
+			id, err := p.setList("loclist", []interface{}{w}, p.locIDs[w], items)
+			if err != nil {
+				return err
+			}
+			p.locIDs[w] = id
+		}
+	}
+	return nil
+}
+
+// setList sets the items in the publisher's quickfix list or location list
+// with the given id and returns the id of the list. The kind is "qflist" or
+// "loclist" and args are the arguments before the list in calls to the
+// set<kind> and get<kind> functions. If id is zero or the list no longer
+// exists, then setList creates a new list.
+//
+//	:help setqflist()
+func (p *Publisher) setList(kind string, args []interface{}, id int, items []*vim.QuickfixError) (int, error) {
+	listArgs := func(extra ...interface{}) []interface{} {
+		return append(append([]interface{}{}, args...), extra...)
+	}
+	what := map[string]interface{}{"title": p.name, "items": items}
+	if id != 0 {
+		what["id"] = id
+		var result int
+		if err := p.v.Call("set"+kind, &result, listArgs([]interface{}{}, "r", what)...); err != nil {
+			return 0, err
+		}
+		if result == 0 {
+			return id, nil
+		}
+		// The list was freed. Create a new list.
+		delete(what, "id")
+	}
+	var info struct {
+		ID int `msgpack:"id"`
+	}
+	pl := p.v.NewAtomicPipeline()
+	pl.Call("set"+kind, nil, listArgs([]interface{}{}, " ", what)...)
+	pl.Call("get"+kind, &info, listArgs(map[string]interface{}{"id": 0})...)
+	if err := pl.Wait(); err != nil {
+		return 0, err
+	}
+	return info.ID, nil
+}
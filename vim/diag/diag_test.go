@@ -0,0 +1,155 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+	"github.com/garyburd/neovim-go/vim/vimfake"
+)
+
+func TestPublish(t *testing.T) {
+	f, err := vimfake.New(t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	v := f.Vim()
+
+	var qflists, loclists [][]interface{}
+	f.StubFunction("setqflist", func(args []interface{}) (interface{}, error) {
+		qflists = append(qflists, args)
+		return 0, nil
+	})
+	f.StubFunction("setloclist", func(args []interface{}) (interface{}, error) {
+		loclists = append(loclists, args)
+		return 0, nil
+	})
+	f.StubFunction("getqflist", func(args []interface{}) (interface{}, error) {
+		return map[string]interface{}{"id": 7}, nil
+	})
+	f.StubFunction("getloclist", func(args []interface{}) (interface{}, error) {
+		return map[string]interface{}{"id": 8}, nil
+	})
+
+	b, err := v.CurrentBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetBufferLines(b, 0, -1, true, [][]byte{[]byte("package main"), []byte("func f() {}")}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPublisher(v, "test", &Options{Highlights: true, Signs: true, VirtualText: true, Quickfix: true, LocationList: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unused := Diagnostic{Row: 1, Col: 5, EndRow: 1, EndCol: 6, Severity: Warning, Message: "f is unused", Source: "vet"}
+	syntax := Diagnostic{Row: 0, Col: 0, Severity: Error, Message: "syntax error\ndetails"}
+	if err := p.Publish(b, []Diagnostic{unused, syntax, syntax}); err != nil {
+		t.Fatal(err)
+	}
+	if diags := p.Diagnostics(b); !reflect.DeepEqual(diags, []Diagnostic{syntax, unused}) {
+		t.Errorf("Diagnostics() = %+v, want syntax and unused", diags)
+	}
+
+	marks, err := v.BufferExtmarks(b, p.NamespaceID(), [2]int{0, 0}, [2]int{-1, -1}, &vim.ExtmarksOptions{Details: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marks) != 2 {
+		t.Fatalf("got %d extmarks, want 2", len(marks))
+	}
+	m := marks[1]
+	m.ID = 0
+	want := vim.Extmark{
+		Row:                1,
+		Col:                5,
+		EndRow:             1,
+		EndCol:             6,
+		NamespaceID:        p.NamespaceID(),
+		HighlightGroup:     "DiagnosticUnderlineWarn",
+		VirtText:           []vim.VirtTextChunk{{Text: "vet: f is unused", HighlightGroup: "DiagnosticVirtualTextWarn"}},
+		VirtTextPos:        "eol",
+		SignText:           "W",
+		SignHighlightGroup: "DiagnosticSignWarn",
+		Priority:           98,
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("extmark = %+v, want %+v", m, want)
+	}
+	if marks[0].HighlightGroup != "" || marks[0].EndRow != -1 || marks[0].VirtText[0].Text != "syntax error" {
+		t.Errorf("extmark for diagnostic without range = %+v", marks[0])
+	}
+	unusedID := marks[1].ID
+
+	if len(qflists) != 1 {
+		t.Fatalf("setqflist called %d times, want 1", len(qflists))
+	}
+	items := qflists[0][2].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 2 {
+		t.Errorf("quickfix list has %d items, want 2", len(items))
+	} else if item := items[1].(map[string]interface{}); item["lnum"] != int64(2) || item["col"] != int64(6) || item["type"] != "W" {
+		t.Errorf("quickfix item = %v, want line 2, col 6, type W", item)
+	}
+	if action := qflists[0][1]; action != " " {
+		t.Errorf("setqflist action = %q, want new list", action)
+	}
+	if len(loclists) != 1 {
+		t.Errorf("setloclist called %d times, want 1", len(loclists))
+	} else if action := loclists[0][2]; action != " " {
+		t.Errorf("setloclist action = %q, want new list", action)
+	}
+
+	// Publishing the same diagnostics does nothing.
+	if err := p.Publish(b, []Diagnostic{syntax, unused}); err != nil {
+		t.Fatal(err)
+	}
+	if len(qflists) != 1 {
+		t.Errorf("setqflist called for unchanged diagnostics")
+	}
+
+	// Republish with a fixed syntax error. The extmark for the unchanged
+	// diagnostic is kept.
+	if err := p.Publish(b, []Diagnostic{unused}); err != nil {
+		t.Fatal(err)
+	}
+	marks, err = v.BufferExtmarks(b, p.NamespaceID(), [2]int{0, 0}, [2]int{-1, -1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marks) != 1 || marks[0].ID != unusedID {
+		t.Errorf("extmarks after republish = %+v, want id %d", marks, unusedID)
+	}
+	if len(qflists) != 2 {
+		t.Fatalf("setqflist called %d times, want 2", len(qflists))
+	}
+	if what := qflists[1][2].(map[string]interface{}); qflists[1][1] != "r" || what["id"] != int64(7) {
+		t.Errorf("setqflist(%v) does not replace the publisher's list", qflists[1][1:])
+	}
+	if len(loclists) != 2 {
+		t.Fatalf("setloclist called %d times, want 2", len(loclists))
+	}
+	if what := loclists[1][3].(map[string]interface{}); loclists[1][2] != "r" || what["id"] != int64(8) {
+		t.Errorf("setloclist(%v) does not replace the publisher's list", loclists[1][1:])
+	}
+
+	if err := p.Clear(b); err != nil {
+		t.Fatal(err)
+	}
+	marks, err = v.BufferExtmarks(b, p.NamespaceID(), [2]int{0, 0}, [2]int{-1, -1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marks) != 0 {
+		t.Errorf("extmarks after clear = %+v, want none", marks)
+	}
+	if diags := p.Diagnostics(b); len(diags) != 0 {
+		t.Errorf("Diagnostics() after clear = %+v, want none", diags)
+	}
+}
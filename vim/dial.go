@@ -0,0 +1,130 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+)
+
+// DialOption specifies an option for dialing to Neovim.
+type DialOption struct {
+	f func(*dialOptions)
+}
+
+type dialOptions struct {
+	logf       func(string, ...interface{})
+	netDial    func(network, address string) (net.Conn, error)
+	rpcOptions []rpc.Option
+}
+
+// DialLogf specifies the function for logging errors on the connection.
+func DialLogf(logf func(string, ...interface{})) DialOption {
+	return DialOption{func(do *dialOptions) {
+		do.logf = logf
+	}}
+}
+
+// DialNetDial specifies a custom dial function for creating the network
+// connection to Neovim. The network argument to the function is "tcp",
+// "unix" or "pipe". Use a dial function for network "pipe" to connect to a
+// Windows named pipe, for example with a package that wraps the Windows
+// named pipe API.
+func DialNetDial(dial func(network, address string) (net.Conn, error)) DialOption {
+	return DialOption{func(do *dialOptions) {
+		do.netDial = dial
+	}}
+}
+
+// DialRPCOptions specifies additional options for the MessagePack RPC
+// endpoint.
+func DialRPCOptions(options ...rpc.Option) DialOption {
+	return DialOption{func(do *dialOptions) {
+		do.rpcOptions = append(do.rpcOptions, options...)
+	}}
+}
+
+// addressNetwork returns the network for a Neovim listen address. Addresses
+// of the form host:port are TCP addresses. Addresses starting with \\.\pipe\
+// or \\?\pipe\ are Windows named pipes. All other addresses are the path of
+// a Unix domain socket.
+func addressNetwork(address string) string {
+	if strings.HasPrefix(address, `\\.\pipe\`) || strings.HasPrefix(address, `\\?\pipe\`) {
+		return "pipe"
+	}
+	if strings.ContainsAny(address, `/\`) {
+		return "unix"
+	}
+	if _, port, err := net.SplitHostPort(address); err == nil && port != "" {
+		return "tcp"
+	}
+	return "unix"
+}
+
+// Dial connects to the instance of Neovim listening on address. The address
+// is either the path of a Unix domain socket or a TCP address of the form
+// host:port. The net package does not support Windows named pipes. Use the
+// DialNetDial option to connect to a named pipe.
+//
+//	:help $NVIM_LISTEN_ADDRESS
+//
+// Dial runs the MessagePack RPC server loop in a separate goroutine. Do not
+// call the Serve method on the returned client. The Close method closes the
+// connection and waits for the server loop to exit.
+func Dial(address string, options ...DialOption) (*Vim, error) {
+	var do dialOptions
+	for _, option := range options {
+		option.f(&do)
+	}
+	network := addressNetwork(address)
+	if do.netDial == nil {
+		if network == "pipe" {
+			return nil, errors.New("nvim: dialing a Windows named pipe requires the DialNetDial option")
+		}
+		do.netDial = net.Dial
+	}
+
+	c, err := do.netDial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := New(c, c, do.logf, do.rpcOptions...)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		// The server loop returns an error after Close closes the
+		// connection. Discard the error.
+		v.Serve()
+		close(done)
+	}()
+
+	v.close = func() error {
+		<-done
+		return nil
+	}
+
+	return v, nil
+}
+
+// DialEnv connects to the instance of Neovim specified by the environment.
+// DialEnv uses the address in $NVIM, the variable set by Neovim for jobs and
+// terminals started by Neovim, or the address in $NVIM_LISTEN_ADDRESS.
+func DialEnv(options ...DialOption) (*Vim, error) {
+	for _, name := range []string{"NVIM", "NVIM_LISTEN_ADDRESS"} {
+		if address := os.Getenv(name); address != "" {
+			return Dial(address, options...)
+		}
+	}
+	return nil, errors.New("nvim: neither $NVIM nor $NVIM_LISTEN_ADDRESS is set")
+}
@@ -0,0 +1,188 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+)
+
+func TestAddressNetwork(t *testing.T) {
+	for _, tt := range []struct {
+		address string
+		network string
+	}{
+		{"/tmp/nvimsocket", "unix"},
+		{"./nvim:1", "unix"},
+		{"nvimsocket", "unix"},
+		{"127.0.0.1:6666", "tcp"},
+		{"localhost:6666", "tcp"},
+		{"[::1]:6666", "tcp"},
+		{`\\.\pipe\nvim-1234-0`, "pipe"},
+		{`\\?\pipe\nvim`, "pipe"},
+		{`C:\nvim\socket`, "unix"},
+	} {
+		if network := addressNetwork(tt.address); network != tt.network {
+			t.Errorf("addressNetwork(%q) = %q, want %q", tt.address, network, tt.network)
+		}
+	}
+}
+
+// serveFakeAPIInfo accepts connections on l and replies to nvim_get_api_info
+// with channel id 42.
+func serveFakeAPIInfo(t *testing.T, l net.Listener) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		ep, err := rpc.NewEndpoint(c, rpc.WithLogf(t.Logf))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ep.RegisterHandler("nvim_get_api_info", func() ([]interface{}, error) {
+			return []interface{}{42, map[string]interface{}{}}, nil
+		})
+		go ep.Serve()
+	}
+}
+
+func testDial(t *testing.T, l net.Listener, dial func() (*Vim, error)) {
+	defer l.Close()
+	go serveFakeAPIInfo(t, l)
+
+	v, err := dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := v.ChannelID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 42 {
+		t.Errorf("ChannelID() = %d, want 42", id)
+	}
+	if err := v.Close(); err != nil {
+		t.Errorf("Close() returned %v", err)
+	}
+}
+
+func TestDial(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "socket")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDial(t, l, func() (*Vim, error) {
+		return Dial(path, DialLogf(t.Logf))
+	})
+
+	l, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDial(t, l, func() (*Vim, error) {
+		return Dial(l.Addr().String())
+	})
+}
+
+func TestDialPipe(t *testing.T) {
+	const address = `\\.\pipe\nvim`
+	if _, err := Dial(address); err == nil {
+		t.Error("Dial(named pipe) without DialNetDial did not return error")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDial(t, l, func() (*Vim, error) {
+		return Dial(address, DialNetDial(func(network, a string) (net.Conn, error) {
+			if network != "pipe" || a != address {
+				return nil, fmt.Errorf("dial %s %s, want pipe %s", network, a, address)
+			}
+			return net.Dial("tcp", l.Addr().String())
+		}))
+	})
+}
+
+func TestDialEnv(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NVIM", "")
+	t.Setenv("NVIM_LISTEN_ADDRESS", l.Addr().String())
+	testDial(t, l, func() (*Vim, error) {
+		return DialEnv()
+	})
+
+	t.Setenv("NVIM_LISTEN_ADDRESS", "")
+	if _, err := DialEnv(); err == nil {
+		t.Error("DialEnv() with empty environment did not return error")
+	}
+}
+
+func TestServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(t.Logf)
+	connected := make(chan *Vim, 1)
+	s.OnConnect = func(ep *rpc.Endpoint) {
+		connected <- ep.FirstArg().(*Vim)
+	}
+	if err := s.RegisterHandler("hello", helloHandler); err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(l)
+	defer s.Shutdown(context.Background())
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := rpc.NewEndpoint(c, rpc.WithLogf(t.Logf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.RegisterHandler("nvim_get_api_info", func() ([]interface{}, error) {
+		return []interface{}{7, map[string]interface{}{}}, nil
+	})
+	go client.Serve()
+	defer client.Close()
+
+	var result string
+	if err := client.Call("hello", &result, "world"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "Hello, world" {
+		t.Errorf("hello returned %q, want %q", result, "Hello, world")
+	}
+
+	v := <-connected
+	id, err := v.ChannelID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 {
+		t.Errorf("ChannelID() = %d, want 7", id)
+	}
+}
@@ -2,13 +2,14 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build ignore
 // +build ignore
 
 // This program prints Neovim's API info as JSON.
 //
-// A snapshot of the output from this program is checked into api.json.
-// Compare api.json to the output from this program to discover changes to
-// Neovim's API.
+// A snapshot of the output from this program is checked into api.json. Use
+// apidiff.go to compare api.json to the output from this program and discover
+// changes to Neovim's API.
 
 package main
 
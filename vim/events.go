@@ -0,0 +1,225 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+
+	"github.com/garyburd/neovim-go/msgpack"
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+)
+
+// Event is an event received from Neovim by a subscriber.
+type Event struct {
+	// Name is the name of the event.
+	Name string
+
+	// Args is the payload of the event decoded to the type specified in the
+	// call to SubscribeEvent.
+	Args interface{}
+
+	// Err is the error, if any, decoding the payload.
+	Err error
+}
+
+// eventSubscription is the set of consumers for a named event.
+type eventSubscription struct {
+	// consumers is protected by Vim.subMu.
+	consumers map[*eventConsumer]bool
+
+	// rpcMu serializes the calls to nvim_subscribe and nvim_unsubscribe
+	// for the event. subscribed is true if Neovim has the subscription and
+	// is protected by rpcMu.
+	rpcMu      sync.Mutex
+	subscribed bool
+}
+
+type eventConsumer struct {
+	argsType reflect.Type
+	ch       chan Event
+	done     chan struct{}
+
+	// mu protects closed and sends to ch.
+	mu     sync.Mutex
+	closed bool
+}
+
+// eventBufferSize is the size of the channels returned from SubscribeEvent.
+const eventBufferSize = 16
+
+// SubscribeEvent subscribes to the named event and returns a channel of the
+// events. Neovim sends events to subscribers with rpcnotify:
+//
+//	:call rpcnotify(0, "name", arg1, arg2)
+//
+// The event arguments are decoded to a new value of argsType. Use a slice
+// type or a struct type with the ",array" field tag option for argsType. If
+// argsType is nil, then the arguments are decoded to []interface{}.
+//
+// An event can have more than one subscriber. Each subscriber receives every
+// event in the order sent by Neovim. A subscriber that does not receive from
+// its channel delays delivery of the event to the other subscribers. The
+// arguments are decoded once for each argsType. Subscribers with the same
+// argsType receive the same value and must not modify the value.
+//
+// SubscribeEvent registers an RPC handler for the event name. Do not use
+// RegisterHandler to register a handler with the same name.
+//
+// Call the cancel function to stop delivery to the channel and close the
+// channel. SubscribeEvent unsubscribes from the event in Neovim when the last
+// subscriber cancels.
+func (v *Vim) SubscribeEvent(name string, argsType reflect.Type) (events <-chan Event, cancel func(), err error) {
+	if argsType == nil {
+		argsType = reflect.TypeOf([]interface{}(nil))
+	}
+	c := &eventConsumer{
+		argsType: argsType,
+		ch:       make(chan Event, eventBufferSize),
+		done:     make(chan struct{}),
+	}
+
+	v.subMu.Lock()
+	if v.subscriptions == nil {
+		v.subscriptions = make(map[string]*eventSubscription)
+	}
+	s := v.subscriptions[name]
+	if s == nil {
+		if !v.eventHandlers[name] {
+			err := v.ep.RegisterHandler(name, func(v *Vim, args ...interface{}) {
+				v.dispatchEvent(name, args)
+			}, rpc.Serial())
+			if err != nil {
+				v.subMu.Unlock()
+				return nil, nil, err
+			}
+			if v.eventHandlers == nil {
+				v.eventHandlers = make(map[string]bool)
+			}
+			v.eventHandlers[name] = true
+		}
+		// The subscription is kept after the last consumer cancels so that
+		// the calls to Neovim for the event are serialized by s.rpcMu.
+		s = &eventSubscription{}
+		v.subscriptions[name] = s
+	}
+	v.setConsumer(s, c, true)
+	v.subMu.Unlock()
+
+	if err := v.syncSubscription(name, s); err != nil {
+		v.subMu.Lock()
+		v.setConsumer(s, c, false)
+		v.subMu.Unlock()
+		v.syncSubscription(name, s)
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	return c.ch, func() { once.Do(func() { v.cancelEvent(name, s, c) }) }, nil
+}
+
+// setConsumer adds or removes consumer c. The consumers are copied on write
+// so that dispatchEvent can use the consumers without holding subMu. The
+// caller must hold subMu.
+func (v *Vim) setConsumer(s *eventSubscription, c *eventConsumer, add bool) {
+	consumers := make(map[*eventConsumer]bool)
+	for x := range s.consumers {
+		if x != c {
+			consumers[x] = true
+		}
+	}
+	if add {
+		consumers[c] = true
+	}
+	s.consumers = consumers
+}
+
+// syncSubscription subscribes to or unsubscribes from the event in Neovim
+// as needed for the current consumers. The call to Neovim is made without
+// holding subMu.
+func (v *Vim) syncSubscription(name string, s *eventSubscription) error {
+	s.rpcMu.Lock()
+	defer s.rpcMu.Unlock()
+
+	v.subMu.Lock()
+	want := len(s.consumers) > 0
+	v.subMu.Unlock()
+
+	if want == s.subscribed {
+		return nil
+	}
+	var err error
+	if want {
+		err = v.Subscribe(name)
+	} else {
+		err = v.Unsubscribe(name)
+	}
+	if err != nil {
+		return err
+	}
+	s.subscribed = want
+	return nil
+}
+
+func (v *Vim) cancelEvent(name string, s *eventSubscription, c *eventConsumer) {
+	close(c.done)
+	c.mu.Lock()
+	c.closed = true
+	close(c.ch)
+	c.mu.Unlock()
+
+	v.subMu.Lock()
+	v.setConsumer(s, c, false)
+	v.subMu.Unlock()
+
+	// The error is ignored because there's no caller to report the error
+	// to. The unsubscribe fails only when the connection is closed.
+	v.syncSubscription(name, s)
+}
+
+func (v *Vim) dispatchEvent(name string, args []interface{}) {
+	v.subMu.Lock()
+	var consumers map[*eventConsumer]bool
+	if s := v.subscriptions[name]; s != nil {
+		consumers = s.consumers
+	}
+	v.subMu.Unlock()
+
+	if len(consumers) == 0 {
+		return
+	}
+	if args == nil {
+		args = []interface{}{}
+	}
+	// Encode the arguments for decoding to the consumers' types. The
+	// arguments are decoded once for each type.
+	var buf bytes.Buffer
+	encodeErr := msgpack.NewEncoder(&buf).Encode(args)
+	p := buf.Bytes()
+	decoded := make(map[reflect.Type]Event)
+
+	for c := range consumers {
+		e, ok := decoded[c.argsType]
+		if !ok {
+			e = Event{Name: name, Err: encodeErr}
+			pv := reflect.New(c.argsType)
+			if e.Err == nil {
+				e.Err = msgpack.NewDecoder(bytes.NewReader(p)).Decode(pv.Interface())
+			}
+			e.Args = pv.Elem().Interface()
+			decoded[c.argsType] = e
+		}
+
+		c.mu.Lock()
+		if !c.closed {
+			select {
+			case c.ch <- e:
+			case <-c.done:
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestSubscribeEvent(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	type args struct {
+		Name  string `msgpack:",array"`
+		Count int
+	}
+	events1, cancel1, err := v.SubscribeEvent("test", reflect.TypeOf(args{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events2, cancel2, err := v.SubscribeEvent("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Subscribed("test") {
+		t.Fatal("not subscribed after SubscribeEvent")
+	}
+
+	receive := func(events <-chan vim.Event) vim.Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for event")
+			return vim.Event{}
+		}
+	}
+
+	if err := f.Notify("test", "hello", 1); err != nil {
+		t.Fatal(err)
+	}
+	if e := receive(events1); e.Err != nil || !reflect.DeepEqual(e.Args, args{"hello", 1}) {
+		t.Errorf("event = %+v, want args %+v", e, args{"hello", 1})
+	}
+	if e := receive(events2); e.Err != nil || !reflect.DeepEqual(e.Args, []interface{}{"hello", int64(1)}) {
+		t.Errorf("event = %+v, want args [hello 1]", e)
+	}
+
+	cancel1()
+	if _, ok := <-events1; ok {
+		t.Error("channel open after cancel")
+	}
+	if err := f.Notify("test", "world", 2); err != nil {
+		t.Fatal(err)
+	}
+	if e := receive(events2); e.Name != "test" || !reflect.DeepEqual(e.Args, []interface{}{"world", int64(2)}) {
+		t.Errorf("event = %+v, want args [world 2]", e)
+	}
+	if !f.Subscribed("test") {
+		t.Error("unsubscribed with remaining subscriber")
+	}
+
+	cancel2()
+	cancel2()
+	if f.Subscribed("test") {
+		t.Error("subscribed after last subscriber cancelled")
+	}
+
+	// Subscribers with the same type receive the same decoded arguments.
+	events3, cancel3, err := v.SubscribeEvent("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel3()
+	events4, cancel4, err := v.SubscribeEvent("test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel4()
+	if !f.Subscribed("test") {
+		t.Fatal("not subscribed after SubscribeEvent following cancel")
+	}
+	if err := f.Notify("test", "again", 3); err != nil {
+		t.Fatal(err)
+	}
+	e3, e4 := receive(events3), receive(events4)
+	if !reflect.DeepEqual(e3.Args, []interface{}{"again", int64(3)}) {
+		t.Errorf("event = %+v, want args [again 3]", e3)
+	}
+	if reflect.ValueOf(e3.Args).Pointer() != reflect.ValueOf(e4.Args).Pointer() {
+		t.Error("arguments decoded for each subscriber with the same type")
+	}
+}
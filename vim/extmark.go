@@ -0,0 +1,256 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"fmt"
+
+	"github.com/garyburd/neovim-go/msgpack"
+)
+
+// Extmark is an extended mark in a buffer. Neovim moves extended marks with
+// the text when the buffer is edited.
+//
+// The positions are zero-based. The fields after Col are set only when the
+// mark is retrieved with details.
+//
+//	:help extmarks
+type Extmark struct {
+	ID  int
+	Row int
+	Col int
+
+	// EndRow and EndCol are the end of the range for the mark. EndRow and
+	// EndCol are -1 if the mark does not have a range.
+	EndRow int
+	EndCol int
+
+	// NamespaceID is the namespace of the mark.
+	NamespaceID int
+
+	HighlightGroup     string
+	VirtText           []VirtTextChunk
+	VirtTextPos        string
+	SignText           string
+	SignHighlightGroup string
+	Priority           int
+}
+
+// VirtTextChunk is a chunk of virtual text for an extmark.
+type VirtTextChunk struct {
+	Text           string `msgpack:",array"`
+	HighlightGroup string
+}
+
+// ExtmarkOptions specifies the options for SetBufferExtmark.
+//
+//	:help nvim_buf_set_extmark()
+type ExtmarkOptions struct {
+	// ID is the id of the mark to create or move. Neovim allocates an id
+	// when ID is zero.
+	ID int `msgpack:"id,omitempty"`
+
+	// EndRow and EndCol specify the end of the range for the mark.
+	EndRow *int `msgpack:"end_row,omitempty"`
+	EndCol *int `msgpack:"end_col,omitempty"`
+
+	// HighlightGroup is the highlight for the range of the mark.
+	HighlightGroup string `msgpack:"hl_group,omitempty"`
+
+	// VirtText is the virtual text for the mark. VirtTextPos is the position
+	// of the virtual text: "eol", "overlay", "right_align" or "inline".
+	VirtText    []VirtTextChunk `msgpack:"virt_text,omitempty"`
+	VirtTextPos string          `msgpack:"virt_text_pos,omitempty"`
+
+	// SignText is the text, one or two display cells, for a sign in the
+	// sign column.
+	SignText           string `msgpack:"sign_text,omitempty"`
+	SignHighlightGroup string `msgpack:"sign_hl_group,omitempty"`
+
+	// Priority is the priority for the highlight and sign.
+	Priority int `msgpack:"priority,omitempty"`
+
+	// RightGravity specifies that the mark moves to the right when text is
+	// inserted at the mark. The default is true.
+	RightGravity *bool `msgpack:"right_gravity,omitempty"`
+
+	// EndRightGravity specifies that the end of the range moves to the right
+	// when text is inserted at the end.
+	EndRightGravity bool `msgpack:"end_right_gravity,omitempty"`
+
+	// Strict specifies that positions outside of the buffer are an error.
+	// The default is true. Neovim moves the positions into the buffer when
+	// Strict is false.
+	Strict *bool `msgpack:"strict,omitempty"`
+}
+
+// ExtmarksOptions specifies the options for BufferExtmarks.
+//
+//	:help nvim_buf_get_extmarks()
+type ExtmarksOptions struct {
+	// Limit is the maximum number of marks to return. All marks are returned
+	// when Limit is zero.
+	Limit int `msgpack:"limit,omitempty"`
+
+	// Details specifies that the details of the marks are returned.
+	Details bool `msgpack:"details,omitempty"`
+}
+
+// extmarkDetails is the details map returned by Neovim for a mark.
+type extmarkDetails struct {
+	NamespaceID        int             `msgpack:"ns_id"`
+	EndRow             *int            `msgpack:"end_row"`
+	EndCol             *int            `msgpack:"end_col"`
+	HighlightGroup     string          `msgpack:"hl_group"`
+	VirtText           []VirtTextChunk `msgpack:"virt_text"`
+	VirtTextPos        string          `msgpack:"virt_text_pos"`
+	SignText           string          `msgpack:"sign_text"`
+	SignHighlightGroup string          `msgpack:"sign_hl_group"`
+	Priority           int             `msgpack:"priority"`
+}
+
+func (m *Extmark) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	return m.decode(dec, true)
+}
+
+// decode decodes [id, row, col, details] or [row, col, details] to m. The
+// details are optional.
+func (m *Extmark) decode(dec *msgpack.Decoder, hasID bool) error {
+	if dec.Type() != msgpack.ArrayLen {
+		err := fmt.Errorf("nvim: extmark is %s, want array", dec.Type())
+		dec.Skip()
+		return err
+	}
+	n := dec.Len()
+	fields := []*int{&m.ID, &m.Row, &m.Col}
+	if !hasID {
+		fields = fields[1:]
+	}
+	if n < len(fields) {
+		err := fmt.Errorf("nvim: extmark has %d elements, want %d", n, len(fields))
+		dec.Skip()
+		return err
+	}
+	for _, p := range fields {
+		if err := dec.Decode(p); err != nil {
+			return err
+		}
+	}
+	m.EndRow, m.EndCol = -1, -1
+	n -= len(fields)
+	if n > 0 {
+		var d extmarkDetails
+		if err := dec.Decode(&d); err != nil {
+			return err
+		}
+		if d.EndRow != nil && d.EndCol != nil {
+			m.EndRow, m.EndCol = *d.EndRow, *d.EndCol
+		}
+		m.NamespaceID = d.NamespaceID
+		m.HighlightGroup = d.HighlightGroup
+		m.VirtText = d.VirtText
+		m.VirtTextPos = d.VirtTextPos
+		m.SignText = d.SignText
+		m.SignHighlightGroup = d.SignHighlightGroup
+		m.Priority = d.Priority
+		n--
+	}
+	for ; n > 0; n-- {
+		if err := skipValue(dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extmarkByID decodes the result of nvim_buf_get_extmark_by_id. The result
+// does not include the id of the mark.
+type extmarkByID struct {
+	m  *Extmark
+	id int
+}
+
+func (x *extmarkByID) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	*x.m = Extmark{}
+	if dec.Type() == msgpack.ArrayLen && dec.Len() == 0 {
+		// The mark does not exist.
+		return nil
+	}
+	if err := x.m.decode(dec, false); err != nil {
+		return err
+	}
+	x.m.ID = x.id
+	return nil
+}
+
+func extmarkOptions(opts *ExtmarkOptions) interface{} {
+	if opts == nil {
+		return map[string]interface{}{}
+	}
+	return opts
+}
+
+func extmarksOptions(opts *ExtmarksOptions) interface{} {
+	if opts == nil {
+		return map[string]interface{}{}
+	}
+	return opts
+}
+
+// SetBufferExtmark creates or updates an extmark at the zero-based row and
+// column and returns the id of the mark.
+//
+//	:help nvim_buf_set_extmark()
+func (v *Vim) SetBufferExtmark(buffer Buffer, nsID int, row int, col int, opts *ExtmarkOptions) (int, error) {
+	var id int
+	err := v.call("nvim_buf_set_extmark", &id, buffer, nsID, row, col, extmarkOptions(opts))
+	return id, err
+}
+
+// SetBufferExtmark creates or updates an extmark at the zero-based row and
+// column.
+//
+//	:help nvim_buf_set_extmark()
+func (p *Pipeline) SetBufferExtmark(buffer Buffer, nsID int, row int, col int, opts *ExtmarkOptions, id *int) {
+	p.call("nvim_buf_set_extmark", id, buffer, nsID, row, col, extmarkOptions(opts))
+}
+
+// BufferExtmark returns the extmark with the given id. The ID field of the
+// returned mark is zero if the mark does not exist.
+//
+//	:help nvim_buf_get_extmark_by_id()
+func (v *Vim) BufferExtmark(buffer Buffer, nsID int, id int, details bool) (Extmark, error) {
+	var m Extmark
+	err := v.call("nvim_buf_get_extmark_by_id", &extmarkByID{&m, id}, buffer, nsID, id, map[string]interface{}{"details": details})
+	return m, err
+}
+
+// BufferExtmark returns the extmark with the given id. The ID field of the
+// returned mark is zero if the mark does not exist.
+//
+//	:help nvim_buf_get_extmark_by_id()
+func (p *Pipeline) BufferExtmark(buffer Buffer, nsID int, id int, details bool, result *Extmark) {
+	p.call("nvim_buf_get_extmark_by_id", &extmarkByID{result, id}, buffer, nsID, id, map[string]interface{}{"details": details})
+}
+
+// BufferExtmarks returns the extmarks in the range from start to end. The
+// start and end are zero-based (row, col) positions. Use [2]int{0, 0} and
+// [2]int{-1, -1} for the whole buffer. If start is after end, then the marks
+// are returned in reverse order. If nsID is -1, then the marks in all
+// namespaces are returned.
+//
+//	:help nvim_buf_get_extmarks()
+func (v *Vim) BufferExtmarks(buffer Buffer, nsID int, start, end [2]int, opts *ExtmarksOptions) ([]Extmark, error) {
+	var marks []Extmark
+	err := v.call("nvim_buf_get_extmarks", &marks, buffer, nsID, start, end, extmarksOptions(opts))
+	return marks, err
+}
+
+// BufferExtmarks returns the extmarks in the range from start to end.
+//
+//	:help nvim_buf_get_extmarks()
+func (p *Pipeline) BufferExtmarks(buffer Buffer, nsID int, start, end [2]int, opts *ExtmarksOptions, result *[]Extmark) {
+	p.call("nvim_buf_get_extmarks", result, buffer, nsID, start, end, extmarksOptions(opts))
+}
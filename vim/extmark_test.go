@@ -0,0 +1,97 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestExtmarks(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b := f.NewBuffer("", "one", "two", "three")
+	ns, err := v.CreateNamespace("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	namespaces, err := v.Namespaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespaces["test"] != ns {
+		t.Errorf("Namespaces() = %v, want test: %d", namespaces, ns)
+	}
+
+	endRow, endCol := 2, 3
+	id1, err := v.SetBufferExtmark(b, ns, 1, 1, &vim.ExtmarkOptions{
+		EndRow:         &endRow,
+		EndCol:         &endCol,
+		HighlightGroup: "Error",
+		VirtText:       []vim.VirtTextChunk{{Text: "oops", HighlightGroup: "Comment"}},
+		SignText:       "E",
+		Priority:       10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, err := v.SetBufferExtmark(b, ns, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := v.BufferExtmark(b, ns, id1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := vim.Extmark{
+		ID:             id1,
+		Row:            1,
+		Col:            1,
+		EndRow:         2,
+		EndCol:         3,
+		NamespaceID:    ns,
+		HighlightGroup: "Error",
+		VirtText:       []vim.VirtTextChunk{{Text: "oops", HighlightGroup: "Comment"}},
+		SignText:       "E",
+		Priority:       10,
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("BufferExtmark() = %+v, want %+v", m, want)
+	}
+
+	marks, err := v.BufferExtmarks(b, ns, [2]int{0, 0}, [2]int{-1, -1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMarks := []vim.Extmark{
+		{ID: id2, Row: 0, Col: 0, EndRow: -1, EndCol: -1},
+		{ID: id1, Row: 1, Col: 1, EndRow: -1, EndCol: -1},
+	}
+	if !reflect.DeepEqual(marks, wantMarks) {
+		t.Errorf("BufferExtmarks() = %+v, want %+v", marks, wantMarks)
+	}
+
+	// Marks move with the text.
+	if err := v.SetBufferLines(b, 0, 0, true, [][]byte{[]byte("zero")}); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := v.BufferExtmark(b, ns, id1, false); err != nil {
+		t.Fatal(err)
+	} else if m.Row != 2 || m.Col != 1 {
+		t.Errorf("mark at %d, %d after insert, want 2, 1", m.Row, m.Col)
+	}
+
+	if ok, err := v.DeleteBufferExtmark(b, ns, id1); err != nil || !ok {
+		t.Errorf("DeleteBufferExtmark() = %v, %v, want true, nil", ok, err)
+	}
+	if m, err := v.BufferExtmark(b, ns, id1, false); err != nil || m.ID != 0 {
+		t.Errorf("BufferExtmark() after delete = %+v, %v, want zero mark", m, err)
+	}
+}
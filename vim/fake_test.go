@@ -0,0 +1,19 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim/vimfake"
+)
+
+func newFake(t *testing.T) *vimfake.Fake {
+	f, err := vimfake.New(t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
@@ -0,0 +1,426 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"fmt"
+
+	"github.com/garyburd/neovim-go/msgpack"
+)
+
+// WindowConfig specifies the layout of a floating window.
+//
+//	:help nvim_open_win()
+type WindowConfig struct {
+	// Relative specifies what Row and Col are relative to: "editor", "win",
+	// "cursor" or "mouse". Relative is "" for a window that is not floating.
+	Relative string
+
+	// Window is the window for Relative = "win". Zero is the current window.
+	Window Window
+
+	// Anchor is the corner of the float placed at Row and Col: "NW", "NE",
+	// "SW" or "SE". The default is "NW".
+	Anchor string
+
+	// Row and Col are the position of the float in screen cells. The
+	// position can be fractional.
+	Row float64
+	Col float64
+
+	// Width and Height are the size of the window in screen cells.
+	Width  int
+	Height int
+
+	// Border is the name of a border style: "none", "single", "double",
+	// "rounded", "solid" or "shadow".
+	Border string
+
+	// BorderChars are the characters for the border, clockwise from the top
+	// left corner. BorderChars overrides Border. WindowConfig sets
+	// BorderChars for a window with a border.
+	BorderChars []string
+
+	// Style is "minimal" to hide the number column, sign column and other
+	// decorations of the window.
+	Style string
+
+	// ZIndex is the stacking order of the float. Floats with a larger ZIndex
+	// are drawn on top. The default is 50.
+	ZIndex int
+
+	// Focusable specifies that the float can be entered with window
+	// commands. The default is true.
+	Focusable *bool
+}
+
+// Floating returns true if the window configuration is for a floating window.
+func (c *WindowConfig) Floating() bool {
+	return c.Relative != ""
+}
+
+func (c *WindowConfig) MarshalMsgPack(enc *msgpack.Encoder) error {
+	m := make(map[string]interface{})
+	if c.Relative != "" {
+		m["relative"] = c.Relative
+		m["row"] = c.Row
+		m["col"] = c.Col
+	}
+	if c.Window != 0 {
+		m["win"] = c.Window
+	}
+	if c.Anchor != "" {
+		m["anchor"] = c.Anchor
+	}
+	if c.Width > 0 {
+		m["width"] = c.Width
+	}
+	if c.Height > 0 {
+		m["height"] = c.Height
+	}
+	if c.BorderChars != nil {
+		m["border"] = c.BorderChars
+	} else if c.Border != "" {
+		m["border"] = c.Border
+	}
+	if c.Style != "" {
+		m["style"] = c.Style
+	}
+	if c.ZIndex > 0 {
+		m["zindex"] = c.ZIndex
+	}
+	if c.Focusable != nil {
+		m["focusable"] = *c.Focusable
+	}
+	return enc.Encode(m)
+}
+
+func (c *WindowConfig) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	*c = WindowConfig{}
+	if dec.Type() != msgpack.MapLen {
+		err := fmt.Errorf("nvim: window config is %s, want map", dec.Type())
+		dec.Skip()
+		return err
+	}
+	n := dec.Len()
+	for i := 0; i < n; i++ {
+		var key string
+		if err := dec.Decode(&key); err != nil {
+			return err
+		}
+		var err error
+		switch key {
+		case "relative":
+			err = dec.Decode(&c.Relative)
+		case "win":
+			err = dec.Decode(&c.Window)
+		case "anchor":
+			err = dec.Decode(&c.Anchor)
+		case "row":
+			err = dec.Decode(&c.Row)
+		case "col":
+			err = dec.Decode(&c.Col)
+		case "width":
+			err = dec.Decode(&c.Width)
+		case "height":
+			err = dec.Decode(&c.Height)
+		case "style":
+			err = dec.Decode(&c.Style)
+		case "zindex":
+			err = dec.Decode(&c.ZIndex)
+		case "focusable":
+			var focusable bool
+			err = dec.Decode(&focusable)
+			c.Focusable = &focusable
+		case "border":
+			var border interface{}
+			err = dec.Decode(&border)
+			c.Border, c.BorderChars = decodeBorder(border)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeBorder converts a border from the window config. Neovim returns a
+// border as the name of a style or as a list of characters, where each
+// character is a string or a [char, highlight] list.
+func decodeBorder(border interface{}) (name string, chars []string) {
+	switch border := border.(type) {
+	case string:
+		return border, nil
+	case []interface{}:
+		chars = make([]string, len(border))
+		for i, c := range border {
+			switch c := c.(type) {
+			case string:
+				chars[i] = c
+			case []interface{}:
+				if len(c) > 0 {
+					chars[i], _ = c[0].(string)
+				}
+			}
+		}
+		return "", chars
+	}
+	return "", nil
+}
+
+// OpenWindow opens a new window showing buffer b. If enter is true, then the
+// window becomes the current window.
+//
+//	:help nvim_open_win()
+func (v *Vim) OpenWindow(b Buffer, enter bool, config *WindowConfig) (Window, error) {
+	var w Window
+	err := v.call("nvim_open_win", &w, b, enter, config)
+	return w, err
+}
+
+// OpenWindow opens a new window showing buffer b.
+//
+//	:help nvim_open_win()
+func (p *Pipeline) OpenWindow(b Buffer, enter bool, config *WindowConfig, result *Window) {
+	p.call("nvim_open_win", result, b, enter, config)
+}
+
+// SetWindowConfig changes the layout of a window. Use SetWindowConfig to
+// move or resize a floating window. The fields of config with the zero value
+// are not changed.
+//
+//	:help nvim_win_set_config()
+func (v *Vim) SetWindowConfig(w Window, config *WindowConfig) error {
+	return v.call("nvim_win_set_config", nil, w, config)
+}
+
+// SetWindowConfig changes the layout of a window.
+//
+//	:help nvim_win_set_config()
+func (p *Pipeline) SetWindowConfig(w Window, config *WindowConfig) {
+	p.call("nvim_win_set_config", nil, w, config)
+}
+
+// WindowConfig returns the layout of a window.
+//
+//	:help nvim_win_get_config()
+func (v *Vim) WindowConfig(w Window) (*WindowConfig, error) {
+	var config WindowConfig
+	if err := v.call("nvim_win_get_config", &config, w); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// WindowConfig returns the layout of a window.
+//
+//	:help nvim_win_get_config()
+func (p *Pipeline) WindowConfig(w Window, result *WindowConfig) {
+	p.call("nvim_win_get_config", result, w)
+}
+
+// FloatOptions specifies options for OpenFloat.
+type FloatOptions struct {
+	// Relative, Row and Col specify the position of the float as in
+	// WindowConfig. If Relative is "", then the float is shown below the
+	// cursor.
+	Relative string
+	Row      float64
+	Col      float64
+	Anchor   string
+
+	// Border is the name of a border style.
+	Border string
+
+	// ZIndex is the stacking order of the float.
+	ZIndex int
+
+	// MaxWidth and MaxHeight limit the size of the float. The size of the
+	// float is also limited by the size of the editor.
+	MaxWidth  int
+	MaxHeight int
+
+	// Filetype is the filetype of the float's buffer, for example
+	// "markdown".
+	Filetype string
+}
+
+// Float is a floating window that shows text in a scratch buffer. The float
+// closes when the cursor moves.
+type Float struct {
+	v      *Vim
+	buffer Buffer
+	window Window
+	group  string
+}
+
+// OpenFloat opens a floating window showing lines. The float is sized to
+// fit the lines and the editor. The float does not take focus. The float
+// closes when Close is called, when insert mode is entered or when the cursor
+// moves from its position when the float was opened. A CursorMoved event that
+// is pending when the float opens does not close the float.
+func OpenFloat(v *Vim, lines []string, opts *FloatOptions) (*Float, error) {
+	if opts == nil {
+		opts = &FloatOptions{}
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+
+	var (
+		b       Buffer
+		columns int
+		rows    int
+		window  Window
+		cursor  [2]int
+	)
+	widths := make([]int, len(lines))
+	p := v.NewPipeline()
+	p.CreateBuffer(false, true, &b)
+	p.CurrentWindow(&window)
+	p.WindowCursor(0, &cursor)
+	p.Option("columns", &columns)
+	p.Option("lines", &rows)
+	for i, line := range lines {
+		p.Strwidth(line, &widths[i])
+	}
+	if err := p.Wait(); err != nil {
+		if b != 0 {
+			v.DeleteBuffer(b, map[string]interface{}{"force": true})
+		}
+		return nil, err
+	}
+
+	border := 0
+	if opts.Border != "" && opts.Border != "none" {
+		border = 2
+	}
+	width := 1
+	for _, w := range widths {
+		if w > width {
+			width = w
+		}
+	}
+	height := len(lines)
+	// The last row of the editor is the command line.
+	width = clampSize(width, opts.MaxWidth, columns-border)
+	height = clampSize(height, opts.MaxHeight, rows-1-border)
+
+	config := &WindowConfig{
+		Relative:  opts.Relative,
+		Row:       opts.Row,
+		Col:       opts.Col,
+		Anchor:    opts.Anchor,
+		Width:     width,
+		Height:    height,
+		Border:    opts.Border,
+		Style:     "minimal",
+		ZIndex:    opts.ZIndex,
+		Focusable: new(bool),
+	}
+	switch config.Relative {
+	case "":
+		config.Relative, config.Row, config.Col = "cursor", 1, 0
+	case "editor":
+		config.Row = clampPosition(config.Row, rows-1-height-border)
+		config.Col = clampPosition(config.Col, columns-width-border)
+	}
+
+	data := make([][]byte, len(lines))
+	for i, line := range lines {
+		data[i] = []byte(line)
+	}
+	f := &Float{v: v, buffer: b}
+	p = v.NewPipeline()
+	p.SetBufferLines(b, 0, -1, true, data)
+	p.SetBufferOption(b, "bufhidden", "wipe")
+	p.SetBufferOption(b, "modifiable", false)
+	if opts.Filetype != "" {
+		p.SetBufferOption(b, "filetype", opts.Filetype)
+	}
+	p.OpenWindow(b, false, config, &f.window)
+	if err := p.Wait(); err != nil {
+		// Delete the buffer and the window, if any. The errors are ignored
+		// because the buffer is wiped out when the window closes.
+		p = v.NewPipeline()
+		if f.window != 0 {
+			p.CloseWindow(f.window, true)
+		}
+		p.DeleteBuffer(b, map[string]interface{}{"force": true})
+		p.Wait()
+		return nil, err
+	}
+
+	// The CursorMoved autocmd checks the cursor position because Neovim
+	// may have a CursorMoved event pending for a move made before the float
+	// opened.
+	f.group = fmt.Sprintf("nvim_go_float_%d", f.window)
+	closeFloat := fmt.Sprintf("silent! call nvim_win_close(%d, v:true) | exe 'autocmd! %s' | exe 'augroup! %s'",
+		f.window, f.group, f.group)
+	p = v.NewPipeline()
+	p.Command(fmt.Sprintf("augroup %s | autocmd! | augroup END", f.group))
+	p.Command(fmt.Sprintf("autocmd %s CursorMoved,CursorMovedI * if [nvim_get_current_win()] + nvim_win_get_cursor(0) != [%d, %d, %d] | %s | endif",
+		f.group, window, cursor[0], cursor[1], closeFloat))
+	p.Command(fmt.Sprintf("autocmd %s InsertEnter * %s", f.group, closeFloat))
+	if err := p.Wait(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// clampSize limits n to the positive limits.
+func clampSize(n int, limits ...int) int {
+	for _, limit := range limits {
+		if limit > 0 && n > limit {
+			n = limit
+		}
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// clampPosition limits a position to the range 0 to max.
+func clampPosition(pos float64, max int) float64 {
+	if pos > float64(max) {
+		pos = float64(max)
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos
+}
+
+// Buffer returns the scratch buffer shown in the float.
+func (f *Float) Buffer() Buffer {
+	return f.buffer
+}
+
+// Window returns the floating window.
+func (f *Float) Window() Window {
+	return f.window
+}
+
+// Close closes the float. Close does nothing if the float is closed.
+func (f *Float) Close() error {
+	var valid bool
+	p := f.v.NewPipeline()
+	if f.group != "" {
+		p.Command("silent! autocmd! " + f.group)
+		p.Command("silent! augroup! " + f.group)
+	}
+	p.IsWindowValid(f.window, &valid)
+	if err := p.Wait(); err != nil {
+		return err
+	}
+	if !valid {
+		return nil
+	}
+	return f.v.CloseWindow(f.window, true)
+}
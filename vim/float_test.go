@@ -0,0 +1,112 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestFloat(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	current, err := v.CurrentWindow()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	float, err := vim.OpenFloat(v, []string{"hello", "a longer line"}, &vim.FloatOptions{
+		Relative: "editor",
+		Row:      100,
+		Col:      3,
+		Border:   "rounded",
+		MaxWidth: 10,
+		Filetype: "markdown",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, err := v.CurrentWindow(); err != nil || w != current {
+		t.Errorf("CurrentWindow() = %v, %v, want %v", w, err, current)
+	}
+
+	config, err := v.WindowConfig(float.Window())
+	if err != nil {
+		t.Fatal(err)
+	}
+	focusable := false
+	want := &vim.WindowConfig{
+		Relative:  "editor",
+		Row:       24 - 1 - 2 - 2,
+		Col:       3,
+		Width:     10,
+		Height:    2,
+		Border:    "rounded",
+		Style:     "minimal",
+		Focusable: &focusable,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("WindowConfig() = %+v, want %+v", config, want)
+	}
+
+	lines, err := v.BufferLines(float.Buffer(), 0, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(bytes.Join(lines, []byte("\n"))); got != "hello\na longer line" {
+		t.Errorf("lines = %q, want hello and a longer line", got)
+	}
+	var filetype string
+	if err := v.BufferOption(float.Buffer(), "filetype", &filetype); err != nil || filetype != "markdown" {
+		t.Errorf("filetype = %q, %v, want markdown", filetype, err)
+	}
+
+	autocmd := false
+	for _, cmd := range f.Commands() {
+		if strings.HasPrefix(cmd, "autocmd nvim_go_float_") && strings.Contains(cmd, "CursorMoved") &&
+			strings.Contains(cmd, fmt.Sprintf("!= [%d, 1, 0]", current)) {
+			autocmd = true
+		}
+	}
+	if !autocmd {
+		t.Errorf("commands %q do not close the float when the cursor moves from its position", f.Commands())
+	}
+
+	if err := v.SetWindowConfig(float.Window(), &vim.WindowConfig{Relative: "cursor", Row: 1, Width: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := v.WindowConfig(float.Window()); err != nil || config.Relative != "cursor" || config.Width != 5 || config.Height != 2 {
+		t.Errorf("WindowConfig() after set = %+v, %v, want cursor, 5x2", config, err)
+	}
+
+	if err := float.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := v.IsWindowValid(float.Window()); err != nil || valid {
+		t.Errorf("IsWindowValid() after close = %v, %v, want false", valid, err)
+	}
+	if err := float.Close(); err != nil {
+		t.Errorf("second Close() returned %v", err)
+	}
+
+	// The scratch buffer is deleted when the float does not open.
+	buffers, err := v.Buffers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vim.OpenFloat(v, []string{"hello"}, &vim.FloatOptions{Relative: "bogus"}); err == nil {
+		t.Fatal("OpenFloat with invalid relative returned nil error")
+	}
+	if after, err := v.Buffers(); err != nil || len(after) != len(buffers) {
+		t.Errorf("Buffers() after failed OpenFloat = %v, %v, want %v", after, err, buffers)
+	}
+}
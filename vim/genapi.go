@@ -2,588 +2,443 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build ignore
 // +build ignore
 
 // This program generates Neovim API methods in api.go.
 //
-// The program generates the code from data declared in this file instead of
-// using the output from nvim --api-info. This approach allows names and types
-// to be modified to create a more idiomatic and convenient API for Go
-// programmers.
+// The program generates the code from the API metadata in api.json. Run
+// dumpapi.go to update api.json from the output of nvim --api-info.
+//
+// The overrides declared in apioverrides.go modify names and types to create
+// a more idiomatic and convenient API for Go programmers. Methods for
+// functions without an override are derived from the metadata.
 
 package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/format"
+	"go/token"
+	"io/ioutil"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"text/template"
 )
 
-var extensions = []*struct {
-	Type string
-	Code int
-	Doc  string
-}{
-	{"Buffer", 0, `// Buffer represents a remote Neovim buffer.`},
-	{"Window", 1, `// Window represents a remote Neovim window.`},
-	{"Tabpage", 2, `// Tabpage represents a remote Neovim tabpage.`},
+type apiFunction struct {
+	Name            string      `json:"name"`
+	Parameters      [][2]string `json:"parameters"`
+	ReturnType      string      `json:"return_type"`
+	Method          bool        `json:"method"`
+	Since           int         `json:"since"`
+	DeprecatedSince int         `json:"deprecated_since"`
+}
+
+type apiInfo struct {
+	Functions  []*apiFunction `json:"functions"`
+	UIEvents   []*apiFunction `json:"ui_events"`
+	ErrorTypes map[string]struct {
+		ID int `json:"id"`
+	} `json:"error_types"`
+	Types map[string]struct {
+		ID     int    `json:"id"`
+		Prefix string `json:"prefix"`
+	} `json:"types"`
+}
+
+func readAPIInfo(fname string) (*apiInfo, error) {
+	p, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var info apiInfo
+	if err := json.Unmarshal(p, &info); err != nil {
+		return nil, fmt.Errorf("%s: %v", fname, err)
+	}
+	return &info, nil
+}
+
+// goTypes maps API types to Go types.
+var goTypes = map[string]string{
+	"Boolean":    "bool",
+	"Integer":    "int",
+	"Float":      "float64",
+	"String":     "string",
+	"Object":     "interface{}",
+	"Array":      "[]interface{}",
+	"Dictionary": "map[string]interface{}",
+	"Buffer":     "Buffer",
+	"Window":     "Window",
+	"Tabpage":    "Tabpage",
+	"void":       "",
+}
+
+// goType returns the Go type for API type t.
+func goType(t string) (string, error) {
+	if gt, ok := goTypes[t]; ok {
+		return gt, nil
+	}
+	if strings.HasPrefix(t, "ArrayOf(") && strings.HasSuffix(t, ")") {
+		args := strings.Split(t[len("ArrayOf("):len(t)-1], ",")
+		elem, err := goType(strings.TrimSpace(args[0]))
+		if err != nil {
+			return "", err
+		}
+		switch len(args) {
+		case 1:
+			return "[]" + elem, nil
+		case 2:
+			return "[" + strings.TrimSpace(args[1]) + "]" + elem, nil
+		}
+	}
+	return "", fmt.Errorf("unknown type %s", t)
 }
 
-type param struct{ Name, Type string }
+// words maps words in API function and parameter names to words in Go
+// names.
+var words = map[string]string{
+	"api":     "API",
+	"buf":     "Buffer",
+	"bufs":    "Buffers",
+	"chan":    "Channel",
+	"chans":   "Channels",
+	"del":     "Delete",
+	"dir":     "Dir",
+	"hl":      "Highlight",
+	"id":      "ID",
+	"keymap":  "Keymap",
+	"ns":      "Namespace",
+	"proc":    "Process",
+	"pum":     "Popupmenu",
+	"rgb":     "RGB",
+	"ui":      "UI",
+	"uis":     "UIs",
+	"vvar":    "Vvar",
+	"win":     "Window",
+	"wins":    "Windows",
+	"tabpage": "Tabpage",
+}
 
-var methods = []*struct {
+func goWord(w string) string {
+	if gw, ok := words[w]; ok {
+		return gw
+	}
+	return strings.ToUpper(w[:1]) + w[1:]
+}
+
+// methodName derives a Go method name from an API function name. The API
+// names nvim_{type}_{verb}_{object} and nvim_{verb}_{object} become
+// {Verb}{Type}{Object} and {Verb}{Object}. The verbs "get" and "list" are
+// dropped.
+func methodName(sm string) string {
+	ws := strings.Split(strings.TrimPrefix(sm, "nvim_"), "_")
+	if len(ws) > 1 {
+		switch ws[0] {
+		case "buf", "win", "tabpage", "ui":
+			switch ws[1] {
+			case "get", "set", "del", "is", "attach", "detach", "delete", "close", "hide", "call", "add", "clear", "create", "list", "try":
+				ws[0], ws[1] = ws[1], ws[0]
+			}
+		}
+	}
+	var buf bytes.Buffer
+	for _, w := range ws {
+		if w != "get" && w != "list" {
+			buf.WriteString(goWord(w))
+		}
+	}
+	return buf.String()
+}
+
+// keywordParams maps API parameter names that are Go keywords to Go names.
+var keywordParams = map[string]string{
+	"chan": "channel",
+	"type": "typ",
+}
+
+// paramName derives a Go parameter name from an API parameter name.
+func paramName(name string) string {
+	if n, ok := keywordParams[name]; ok {
+		return n
+	}
+	ws := strings.Split(name, "_")
+	for i := 1; i < len(ws); i++ {
+		ws[i] = goWord(ws[i])
+	}
+	return strings.Join(ws, "")
+}
+
+type method struct {
 	Name   string
 	Sm     string
 	Return string
 	Doc    string
 	Params []param
-}{
-	{
-		Name:   "BufferLineCount",
-		Sm:     "buffer_line_count",
-		Return: "int",
-		Params: []param{{"buffer", "Buffer"}},
-		Doc:    `// BufferLineCount returns the number of lines in the buffer.`,
-	},
-	{
-		Name:   "BufferLines",
-		Sm:     "buffer_get_lines",
-		Return: "[][]byte",
-		Params: []param{{"buffer", "Buffer"}, {"start", "int"}, {"end", "int"}, {"strict", "bool"}},
-		Doc: `
-// BufferLines retrieves a line range from a buffer.
-//
-// Indexing is zero-based, end-exclusive. Negative indices are interpreted as
-// length+1+index, i e -1 refers to the index past the end. So to get the last
-// element set start=-2 and end=-1.
-//
-// Out-of-bounds indices are clamped to the nearest valid value, unless strict
-// = true.
-`,
-	},
-	{
-		Name:   "SetBufferLines",
-		Sm:     "buffer_set_lines",
-		Params: []param{{"buffer", "Buffer"}, {"start", "int"}, {"end", "int"}, {"strict", "bool"}, {"replacement", "[][]byte"}},
-		Doc: `
-// SetBufferLines replaces a line range on a buffer.
-//
-// Indexing is zero-based, end-exclusive. Negative indices are interpreted as
-// length+1+index, ie -1 refers to the index past the end. So to change or
-// delete the last element set start=-2 and end=-1.
-//
-// To insert lines at a given index, set both start and end to the same index.
-// To delete a range of lines, set replacement to an empty array.
-//
-// Out-of-bounds indices are clamped to the nearest valid value, unless strict
-// = true.
-`,
-	},
-	{
-		Name:   "BufferVar",
-		Sm:     "buffer_get_var",
-		Return: "interface{}",
-		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
-		Doc:    `// BufferVar gets a buffer-scoped (b:) variable.`,
-	},
-	{
-		Name:   "SetBufferVar",
-		Sm:     "buffer_set_var",
-		Return: "interface{}",
-		Params: []param{{"buffer", "Buffer"}, {"name", "string"}, {"value", "interface{}"}},
-		Doc: `
-// SetBufferVar sets a buffer-scoped (b:) variable. The value nil deletes the
-// variable. Result is the previous value of the variable.
-`,
-	},
-	{
-		Name:   "BufferOption",
-		Sm:     "buffer_get_option",
-		Return: "interface{}",
-		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
-		Doc:    `// BufferOption gets a buffer option value.`,
-	},
-	{
-		Name:   "SetBufferOption",
-		Sm:     "buffer_set_option",
-		Params: []param{{"buffer", "Buffer"}, {"name", "string"}, {"value", "interface{}"}},
-		Doc: `
-// SetBufferOption sets a buffer option value. The value nil deletes the option
-// in the case where there's a global fallback.
-`,
-	},
-	{
-		Name:   "BufferNumber",
-		Sm:     "buffer_get_number",
-		Return: "int",
-		Params: []param{{"buffer", "Buffer"}},
-		Doc:    `// BufferNumber gets a buffer's number.`,
-	},
-	{
-		Name:   "BufferName",
-		Sm:     "buffer_get_name",
-		Return: "string",
-		Params: []param{{"buffer", "Buffer"}},
-		Doc:    `// BufferName gets the full file name of a buffer.`,
-	},
-	{
-		Name:   "SetBufferName",
-		Sm:     "buffer_set_name",
-		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
-		Doc: `
-// SetBufferName sets the full file name of a buffer.
-// BufFilePre/BufFilePost are triggered.
-`,
-	},
-	{
-		Name:   "IsBufferValid",
-		Sm:     "buffer_is_valid",
-		Return: "bool",
-		Params: []param{{"buffer", "Buffer"}},
-		Doc:    `// IsBufferValid returns true if the buffer is valid.`,
-	},
-	{
-		Name:   "BufferMark",
-		Sm:     "buffer_get_mark",
-		Return: "[2]int",
-		Params: []param{{"buffer", "Buffer"}, {"name", "string"}},
-		Doc:    `// BufferMark returns the (row,col) of the named mark.`,
-	},
-	{
-		Name:   "AddBufferHighlight",
-		Sm:     "buffer_add_highlight",
-		Return: "int",
-		Params: []param{{"buffer", "Buffer"}, {"srcID", "int"}, {"hlGroup", "string"}, {"line", "int"}, {"startCol", "int"}, {"endCol", "int"}},
-		Doc: `
-// AddBufferHighlight adds a highlight to buffer and returns the source id of
-// the highlight.
-//
-// AddBufferHighlight can be used for plugins which dynamically generate
-// highlights to a buffer (like a semantic highlighter or linter). The function
-// adds a single highlight to a buffer. Unlike matchaddpos() highlights follow
-// changes to line numbering (as lines are inserted/removed above the
-// highlighted line), like signs and marks do.
-//
-// The srcID is useful for batch deletion/updating of a set of highlights. When
-// called with srcID = 0, an unique source id is generated and returned.
-// Succesive calls can pass in it as srcID to add new highlights to the same
-// source group. All highlights in the same group can then be cleared with
-// ClearBufferHighlight. If the highlight never will be manually deleted pass
-// in -1 for srcID.
-//
-// If hlGroup is the empty string no highlight is added, but a new srcID is
-// still returned. This is useful for an external plugin to synchrounously
-// request an unique srcID at initialization, and later asynchronously add and
-// clear highlights in response to buffer changes.
-//
-// The startCol and endCol parameters specify the range of columns to
-// highlight. Use endCol = -1 to highlight to the end of the line.
-`,
-	},
-	{
-		Name:   "ClearBufferHighlight",
-		Sm:     "buffer_clear_highlight",
-		Params: []param{{"buffer", "Buffer"}, {"srcID", "int"}, {"startLine", "int"}, {"endLine", "int"}},
-		Doc: `
-// ClearBufferHighlight clears highlights from a given source group and a range
-// of lines.
-//
-// To clear a source group in the entire buffer, pass in 1 and -1 to startLine
-// and endLine respectively.
-//
-// The lineStart and lineEnd parameters specify the range of lines to clear.
-// The end of range is exclusive. Specify -1 to clear to the end of the file.
-`,
-	},
-	{
-		Name:   "TabpageWindows",
-		Sm:     "tabpage_get_windows",
-		Return: "[]Window",
-		Params: []param{{"tabpage", "Tabpage"}},
-		Doc:    `// TabpageWindows returns the windows in a tabpage.`,
-	},
-	{
-		Name:   "TabpageVar",
-		Sm:     "tabpage_get_var",
-		Return: "interface{}",
-		Params: []param{{"tabpage", "Tabpage"}, {"name", "string"}},
-		Doc:    `// TabpageVar gets a tab-scoped (t:) variable.`,
-	},
-	{
-		Name:   "SetTabpageVar",
-		Sm:     "tabpage_set_var",
-		Return: "interface{}",
-		Params: []param{{"tabpage", "Tabpage"}, {"name", "string"}, {"value", "interface{}"}},
-		Doc:    `// SetTabpageVar isets a tab-scoped (t:) variable. A nil value deletes the variable.`,
-	},
-	{
-		Name:   "TabpageWindow",
-		Sm:     "tabpage_get_window",
-		Return: "Window",
-		Params: []param{{"tabpage", "Tabpage"}},
-		Doc:    `// TabpageWindow gets the current window in a tab page.`,
-	},
-	{
-		Name:   "IsTabpageValid",
-		Sm:     "tabpage_is_valid",
-		Return: "bool",
-		Params: []param{{"tabpage", "Tabpage"}},
-		Doc:    `// IsTabpageValid checks if a tab page is valid.`,
-	},
-	{
-		Name:   "Command",
-		Sm:     "vim_command",
-		Params: []param{{"str", "string"}},
-		Doc:    `// Command executes a single ex command.`,
-	},
-	{
-		Name:   "FeedKeys",
-		Sm:     "vim_feedkeys",
-		Params: []param{{"keys", "string"}, {"mode", "string"}, {"escapeCsi", "bool"}},
-		Doc: `
-// FeedKeys Pushes keys to the Neovim user input buffer. Options can be a string
-// with the following character flags:
-//
-//  m:  Remap keys. This is default.
-//  n:  Do not remap keys.
-//  t:  Handle keys as if typed; otherwise they are handled as if coming from a
-//     mapping. This matters for undo, opening folds, etc. 
-`,
-	},
-	{
-		Name:   "Input",
-		Sm:     "vim_input",
-		Return: "int",
-		Params: []param{{"keys", "string"}},
-		Doc: `
-// Input pushes bytes to the Neovim low level input buffer.
-// 
-// Unlike FeedKeys, this uses the lowest level input buffer and the call is not
-// deferred. It returns the number of bytes actually written(which can be less
-// than what was requested if the buffer is full).
-`,
-	},
-	{
-		Name:   "ReplaceTermcodes",
-		Sm:     "vim_replace_termcodes",
-		Return: "string",
-		Params: []param{{"str", "string"}, {"fromPart", "bool"}, {"doLt", "bool"}, {"special", "bool"}},
-		Doc: `
-// ReplaceTermcodes replaces any terminal code strings by byte sequences. The
-// returned sequences are Nvim's internal representation of keys, for example:
-//
-//  <esc> -> '\x1b'
-//  <cr>  -> '\r'
-//  <c-l> -> '\x0c'
-//  <up>  -> '\x80ku'
-//
-// The returned sequences can be used as input to feedkeys.
-`,
-	},
-	{
-		Name:   "CommandOutput",
-		Sm:     "vim_command_output",
-		Return: "string",
-		Params: []param{{"str", "string"}},
-		Doc: `
-// CommandOutput executes a single ex command and returns the output.
-`,
-	},
-	{
-		Name:   "Eval",
-		Sm:     "vim_eval",
-		Return: "interface{}",
-		Params: []param{{"str", "string"}},
-		Doc: `
-// Eval evaluates the expression str using the Vim internal expression
-// evaluator. 
-//
-//  :help expression
-`,
-	},
-	{
-		Name:   "Strwidth",
-		Sm:     "vim_strwidth",
-		Return: "int",
-		Params: []param{{"str", "string"}},
-		Doc: `
-// Strwidth returns the number of display cells the string occupies. Tab is
-// counted as one cell.
-`,
-	},
-	{
-		Name:   "RuntimePaths",
-		Sm:     "vim_list_runtime_paths",
-		Return: "[]string",
-		Doc: `
-// RuntimePaths returns a list of paths contained in the runtimepath option.
-`,
-	},
-	{
-		Name:   "ChangeDirectory",
-		Sm:     "vim_change_directory",
-		Params: []param{{"dir", "string"}},
-		Doc:    `// ChangeDirectory changes Vim working directory.`,
-	},
-	{
-		Name:   "CurrentLine",
-		Sm:     "vim_get_current_line",
-		Return: "[]byte",
-		Doc:    `// CurrentLine gets the current line in the current buffer.`,
-	},
-	{
-		Name:   "SetCurrentLine",
-		Sm:     "vim_set_current_line",
-		Params: []param{{"line", "[]byte"}},
-		Doc:    `// SetCurrentLine sets the current line in the current buffer.`,
-	},
-	{
-		Name: "DeleteCurrentLine",
-		Sm:   "vim_del_current_line",
-		Doc:  `// DeleteCurrentLine deletes the current line in the current buffer.`,
-	},
-	{
-		Name:   "Var",
-		Sm:     "vim_get_var",
-		Return: "interface{}",
-		Params: []param{{"name", "string"}},
-		Doc:    `// Var gets a global (g:) variable.`,
-	},
-	{
-		Name:   "SetVar",
-		Sm:     "vim_set_var",
-		Return: "interface{}",
-		Params: []param{{"name", "string"}, {"value", "interface{}"}},
-		Doc: `
-// SetVar sets a global (g:) variable. The value nil deletes the variable.
-// Result is the previous value of the variable.
-`,
-	},
-	{
-		Name:   "Vvar",
-		Sm:     "vim_get_vvar",
-		Return: "interface{}",
-		Params: []param{{"name", "string"}},
-		Doc:    `// Vvar gets a vim (v:) variable.`,
-	},
-	{
-		Name:   "Option",
-		Sm:     "vim_get_option",
-		Return: "interface{}",
-		Params: []param{{"name", "string"}},
-		Doc:    `// Option gets an option.`,
-	},
-	{
-		Name:   "SetOption",
-		Sm:     "vim_set_option",
-		Params: []param{{"name", "string"}, {"value", "interface{}"}},
-		Doc:    `// SetOption sets an option.`,
-	},
-	{
-		Name:   "WriteOut",
-		Sm:     "vim_out_write",
-		Params: []param{{"str", "string"}},
-		Doc: `
-// WriteOut writes a message to vim output buffer. The string is split and
-// flushed after each newline. Incomplete lines are kept for writing later.
-`,
-	},
-	{
-		Name:   "WriteErr",
-		Sm:     "vim_err_write",
-		Params: []param{{"str", "string"}},
-		Doc: `
-// WriteErr writes a message to vim error buffer. The string is split and
-// flushed after each newline. Incomplete lines are kept for writing later.
-`,
-	},
-	{
-		Name:   "ReportError",
-		Sm:     "vim_report_error",
-		Params: []param{{"str", "string"}},
-		Doc:    `// ReportError writes prints str and a newline as an error message.`,
-	},
-	{
-		Name:   "Buffers",
-		Sm:     "vim_get_buffers",
-		Return: "[]Buffer",
-		Doc:    `// Buffers returns the current list of buffers.`,
-	},
-	{
-		Name:   "CurrentBuffer",
-		Sm:     "vim_get_current_buffer",
-		Return: "Buffer",
-		Doc:    `// CurrentBuffer returns the current buffer.`,
-	},
-	{
-		Name:   "SetCurrentBuffer",
-		Sm:     "vim_set_current_buffer",
-		Params: []param{{"buffer", "Buffer"}},
-		Doc:    `// SetCurrentBuffer sets the current buffer.`,
-	},
-	{
-		Name:   "Windows",
-		Sm:     "vim_get_windows",
-		Return: "[]Window",
-		Doc:    `// Windows returns the current list of windows.`,
-	},
-	{
-		Name:   "CurrentWindow",
-		Sm:     "vim_get_current_window",
-		Return: "Window",
-		Doc:    `// CurrentWindow returns the current window.`,
-	},
-	{
-		Name:   "SetCurrentWindow",
-		Sm:     "vim_set_current_window",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// SetCurrentWindow sets the current window.`,
-	},
-	{
-		Name:   "Tabpages",
-		Sm:     "vim_get_tabpages",
-		Return: "[]Tabpage",
-		Doc:    `// Tabpages returns the current list of tabpages.`,
-	},
-	{
-		Name:   "CurrentTabpage",
-		Sm:     "vim_get_current_tabpage",
-		Return: "Tabpage",
-		Doc:    `// CurrentTabpage returns the current tabpage.`,
-	},
-	{
-		Name:   "SetCurrentTabpage",
-		Sm:     "vim_set_current_tabpage",
-		Params: []param{{"tabpage", "Tabpage"}},
-		Doc:    `// SetCurrentTabpage sets the current tabpage.`,
-	},
-	{
-		Name:   "Subscribe",
-		Sm:     "vim_subscribe",
-		Params: []param{{"event", "string"}},
-		Doc:    `// Subscribe subscribes to a Neovim event.`,
-	},
-	{
-		Name:   "Unsubscribe",
-		Sm:     "vim_unsubscribe",
-		Params: []param{{"event", "string"}},
-		Doc:    `// Unsubscribe unsubscribes to a Neovim event.`,
-	},
-	{
-		Name:   "NameToColor",
-		Sm:     "vim_name_to_color",
-		Return: "int",
-		Params: []param{{"name", "string"}},
-	},
-	{
-		Name:   "ColorMap",
-		Sm:     "vim_get_color_map",
-		Return: "map[string]interface{}",
-	},
-	{
-		Name:   "APIInfo",
-		Sm:     "vim_get_api_info",
-		Return: "[]interface{}",
-	},
-	{
-		Name:   "WindowBuffer",
-		Sm:     "window_get_buffer",
-		Return: "Buffer",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// WindowBuffer returns the current buffer in a window.`,
-	},
-	{
-		Name:   "WindowCursor",
-		Sm:     "window_get_cursor",
-		Return: "[2]int",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// WindowCursor returns the cursor position in the window.`,
-	},
-	{
-		Name:   "SetWindowCursor",
-		Sm:     "window_set_cursor",
-		Params: []param{{"window", "Window"}, {"pos", "[2]int"}},
-		Doc:    `// SetWindowCursor sets the cursor position in the window to the given position.`,
-	},
-	{
-		Name:   "WindowHeight",
-		Sm:     "window_get_height",
-		Return: "int",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// WindowHeight returns the window height.`,
-	},
-	{
-		Name:   "SetWindowHeight",
-		Sm:     "window_set_height",
-		Params: []param{{"window", "Window"}, {"height", "int"}},
-		Doc:    `// SetWindowHeight sets the window height.`,
-	},
-	{
-		Name:   "WindowWidth",
-		Sm:     "window_get_width",
-		Return: "int",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// WindowWidth returns the window width.`,
-	},
-	{
-		Name:   "SetWindowWidth",
-		Sm:     "window_set_width",
-		Params: []param{{"window", "Window"}, {"width", "int"}},
-		Doc:    `// SetWindowWidth sets the window width.`,
-	},
-	{
-		Name:   "WindowVar",
-		Sm:     "window_get_var",
-		Return: "interface{}",
-		Params: []param{{"window", "Window"}, {"name", "string"}},
-		Doc:    `// WindowVar gets a window-scoped (w:) variable.`,
-	},
-	{
-		Name:   "SetWindowVar",
-		Sm:     "window_set_var",
-		Return: "interface{}",
-		Params: []param{{"window", "Window"}, {"name", "string"}, {"value", "interface{}"}},
-		Doc:    `// SetWindowVar sets a window-scoped (w:) variable.`,
-	},
-	{
-		Name:   "WindowOption",
-		Sm:     "window_get_option",
-		Return: "interface{}",
-		Params: []param{{"window", "Window"}, {"name", "string"}},
-		Doc:    `// WindowOption gets a window option.`,
-	},
-	{
-		Name:   "SetWindowOption",
-		Sm:     "window_set_option",
-		Params: []param{{"window", "Window"}, {"name", "string"}, {"value", "interface{}"}},
-		Doc:    `// SetWindowOption sets a window option.`,
-	},
-	{
-		Name:   "WindowPosition",
-		Sm:     "window_get_position",
-		Return: "[2]int",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// WindowPosition gets the window position in display cells. First position is zero.`,
-	},
-	{
-		Name:   "WindowTabpage",
-		Sm:     "window_get_tabpage",
-		Return: "Tabpage",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// WindowTabpage gets the tab page that contains the window.`,
-	},
-	{
-		Name:   "IsWindowValid",
-		Sm:     "window_is_valid",
-		Return: "bool",
-		Params: []param{{"window", "Window"}},
-		Doc:    `// IsWindowValid returns true if the window is valid.`,
-	},
+}
+
+// deprecatedDoc returns the deprecation paragraph for the method for f.
+func deprecatedDoc(f *apiFunction, names map[string]string) string {
+	if r := replacements[f.Name]; r != "" {
+		return fmt.Sprintf("Deprecated: Use %s instead.", names[r])
+	}
+	return fmt.Sprintf("Deprecated: %s is deprecated since API level %d.", f.Name, f.DeprecatedSince)
+}
+
+func newMethod(f *apiFunction, o *override) (*method, error) {
+	m := &method{Sm: f.Name}
+
+	if o != nil && o.Params != nil {
+		if len(o.Params) != len(f.Parameters) {
+			return nil, fmt.Errorf("%s: override has %d params, function has %d", f.Name, len(o.Params), len(f.Parameters))
+		}
+		m.Params = o.Params
+	} else {
+		for _, p := range f.Parameters {
+			t, err := goType(p[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", f.Name, err)
+			}
+			name := paramName(p[1])
+			if token.Lookup(name).IsKeyword() {
+				return nil, fmt.Errorf("%s: parameter name %s is a keyword", f.Name, name)
+			}
+			m.Params = append(m.Params, param{name, t})
+		}
+	}
+
+	if o != nil && o.Return != "" {
+		m.Return = o.Return
+	} else {
+		var err error
+		m.Return, err = goType(f.ReturnType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+	}
+
+	if o != nil && o.Name != "" {
+		m.Name = o.Name
+	} else {
+		m.Name = methodName(f.Name)
+	}
+
+	if o != nil && o.Doc != "" {
+		m.Doc = strings.TrimSpace(o.Doc)
+	} else {
+		m.Doc = fmt.Sprintf("// %s calls the %s API function.\n//\n//  :help %s()", m.Name, f.Name, f.Name)
+	}
+	return m, nil
+}
+
+func hasLuaRef(f *apiFunction) bool {
+	for _, p := range f.Parameters {
+		if p[0] == "LuaRef" {
+			return true
+		}
+	}
+	return false
+}
+
+// renamedPrefixes maps the prefixes of API level 0 functions to the prefixes
+// of the functions that replace them.
+var renamedPrefixes = [][2]string{
+	{"buffer_", "nvim_buf_"},
+	{"window_", "nvim_win_"},
+	{"tabpage_", "nvim_tabpage_"},
+	{"vim_", "nvim_"},
+	{"ui_", "nvim_ui_"},
+}
+
+// replacement returns the function that replaces deprecated function f.
+func replacement(f *apiFunction, functions map[string]*apiFunction) (string, error) {
+	r, ok := replacements[f.Name]
+	for _, rp := range renamedPrefixes {
+		if !ok && strings.HasPrefix(f.Name, rp[0]) {
+			r, ok = rp[1]+f.Name[len(rp[0]):], true
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("%s: no replacement for deprecated function", f.Name)
+	}
+	if r == "" {
+		return "", nil
+	}
+	g := functions[r]
+	switch {
+	case g == nil:
+		return "", fmt.Errorf("%s: replacement %s not found", f.Name, r)
+	case g.DeprecatedSince != 0:
+		// The replacement is also deprecated.
+		return replacement(g, functions)
+	}
+	return r, nil
+}
+
+type uiEvent struct {
+	Name        string
+	Type        string
+	Fields      []param
+	HandWritten bool
+}
+
+// fieldName derives a Go field name from an API parameter name.
+func fieldName(name string) string {
+	var buf bytes.Buffer
+	for _, w := range strings.Split(name, "_") {
+		buf.WriteString(goWord(w))
+	}
+	return buf.String()
+}
+
+func newUIEvents(info *apiInfo) ([]*uiEvent, error) {
+	var events []*uiEvent
+	used := make(map[string]bool)
+	for _, e := range info.UIEvents {
+		ev := &uiEvent{
+			Name:        e.Name,
+			Type:        fieldName(e.Name) + "Event",
+			HandWritten: uiHandWritten[e.Name],
+		}
+		for _, p := range e.Parameters {
+			t, ok := uiParamTypes[e.Name+"."+p[1]]
+			used[e.Name+"."+p[1]] = ok
+			if !ok {
+				var err error
+				t, err = goType(p[0])
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", e.Name, err)
+				}
+			}
+			ev.Fields = append(ev.Fields, param{fieldName(p[1]), t})
+		}
+		events = append(events, ev)
+	}
+	for k := range uiParamTypes {
+		if !used[k] {
+			return nil, fmt.Errorf("%s: type for unknown UI event parameter", k)
+		}
+	}
+	return events, nil
+}
+
+func generate(info *apiInfo) ([]byte, error) {
+	functions := make(map[string]*apiFunction)
+	for _, f := range info.Functions {
+		functions[f.Name] = f
+	}
+
+	overridesBySm := make(map[string]*override)
+	for _, o := range overrides {
+		if functions[o.Sm] == nil {
+			return nil, fmt.Errorf("%s: override for unknown function", o.Sm)
+		}
+		if skipped[o.Sm] {
+			return nil, fmt.Errorf("%s: override for skipped function", o.Sm)
+		}
+		overridesBySm[o.Sm] = o
+	}
+
+	var (
+		methods    []*method
+		aliases    [][2]string
+		goNames    = make(map[string]string)
+		deprecated = make(map[string]*apiFunction)
+	)
+	for _, f := range info.Functions {
+		o := overridesBySm[f.Name]
+		if f.DeprecatedSince != 0 {
+			r, err := replacement(f, functions)
+			if err != nil {
+				return nil, err
+			}
+			aliases = append(aliases, [2]string{f.Name, r})
+			if o == nil {
+				continue
+			}
+			deprecated[f.Name] = f
+		}
+		if hasLuaRef(f) || skipped[f.Name] {
+			continue
+		}
+		m, err := newMethod(f, o)
+		if err != nil {
+			return nil, err
+		}
+		if sm, ok := goNames[m.Name]; ok {
+			return nil, fmt.Errorf("%s and %s have the same method name %s", sm, f.Name, m.Name)
+		}
+		goNames[m.Name] = f.Name
+		methods = append(methods, m)
+	}
+
+	smNames := make(map[string]string)
+	for name, sm := range goNames {
+		smNames[sm] = name
+	}
+	for _, m := range methods {
+		if f := deprecated[m.Sm]; f != nil && !strings.Contains(m.Doc, "Deprecated:") {
+			m.Doc += "\n//\n// " + deprecatedDoc(f, smNames)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i][0] < aliases[j][0] })
+
+	levels := make(map[string]int)
+	for _, f := range info.Functions {
+		if f.DeprecatedSince == 0 && f.Since > 1 {
+			levels[f.Name] = f.Since
+		}
+	}
+
+	errorTypes := make(map[string]int)
+	for name, et := range info.ErrorTypes {
+		errorTypes[strings.ToLower(name)] = et.ID
+	}
+
+	type extension struct {
+		Type string
+		Code int
+		Doc  string
+	}
+	var exts []*extension
+	for _, e := range extensions {
+		t, ok := info.Types[e.Type]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in API metadata", e.Type)
+		}
+		exts = append(exts, &extension{Type: e.Type, Code: t.ID, Doc: e.Doc})
+	}
+
+	uiEvents, err := newUIEvents(info)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := templ.Execute(&buf, map[string]interface{}{
+		"UIEvents":   uiEvents,
+		"Methods":    methods,
+		"Extensions": exts,
+		"ErrorTypes": errorTypes,
+		"Aliases":    aliases,
+		"Levels":     levels,
+	}); err != nil {
+		return nil, fmt.Errorf("error executing template: %v", err)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		for i, p := range bytes.Split(buf.Bytes(), []byte("\n")) {
+			fmt.Fprintf(os.Stderr, "%d: %s\n", i+1, p)
+		}
+		return nil, fmt.Errorf("error formating source: %v", err)
+	}
+	return out, nil
 }
 
 var templ = template.Must(template.New("").Parse(`// Code generated by 'go generate'
@@ -591,6 +446,7 @@ var templ = template.Must(template.New("").Parse(`// Code generated by 'go gener
 package vim
 
 import (
+    "context"
     "fmt"
     "reflect"
 
@@ -599,9 +455,20 @@ import (
 )
 
 const (
-    exceptionError  = 0
-    validationError = 1
-)
+{{range $name, $id := .ErrorTypes}}    {{$name}}Error = {{$id}}
+{{end}})
+
+// deprecatedFunctions maps deprecated API functions to the function that
+// replaces the deprecated function.
+var deprecatedFunctions = map[string]string{
+{{range .Aliases}}    "{{index . 0}}": "{{index . 1}}",
+{{end}}}
+
+// functionLevels maps API functions added after API level 1 to the API level
+// where the function was added.
+var functionLevels = map[string]int{
+{{range $sm, $level := .Levels}}    "{{$sm}}": {{$level}},
+{{end}}}
 
 func withExtensions() rpc.Option {
 	return rpc.WithExtensions(msgpack.ExtensionMap{
@@ -648,6 +515,11 @@ func (v *Vim) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}} result inte
     return v.call("{{.Sm}}", result, {{range .Params}}{{.Name}},{{end}})
 }
 
+// {{.Name}}Context is like {{.Name}} with a context for cancelling the call.
+func (v *Vim) {{.Name}}Context(ctx context.Context, {{range .Params}}{{.Name}} {{.Type}},{{end}} result interface{}) error {
+    return v.callContext(ctx, "{{.Sm}}", result, {{range .Params}}{{.Name}},{{end}})
+}
+
 {{.Doc}}
 func (p *Pipeline) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}} result interface{}) {
     p.call("{{.Sm}}", result, {{range .Params}}{{.Name}},{{end}})
@@ -659,6 +531,14 @@ func (v *Vim) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}}) ({{.Return
     err := v.call("{{.Sm}}", {{if .Return}}&result{{else}}nil{{end}}, {{range .Params}}{{.Name}},{{end}})
     return result, err
 }
+
+// {{.Name}}Context is like {{.Name}} with a context for cancelling the call.
+func (v *Vim) {{.Name}}Context(ctx context.Context, {{range .Params}}{{.Name}} {{.Type}},{{end}}) ({{.Return}}, error) {
+    var result {{.Return}}
+    err := v.callContext(ctx, "{{.Sm}}", &result, {{range .Params}}{{.Name}},{{end}})
+    return result, err
+}
+
 {{.Doc}}
 func (p *Pipeline) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}} result *{{.Return}}) {
     p.call("{{.Sm}}", result, {{range .Params}}{{.Name}},{{end}})
@@ -668,37 +548,57 @@ func (p *Pipeline) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}} result
 func (v *Vim) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}}) error {
     return v.call("{{.Sm}}", nil, {{range .Params}}{{.Name}},{{end}})
 }
+
+// {{.Name}}Context is like {{.Name}} with a context for cancelling the call.
+func (v *Vim) {{.Name}}Context(ctx context.Context, {{range .Params}}{{.Name}} {{.Type}},{{end}}) error {
+    return v.callContext(ctx, "{{.Sm}}", nil, {{range .Params}}{{.Name}},{{end}})
+}
+
 {{.Doc}}
 func (p *Pipeline) {{.Name}}({{range .Params}}{{.Name}} {{.Type}},{{end}}) {
     p.call("{{.Sm}}", nil, {{range .Params}}{{.Name}},{{end}})
 }
 {{end}}
 {{end}}
+
+// redrawEventTypes maps UI event names to functions that return a new event
+// of the corresponding Go type.
+var redrawEventTypes = map[string]func() RedrawEvent{
+{{range .UIEvents}}    "{{.Name}}": func() RedrawEvent { return new({{.Type}}) },
+{{end}}}
+
+{{range .UIEvents}}{{if not .HandWritten}}
+// {{.Type}} is the {{.Name}} UI event.
+//
+//  :help ui-event-{{.Name}}
+{{if .Fields}}type {{.Type}} struct {
+{{range $i, $f := .Fields}}    {{$f.Name}} {{$f.Type}} {{if eq $i 0}}` + "`msgpack:\",array\"`" + `{{end}}
+{{end}}}
+{{else}}type {{.Type}} struct{}
+
+func (*{{.Type}}) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+    return dec.Skip()
+}
+{{end}}
+{{end}}
+func (*{{.Type}}) redrawEvent() {}
+{{end}}
 `))
 
 func main() {
 	log.SetFlags(0)
 	outFile := flag.String("out", "", "Output file")
+	apiFile := flag.String("api", "api.json", "API metadata file")
 	flag.Parse()
 
-	for _, m := range methods {
-		m.Doc = strings.TrimSpace(m.Doc)
-	}
-
-	var buf bytes.Buffer
-	if err := templ.Execute(&buf, map[string]interface{}{
-		"Methods":    methods,
-		"Extensions": extensions,
-	}); err != nil {
-		log.Fatalf("error executing template: %v", err)
+	info, err := readAPIInfo(*apiFile)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	out, err := format.Source(buf.Bytes())
+	out, err := generate(info)
 	if err != nil {
-		for i, p := range bytes.Split(buf.Bytes(), []byte("\n")) {
-			fmt.Fprintf(os.Stderr, "%d: %s\n", i+1, p)
-		}
-		log.Fatalf("error formating source: %v", err)
+		log.Fatal(err)
 	}
 
 	f := os.Stdout
@@ -45,7 +45,7 @@ type QuickfixError struct {
 // CommandCompletionArgs represents the arguments to a custom command line
 // completion function.
 //
-//  :help :command-completion-custom
+//	:help :command-completion-custom
 type CommandCompletionArgs struct {
 	// ArgLead is the leading portion of the argument currently being completed
 	// on.
@@ -0,0 +1,99 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+// ExecLua executes Lua code. The arguments are available in the code as
+// "...". The value returned by the code is decoded to result.
+//
+//	err := v.ExecLua("return vim.fn.expand(...)", &result, "%:p")
+//
+//	:help nvim_exec_lua()
+func (v *Vim) ExecLua(code string, result interface{}, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return v.call("nvim_exec_lua", result, code, args)
+}
+
+// ExecLua executes Lua code.
+//
+//	:help nvim_exec_lua()
+func (p *Pipeline) ExecLua(code string, result interface{}, args ...interface{}) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	p.call("nvim_exec_lua", result, code, args)
+}
+
+// LuaModule is a Lua module shipped with a Go program, for example with the
+// go:embed directive. The source of the module is a chunk that returns the
+// module table:
+//
+//	local M = {}
+//	function M.greet(name) return "hello " .. name end
+//	return M
+//
+// The module is loaded into a Neovim session on the first call to a function
+// in the module. After the module is loaded, Lua code in the session can use
+// the module with require(name).
+type LuaModule struct {
+	name   string
+	source string
+}
+
+// NewLuaModule returns a Lua module with the given name and source.
+func NewLuaModule(name, source string) *LuaModule {
+	return &LuaModule{name: name, source: source}
+}
+
+// Name returns the name of the module.
+func (m *LuaModule) Name() string {
+	return m.name
+}
+
+const (
+	// loadLuaModule evaluates the source of a module and stores the module
+	// in package.loaded.
+	loadLuaModule = `local name, source = ...
+local chunk, err = loadstring(source, "=" .. name)
+if not chunk then error(err) end
+package.loaded[name] = chunk() or true`
+
+	// callLuaModule calls a function in a loaded module.
+	callLuaModule = `local name, fn = ...
+local m = package.loaded[name]
+if type(m) ~= "table" or type(m[fn]) ~= "function" then
+  error("module " .. name .. " does not have function " .. fn)
+end
+return m[fn](select(3, ...))`
+)
+
+// Load loads the module into the Neovim session for v if the module is not
+// loaded. Call calls Load.
+func (m *LuaModule) Load(v *Vim) error {
+	v.luaMu.Lock()
+	defer v.luaMu.Unlock()
+	if v.luaModules[m] {
+		return nil
+	}
+	if err := v.ExecLua(loadLuaModule, nil, m.name, m.source); err != nil {
+		return err
+	}
+	if v.luaModules == nil {
+		v.luaModules = make(map[*LuaModule]bool)
+	}
+	v.luaModules[m] = true
+	return nil
+}
+
+// Call calls the function fn in the module with the arguments and decodes
+// the value returned by the function to result. Call loads the module if the
+// module is not loaded.
+func (m *LuaModule) Call(v *Vim, fn string, result interface{}, args ...interface{}) error {
+	if err := m.Load(v); err != nil {
+		return err
+	}
+	return v.ExecLua(callLuaModule, result, append([]interface{}{m.name, fn}, args...)...)
+}
@@ -0,0 +1,76 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestExecLua(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	type luaCall struct {
+		code string
+		args []interface{}
+	}
+	var calls []luaCall
+	f.StubLua(func(code string, args []interface{}) (interface{}, error) {
+		calls = append(calls, luaCall{code, args})
+		if len(args) > 1 && args[1] == "greet" {
+			return map[string]interface{}{"text": "hello " + args[2].(string)}, nil
+		}
+		return len(args), nil
+	})
+
+	var n int
+	if err := v.ExecLua("return select('#', ...)", &n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 || len(calls) != 1 {
+		t.Fatalf("ExecLua with no arguments = %d, sent %+v, want 0", n, calls)
+	}
+
+	const source = "return {greet = function(name) return {text = 'hello ' .. name} end}"
+	m := vim.NewLuaModule("greeter", source)
+	var result struct {
+		Text string `msgpack:"text"`
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.Call(v, "greet", &result, "world"); err != nil {
+			t.Fatal(err)
+		}
+		if result.Text != "hello world" {
+			t.Errorf("Call() result = %q, want hello world", result.Text)
+		}
+	}
+
+	// The module is loaded once, then called twice.
+	if len(calls) != 4 {
+		t.Fatalf("got %d Lua calls, want 4", len(calls))
+	}
+	load := calls[1]
+	if !reflect.DeepEqual(load.args, []interface{}{"greeter", source}) {
+		t.Errorf("load args = %v, want name and source", load.args)
+	}
+	for _, c := range calls[2:] {
+		if !reflect.DeepEqual(c.args, []interface{}{"greeter", "greet", "world"}) {
+			t.Errorf("call args = %v, want greeter, greet, world", c.args)
+		}
+	}
+
+	p := v.NewPipeline()
+	p.ExecLua("return ...", nil, 1, "two")
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if args := calls[len(calls)-1].args; !reflect.DeepEqual(args, []interface{}{int64(1), "two"}) {
+		t.Errorf("pipeline args = %#v, want 1, two", args)
+	}
+}
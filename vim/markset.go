@@ -0,0 +1,175 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import "sync"
+
+// MarkSet maps application keys to extmarks in a namespace. Use a MarkSet to
+// annotate positions in buffers and to find where the positions moved after
+// the buffers are edited.
+//
+// The keys are compared using Go equality and must be comparable. A key
+// refers to at most one mark. The methods are safe to call concurrently. When
+// calls for the same key run concurrently, the last call to complete wins.
+type MarkSet struct {
+	v    *Vim
+	nsID int
+
+	// mu protects marks. The lock is not held while calling Neovim.
+	mu    sync.Mutex
+	marks map[interface{}]markRef
+}
+
+type markRef struct {
+	buffer Buffer
+	id     int
+}
+
+// NewMarkSet creates a mark set for the named namespace. The namespace is
+// created if it does not exist.
+func NewMarkSet(v *Vim, namespace string) (*MarkSet, error) {
+	nsID, err := v.CreateNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkSet{v: v, nsID: nsID, marks: make(map[interface{}]markRef)}, nil
+}
+
+// NamespaceID returns the id of the namespace for the marks.
+func (s *MarkSet) NamespaceID() int {
+	return s.nsID
+}
+
+// Set sets the mark for key to the zero-based row and column in buffer b. If
+// key has a mark in b, then the mark is moved. If key has a mark in another
+// buffer, then that mark is deleted. The ID field of opts is ignored.
+func (s *MarkSet) Set(key interface{}, b Buffer, row, col int, opts *ExtmarkOptions) error {
+	var o ExtmarkOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.ID = 0
+
+	s.mu.Lock()
+	ref, ok := s.marks[key]
+	s.mu.Unlock()
+
+	if ok {
+		if ref.buffer == b {
+			o.ID = ref.id
+		} else {
+			if _, err := s.v.DeleteBufferExtmark(ref.buffer, s.nsID, ref.id); err != nil {
+				return err
+			}
+			s.remove(key, ref)
+		}
+	}
+	id, err := s.v.SetBufferExtmark(b, s.nsID, row, col, &o)
+	if err != nil {
+		return err
+	}
+
+	newRef := markRef{buffer: b, id: id}
+	s.mu.Lock()
+	cur, replaced := s.marks[key]
+	s.marks[key] = newRef
+	s.mu.Unlock()
+
+	// A concurrent call to Set created or restored another mark for key.
+	// Delete that mark so that key refers to at most one mark.
+	if replaced && cur != newRef {
+		_, err = s.v.DeleteBufferExtmark(cur.buffer, s.nsID, cur.id)
+	}
+	return err
+}
+
+// remove deletes key from the set if key refers to ref.
+func (s *MarkSet) remove(key interface{}, ref markRef) {
+	s.mu.Lock()
+	if cur, ok := s.marks[key]; ok && cur == ref {
+		delete(s.marks, key)
+	}
+	s.mu.Unlock()
+}
+
+// Buffer returns the buffer for the mark for key. The ok result is false if
+// key does not have a mark.
+func (s *MarkSet) Buffer(key interface{}) (b Buffer, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ref, ok := s.marks[key]
+	return ref.buffer, ok
+}
+
+// Get returns the current position of the mark for key. The ok result is
+// false if key does not have a mark or if Neovim deleted the mark, for
+// example when the buffer was wiped out.
+func (s *MarkSet) Get(key interface{}) (m Extmark, ok bool, err error) {
+	s.mu.Lock()
+	ref, ok := s.marks[key]
+	s.mu.Unlock()
+	if !ok {
+		return Extmark{}, false, nil
+	}
+	m, err = s.v.BufferExtmark(ref.buffer, s.nsID, ref.id, true)
+	if err != nil {
+		return Extmark{}, false, err
+	}
+	if m.ID == 0 {
+		s.remove(key, ref)
+		return Extmark{}, false, nil
+	}
+	return m, true, nil
+}
+
+// Marks returns the current positions of the marks in buffer b by key.
+func (s *MarkSet) Marks(b Buffer) (map[interface{}]Extmark, error) {
+	marks, err := s.v.BufferExtmarks(b, s.nsID, [2]int{0, 0}, [2]int{-1, -1}, &ExtmarksOptions{Details: true})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Extmark, len(marks))
+	for _, m := range marks {
+		byID[m.ID] = m
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[interface{}]Extmark)
+	for key, ref := range s.marks {
+		if ref.buffer != b {
+			continue
+		}
+		if m, ok := byID[ref.id]; ok {
+			result[key] = m
+		}
+	}
+	return result, nil
+}
+
+// Delete deletes the mark for key.
+func (s *MarkSet) Delete(key interface{}) error {
+	s.mu.Lock()
+	ref, ok := s.marks[key]
+	delete(s.marks, key)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	_, err := s.v.DeleteBufferExtmark(ref.buffer, s.nsID, ref.id)
+	return err
+}
+
+// Clear deletes the marks in buffer b.
+func (s *MarkSet) Clear(b Buffer) error {
+	s.mu.Lock()
+	for key, ref := range s.marks {
+		if ref.buffer == b {
+			delete(s.marks, key)
+		}
+	}
+	s.mu.Unlock()
+	return s.v.ClearBufferNamespace(b, s.nsID, 0, -1)
+}
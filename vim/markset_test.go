@@ -0,0 +1,125 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestMarkSet(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b1 := f.NewBuffer("", "a", "b", "c")
+	b2 := f.NewBuffer("", "x")
+
+	s, err := vim.NewMarkSet(v, "marks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type key struct{ name string }
+	if err := s.Set(key{"first"}, b1, 0, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(key{"second"}, b1, 2, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move a mark within the buffer.
+	if err := s.Set(key{"second"}, b1, 1, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	marks, err := v.BufferExtmarks(b1, s.NamespaceID(), [2]int{0, 0}, [2]int{-1, -1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marks) != 2 {
+		t.Errorf("buffer has %d marks, want 2", len(marks))
+	}
+
+	// Edit the buffer and find the marks.
+	if err := v.SetBufferLines(b1, 0, 0, true, [][]byte{[]byte("new")}); err != nil {
+		t.Fatal(err)
+	}
+	m, ok, err := s.Get(key{"second"})
+	if err != nil || !ok || m.Row != 2 {
+		t.Errorf("Get(second) = %+v, %v, %v, want row 2", m, ok, err)
+	}
+	byKey, err := s.Marks(b1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byKey) != 2 || byKey[key{"first"}].Row != 1 || byKey[key{"second"}].Row != 2 {
+		t.Errorf("Marks() = %+v, want first at row 1 and second at row 2", byKey)
+	}
+
+	// Move a mark to another buffer.
+	if err := s.Set(key{"first"}, b2, 0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := s.Buffer(key{"first"}); !ok || b != b2 {
+		t.Errorf("Buffer(first) = %v, %v, want %v", b, ok, b2)
+	}
+	if byKey, err := s.Marks(b1); err != nil || len(byKey) != 1 {
+		t.Errorf("Marks() = %+v, %v, want one mark", byKey, err)
+	}
+
+	if err := s.Delete(key{"first"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := s.Get(key{"first"}); err != nil || ok {
+		t.Errorf("Get(first) after delete = %v, %v, want false", ok, err)
+	}
+	if err := s.Clear(b1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := s.Get(key{"second"}); err != nil || ok {
+		t.Errorf("Get(second) after clear = %v, %v, want false", ok, err)
+	}
+}
+
+func TestMarkSetConcurrent(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	buffers := []vim.Buffer{f.NewBuffer("", "a"), f.NewBuffer("", "b")}
+	s, err := vim.NewMarkSet(v, "marks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Concurrent calls for the same key leave one mark.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.Set("key", buffers[i%2], 0, 0, nil); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	n := 0
+	for _, b := range buffers {
+		marks, err := v.BufferExtmarks(b, s.NamespaceID(), [2]int{0, 0}, [2]int{-1, -1}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += len(marks)
+	}
+	if n != 1 {
+		t.Errorf("buffers have %d marks, want 1", n)
+	}
+	if _, ok, err := s.Get("key"); err != nil || !ok {
+		t.Errorf("Get(key) = %v, %v, want true", ok, err)
+	}
+}
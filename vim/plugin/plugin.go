@@ -13,14 +13,29 @@
 // applications. If the environment variable NEOVIM_GO_LOG_FILE is set, then
 // the default logger is configured to append to the file specified by the
 // environment variable.
+//
+// If the environment variable NEOVIM_GO_EXPVAR_ADDR is set, then the plugin
+// host collects MessagePack RPC metrics and serves the metrics in expvar
+// format at http://$NEOVIM_GO_EXPVAR_ADDR/debug/vars.
+//
+// If the environment variable NEOVIM_GO_RECORD_FILE is set, then the plugin
+// host records the MessagePack RPC session with Neovim to a file named by
+// appending "." and the process id to the value of the environment variable.
+// Each plugin host process records to its own file so that the sessions of
+// multiple hosts do not overwrite each other. Use rpc.Replayer to play back a
+// session.
 package plugin
 
 import (
+	"expvar"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 
+	"github.com/garyburd/neovim-go/msgpack/rpc"
 	"github.com/garyburd/neovim-go/vim"
 )
 
@@ -57,7 +72,32 @@ func Main() {
 		os.Stdout = os.Stderr
 	}
 
-	v, err := vim.New(os.Stdin, stdout, log.Printf)
+	var options []rpc.Option
+	if addr := os.Getenv("NEOVIM_GO_EXPVAR_ADDR"); addr != "" {
+		m := rpc.NewMetrics()
+		expvar.Publish("rpc", expvar.Func(func() interface{} { return m.Snapshot() }))
+		options = append(options, rpc.WithMetrics(m))
+		go func() {
+			log.Print(http.ListenAndServe(addr, nil))
+		}()
+	}
+
+	var r io.Reader = os.Stdin
+	var wc io.WriteCloser = stdout
+	if fname := os.Getenv("NEOVIM_GO_RECORD_FILE"); fname != "" {
+		f, err := os.Create(fmt.Sprintf("%s.%d", fname, os.Getpid()))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		rec := rpc.NewRecorder(struct {
+			io.Reader
+			io.WriteCloser
+		}{r, wc}, f)
+		r, wc = rec, rec
+	}
+
+	v, err := vim.New(r, wc, log.Printf, options...)
 	if err != nil {
 		log.Fatal(err)
 	}
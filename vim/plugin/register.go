@@ -12,6 +12,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/garyburd/neovim-go/msgpack/rpc"
 	"github.com/garyburd/neovim-go/vim"
 )
 
@@ -23,11 +24,13 @@ type pluginSpec struct {
 
 	ServiceMethod string `msgpack:"-"`
 	fn            interface{}
+	dispatch      rpc.HandlerOption
 }
 
 type handler struct {
-	sm string
-	fn interface{}
+	sm      string
+	fn      interface{}
+	options []rpc.HandlerOption
 }
 
 var (
@@ -57,13 +60,13 @@ func RegisterHandlers(v *vim.Vim, paths ...string) error {
 	}
 	for _, path := range paths {
 		for _, s := range pluginSpecs {
-			if err := v.RegisterHandler(path+s.ServiceMethod, s.fn); err != nil {
+			if err := v.RegisterHandler(path+s.ServiceMethod, s.fn, s.dispatch); err != nil {
 				return err
 			}
 		}
 	}
 	for _, h := range handlers {
-		if err := v.RegisterHandler(h.sm, h.fn); err != nil {
+		if err := v.RegisterHandler(h.sm, h.fn, h.options...); err != nil {
 			return err
 		}
 	}
@@ -73,18 +76,22 @@ func RegisterHandlers(v *vim.Vim, paths ...string) error {
 // Handle registers fn as a MessagePack RPC handler for the specified method
 // name. The function signature for fn is one of
 //
-//  func(v *vim.Vim, {args}) ({resultType}, error)
-//  func(v *vim.Vim, {args}) error
-//  func(v *vim.Vim, {args})
+//	func(v *vim.Vim, [ctx context.Context,] {args}) ({resultType}, error)
+//	func(v *vim.Vim, [ctx context.Context,] {args}) error
+//	func(v *vim.Vim, [ctx context.Context,] {args})
 //
 // where {args} is zero or more arguments and {resultType} is the type of of a
-// return value. Call the handler from Neovim using the rpcnotify and
-// rpcrequest functions:
+// return value. The optional context is cancelled when the handler returns or
+// Neovim closes the connection. Call the handler from Neovim using the
+// rpcnotify and rpcrequest functions:
 //
-//  :help rpcrequest()
-//  :help rpcnotify()
-func Handle(method string, fn interface{}) {
-	handlers = append(handlers, &handler{fn: fn, sm: method})
+//	:help rpcrequest()
+//	:help rpcnotify()
+//
+// The options control how calls to the handler are scheduled. See
+// rpc.Serial, rpc.Parallel, rpc.LatestWins and rpc.InQueue.
+func Handle(method string, fn interface{}, options ...rpc.HandlerOption) {
+	handlers = append(handlers, &handler{fn: fn, sm: method, options: options})
 }
 
 // FunctionOptions specifies function options.
@@ -92,6 +99,14 @@ type FunctionOptions struct {
 	// Eval is an expression evaluated in Neovim. The result is passed the
 	// handler function.
 	Eval string
+
+	// Dispatch specifies how calls to the handler are scheduled. By default,
+	// each call runs in a new goroutine.
+	//
+	//  rpc.Serial()      One call at a time in arrival order
+	//  rpc.Parallel(n)   At most n concurrent calls
+	//  rpc.LatestWins()  One call at a time, drop stale waiting calls
+	Dispatch rpc.HandlerOption
 }
 
 // HandleFunction registers fn as a handler for a Neovim function with the
@@ -99,12 +114,13 @@ type FunctionOptions struct {
 // and must start with a capital letter. The function signature for fn is one
 // of
 //
-//  func(v *vim.Vim, args {arrayType} [, eval {evalType}]) ({resultType}, error)
-//  func(v *vim.Vim, args {arrayType} [, eval {evalType}]) error
+//	func(v *vim.Vim, [ctx context.Context,] args {arrayType} [, eval {evalType}]) ({resultType}, error)
+//	func(v *vim.Vim, [ctx context.Context,] args {arrayType} [, eval {evalType}]) error
 //
 // where {arrayType} is a type that can be unmarshaled from a MessagePack
 // array, {evalType} is a type compatible with the Eval option expression and
-// {resultType} is the type of function result.
+// {resultType} is the type of function result. The optional context is
+// cancelled when the function returns or Neovim closes the connection.
 //
 // If options.Eval == "*", then HandleFunction constructs the expression to
 // evaluate in Neovim from the type of fn's last argument. The last argument is
@@ -112,20 +128,22 @@ type FunctionOptions struct {
 // expression to evaluate for each field. Nested structs are supported. The
 // expression for the function
 //
-//  func example(v *vim.Vim, eval *struct{
-//      GOPATH string `eval:"$GOPATH"`
-//      Cwd    string `eval:"getcwd()"`
-//  })
+//	func example(v *vim.Vim, eval *struct{
+//	    GOPATH string `eval:"$GOPATH"`
+//	    Cwd    string `eval:"getcwd()"`
+//	})
 //
 // is
 //
-//  {'GOPATH': $GOPATH, Cwd: getcwd()}
+//	{'GOPATH': $GOPATH, Cwd: getcwd()}
 func HandleFunction(name string, options *FunctionOptions, fn interface{}) {
 	m := make(map[string]string)
+	var dispatch rpc.HandlerOption
 	if options != nil {
 		if options.Eval != "" {
 			m["eval"] = eval(options.Eval, fn)
 		}
+		dispatch = options.Dispatch
 	}
 	pluginSpecs = append(pluginSpecs, &pluginSpec{
 		Type: "function",
@@ -134,6 +152,7 @@ func HandleFunction(name string, options *FunctionOptions, fn interface{}) {
 		Opts: m,
 
 		fn:            fn,
+		dispatch:      dispatch,
 		ServiceMethod: ":function:" + name,
 	})
 }
@@ -203,21 +222,26 @@ type CommandOptions struct {
 	//
 	//  :help :command-complete
 	Complete string
+
+	// Dispatch specifies how calls to the handler are scheduled. See the
+	// FunctionOptions Dispatch field for details.
+	Dispatch rpc.HandlerOption
 }
 
 // HandleCommand registers fn as a handler for a Neovim command with the
 // specified name. The name must be made of alphanumeric characters and '_',
 // and must start with a capital letter.
-///
+// /
 // The arguments to fn function are:
 //
-//  v *vim.Vim
-//  args []string       when options.NArgs != ""
-//  range [2]int        when options.Range == "." or Range == "%"
-//  range int           when options.Range == N or Count != ""
-//  bang bool           when options.Bang == true
-//  register string     when options.Register == true
-//  eval interface{}    when options.Eval != ""
+//	v *vim.Vim
+//	ctx context.Context optional, cancelled when the handler returns
+//	args []string       when options.NArgs != ""
+//	range [2]int        when options.Range == "." or Range == "%"
+//	range int           when options.Range == N or Count != ""
+//	bang bool           when options.Bang == true
+//	register string     when options.Register == true
+//	eval interface{}    when options.Eval != ""
 //
 // The function fn must return an error.
 //
@@ -227,6 +251,7 @@ type CommandOptions struct {
 // generated.
 func HandleCommand(name string, options *CommandOptions, fn interface{}) error {
 	m := make(map[string]string)
+	var dispatch rpc.HandlerOption
 	if options != nil {
 
 		if options.NArgs != "" {
@@ -265,6 +290,8 @@ func HandleCommand(name string, options *CommandOptions, fn interface{}) error {
 		if options.Complete != "" {
 			m["complete"] = options.Complete
 		}
+
+		dispatch = options.Dispatch
 	}
 
 	pluginSpecs = append(pluginSpecs, &pluginSpec{
@@ -275,6 +302,7 @@ func HandleCommand(name string, options *CommandOptions, fn interface{}) error {
 
 		ServiceMethod: ":command:" + name,
 		fn:            fn,
+		dispatch:      dispatch,
 	})
 	return nil
 }
@@ -297,6 +325,11 @@ type AutocmdOptions struct {
 	// Eval is evaluated in Neovim and the result is passed the the handler
 	// function.
 	Eval string
+
+	// Dispatch specifies how calls to the handler are scheduled. Use
+	// rpc.LatestWins() for frequent events such as TextChangedI. See the
+	// FunctionOptions Dispatch field for details.
+	Dispatch rpc.HandlerOption
 }
 
 // HandleAutocmd registers fn as a handler for the specified autocmnd event.
@@ -308,6 +341,7 @@ type AutocmdOptions struct {
 func HandleAutocmd(event string, options *AutocmdOptions, fn interface{}) {
 	pattern := ""
 	m := make(map[string]string)
+	var dispatch rpc.HandlerOption
 	if options != nil {
 
 		if options.Group != "" {
@@ -327,6 +361,7 @@ func HandleAutocmd(event string, options *AutocmdOptions, fn interface{}) {
 			m["eval"] = eval(options.Eval, fn)
 		}
 
+		dispatch = options.Dispatch
 	}
 	pluginSpecs = append(pluginSpecs, &pluginSpec{
 		Type: "autocmd",
@@ -335,6 +370,7 @@ func HandleAutocmd(event string, options *AutocmdOptions, fn interface{}) {
 		Opts: m,
 
 		fn:            fn,
+		dispatch:      dispatch,
 		ServiceMethod: fmt.Sprintf(":autocmd:%s:%s", event, pattern),
 	})
 
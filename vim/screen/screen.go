@@ -0,0 +1,428 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package screen implements an in-memory model of the Neovim screen for
+// testing.
+//
+// A Screen applies the redraw events sent to a UI attached with
+// vim.AttachUI to a grid of cells. Tests use the Text and Markup methods to
+// compare the screen with golden files and the WaitFor method to wait for
+// Neovim to redraw the screen:
+//
+//	s, err := screen.Attach(v, 40, 10, nil)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	err = s.WaitFor(func(s *screen.Screen) bool {
+//	    return strings.Contains(s.Text(), "Hello")
+//	}, 5*time.Second)
+//
+// The screen models the default grid only. Do not attach with the
+// ExtMultigrid option.
+package screen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+// defaultGrid is the grid for the whole screen.
+const defaultGrid = 1
+
+// Cell is a cell in the screen grid.
+type Cell struct {
+	// Text is the text of the cell. Text is "" for the right half of a
+	// double width character.
+	Text string
+
+	// HighlightID is the highlight for the cell. Zero is the default
+	// highlight.
+	HighlightID int
+}
+
+// Screen is a model of the Neovim screen.
+type Screen struct {
+	rgb bool
+
+	mu         sync.Mutex
+	grid       [][]Cell
+	highlights map[int]*vim.HighlightAttrDefineEvent
+	cursorRow  int
+	cursorCol  int
+	mode       string
+	title      string
+	frames     int
+
+	// changed is closed and replaced when a frame is applied.
+	changed chan struct{}
+}
+
+// New returns a new empty screen. The screen uses the RGB attributes of the
+// highlights if rgb is true and the terminal attributes otherwise. Use the
+// Apply method as the OnFrame function of a UI.
+func New(rgb bool) *Screen {
+	return &Screen{
+		rgb:        rgb,
+		highlights: make(map[int]*vim.HighlightAttrDefineEvent),
+		changed:    make(chan struct{}),
+	}
+}
+
+// Attach attaches v as a UI with the given grid size and returns a screen
+// updated with the redraw events for the UI. The OnFrame field in options is
+// ignored.
+func Attach(v *vim.Vim, width, height int, options *vim.UIOptions) (*Screen, error) {
+	var o vim.UIOptions
+	if options != nil {
+		o = *options
+	}
+	s := New(o.RGB)
+	o.OnFrame = s.Apply
+	if err := v.AttachUI(width, height, &o); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Apply applies a frame of redraw events to the screen. Events for other
+// grids and events that do not change the grid are ignored.
+func (s *Screen) Apply(frame []vim.RedrawEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range frame {
+		switch e := e.(type) {
+		case *vim.GridResizeEvent:
+			if e.Grid == defaultGrid {
+				s.resize(e.Width, e.Height)
+			}
+		case *vim.GridClearEvent:
+			if e.Grid == defaultGrid {
+				s.clear()
+			}
+		case *vim.GridCursorGotoEvent:
+			if e.Grid == defaultGrid {
+				s.cursorRow, s.cursorCol = e.Row, e.Col
+			}
+		case *vim.GridLineEvent:
+			if e.Grid == defaultGrid {
+				s.line(e)
+			}
+		case *vim.GridScrollEvent:
+			if e.Grid == defaultGrid {
+				s.scroll(e)
+			}
+		case *vim.HighlightAttrDefineEvent:
+			s.highlights[e.ID] = e
+		case *vim.ModeChangeEvent:
+			s.mode = e.Mode
+		case *vim.SetTitleEvent:
+			s.title = e.Title
+		}
+	}
+	s.frames++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Screen) resize(width, height int) {
+	grid := make([][]Cell, height)
+	for row := range grid {
+		grid[row] = make([]Cell, width)
+		n := 0
+		if row < len(s.grid) {
+			n = copy(grid[row], s.grid[row])
+		}
+		for col := n; col < width; col++ {
+			grid[row][col] = Cell{Text: " "}
+		}
+	}
+	s.grid = grid
+}
+
+func (s *Screen) clear() {
+	for _, cells := range s.grid {
+		for col := range cells {
+			cells[col] = Cell{Text: " "}
+		}
+	}
+}
+
+func (s *Screen) line(e *vim.GridLineEvent) {
+	if e.Row < 0 || e.Row >= len(s.grid) {
+		return
+	}
+	cells := s.grid[e.Row]
+	col := e.ColStart
+	for _, c := range e.Cells {
+		for i := 0; i < c.Repeat; i++ {
+			if col >= 0 && col < len(cells) {
+				cells[col] = Cell{Text: c.Text, HighlightID: c.HighlightID}
+			}
+			col++
+		}
+	}
+}
+
+// scroll moves the region of the grid by e.Rows. The rows uncovered by the
+// move are redrawn by later events.
+//
+//	:help ui-event-grid_scroll
+func (s *Screen) scroll(e *vim.GridScrollEvent) {
+	move := func(dst, src int) {
+		if dst < 0 || dst >= len(s.grid) || src < 0 || src >= len(s.grid) {
+			return
+		}
+		right := e.Right
+		if right > len(s.grid[dst]) {
+			right = len(s.grid[dst])
+		}
+		if e.Left < right {
+			copy(s.grid[dst][e.Left:right], s.grid[src][e.Left:right])
+		}
+	}
+	if e.Rows > 0 {
+		for row := e.Top; row < e.Bot-e.Rows; row++ {
+			move(row, row+e.Rows)
+		}
+	} else {
+		for row := e.Bot - 1; row >= e.Top-e.Rows; row-- {
+			move(row, row+e.Rows)
+		}
+	}
+}
+
+// Size returns the size of the screen.
+func (s *Screen) Size() (width, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.grid) == 0 {
+		return 0, 0
+	}
+	return len(s.grid[0]), len(s.grid)
+}
+
+// Cell returns the cell at the zero-based row and column.
+func (s *Screen) Cell(row, col int) Cell {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if row < 0 || row >= len(s.grid) || col < 0 || col >= len(s.grid[row]) {
+		return Cell{}
+	}
+	return s.grid[row][col]
+}
+
+// Cursor returns the zero-based position of the cursor.
+func (s *Screen) Cursor() (row, col int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursorRow, s.cursorCol
+}
+
+// Mode returns the name of the current mode, for example "normal" or
+// "insert".
+func (s *Screen) Mode() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mode
+}
+
+// Title returns the window title.
+func (s *Screen) Title() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.title
+}
+
+// Frames returns the number of frames applied to the screen.
+func (s *Screen) Frames() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.frames
+}
+
+// Highlight returns the attributes of the highlight with the given id.
+func (s *Screen) Highlight(id int) vim.HighlightAttrs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attrs(id)
+}
+
+func (s *Screen) attrs(id int) vim.HighlightAttrs {
+	e := s.highlights[id]
+	switch {
+	case e == nil:
+		return vim.HighlightAttrs{Foreground: -1, Background: -1, Special: -1}
+	case s.rgb:
+		return e.RGBAttrs
+	default:
+		return e.CtermAttrs
+	}
+}
+
+// Text returns the text on the screen. The lines of the screen are
+// terminated by "\n". Trailing spaces are removed from the lines.
+func (s *Screen) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var buf bytes.Buffer
+	for _, cells := range s.grid {
+		var line bytes.Buffer
+		for _, c := range cells {
+			line.WriteString(c.Text)
+		}
+		buf.Write(bytes.TrimRight(line.Bytes(), " "))
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// Markup returns the text on the screen with markup for the highlights.
+//
+// A run of text with a highlight is written as {n:text} where n is a number
+// for the highlight attributes. The numbers are assigned in order of first
+// appearance on the screen, so the markup does not depend on the highlight
+// ids chosen by Neovim. The text "{" and "}" on the screen is written as
+// "{{" and "}}". Text with the default attributes is written without markup.
+//
+// The lines of the screen are followed by an empty line and a legend with
+// the attributes for each number:
+//
+//	{1:Hello} world
+//	~
+//
+//	{1} bold foreground=#ff0000
+func (s *Screen) Markup() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numbers := make(map[vim.HighlightAttrs]int)
+	var legend []string
+	number := func(id int) int {
+		a := s.attrs(id)
+		if a == (vim.HighlightAttrs{Foreground: -1, Background: -1, Special: -1}) {
+			return 0
+		}
+		n, ok := numbers[a]
+		if !ok {
+			n = len(numbers) + 1
+			numbers[a] = n
+			legend = append(legend, fmt.Sprintf("{%d} %s", n, formatAttrs(a, s.rgb)))
+		}
+		return n
+	}
+
+	escaper := strings.NewReplacer("{", "{{", "}", "}}")
+	var buf bytes.Buffer
+	for _, cells := range s.grid {
+		var line bytes.Buffer
+		current := 0
+		for _, c := range cells {
+			n := number(c.HighlightID)
+			if n != current {
+				if current != 0 {
+					line.WriteByte('}')
+				}
+				if n != 0 {
+					fmt.Fprintf(&line, "{%d:", n)
+				}
+				current = n
+			}
+			line.WriteString(escaper.Replace(c.Text))
+		}
+		if current != 0 {
+			line.WriteByte('}')
+		}
+		buf.Write(bytes.TrimRight(line.Bytes(), " "))
+		buf.WriteByte('\n')
+	}
+	if len(legend) > 0 {
+		buf.WriteByte('\n')
+		for _, l := range legend {
+			buf.WriteString(l)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
+
+// formatAttrs returns a readable description of the highlight attributes.
+func formatAttrs(a vim.HighlightAttrs, rgb bool) string {
+	var parts []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"reverse", a.Reverse},
+		{"italic", a.Italic},
+		{"bold", a.Bold},
+		{"strikethrough", a.Strikethrough},
+		{"underline", a.Underline},
+		{"undercurl", a.Undercurl},
+		{"underdouble", a.Underdouble},
+		{"underdotted", a.Underdotted},
+		{"underdashed", a.Underdashed},
+		{"standout", a.Standout},
+		{"nocombine", a.Nocombine},
+	} {
+		if f.set {
+			parts = append(parts, f.name)
+		}
+	}
+	for _, c := range []struct {
+		name  string
+		color int
+	}{
+		{"foreground", a.Foreground},
+		{"background", a.Background},
+		{"special", a.Special},
+	} {
+		switch {
+		case c.color < 0:
+		case rgb:
+			parts = append(parts, fmt.Sprintf("%s=#%06x", c.name, c.color))
+		default:
+			parts = append(parts, fmt.Sprintf("%s=%d", c.name, c.color))
+		}
+	}
+	if a.Blend != 0 {
+		parts = append(parts, fmt.Sprintf("blend=%d", a.Blend))
+	}
+	return strings.Join(parts, " ")
+}
+
+// WaitFor waits for predicate to return true. WaitFor calls predicate
+// immediately and after each frame applied to the screen. If timeout expires
+// before predicate returns true, then WaitFor returns a *TimeoutError.
+func (s *Screen) WaitFor(predicate func(s *Screen) bool, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+		if predicate(s) {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return &TimeoutError{Text: s.Text()}
+		}
+	}
+}
+
+// TimeoutError is returned from WaitFor when the timeout expires. The error
+// includes the screen text to help debug failed tests.
+type TimeoutError struct {
+	Text string
+}
+
+func (e *TimeoutError) Error() string {
+	return "screen: timeout waiting for screen:\n" + e.Text
+}
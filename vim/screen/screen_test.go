@@ -0,0 +1,111 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package screen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garyburd/neovim-go/vim"
+	"github.com/garyburd/neovim-go/vim/vimfake"
+)
+
+func gridLine(row, col int, cells ...vim.GridCell) *vim.GridLineEvent {
+	return &vim.GridLineEvent{Grid: 1, Row: row, ColStart: col, Cells: cells}
+}
+
+func TestApply(t *testing.T) {
+	s := New(true)
+	s.Apply([]vim.RedrawEvent{
+		&vim.GridResizeEvent{Grid: 1, Width: 10, Height: 4},
+		&vim.GridClearEvent{Grid: 1},
+		&vim.HighlightAttrDefineEvent{ID: 7, RGBAttrs: vim.HighlightAttrs{Foreground: 0xff0000, Background: -1, Special: -1, Bold: true}},
+		&vim.HighlightAttrDefineEvent{ID: 8, RGBAttrs: vim.HighlightAttrs{Foreground: -1, Background: -1, Special: -1}},
+		gridLine(0, 0, vim.GridCell{Text: "H", HighlightID: 7, Repeat: 1}, vim.GridCell{Text: "i", HighlightID: 7, Repeat: 1}, vim.GridCell{Text: "{", HighlightID: 8, Repeat: 1}),
+		gridLine(1, 2, vim.GridCell{Text: "-", HighlightID: 0, Repeat: 3}),
+		gridLine(2, 0, vim.GridCell{Text: "a", Repeat: 1}, vim.GridCell{Text: "b", Repeat: 1}),
+		gridLine(3, 0, vim.GridCell{Text: "~", Repeat: 1}),
+		// Scrolling and ignored events for other grids.
+		&vim.GridScrollEvent{Grid: 1, Top: 1, Bot: 3, Left: 0, Right: 10, Rows: 1},
+		gridLine(2, 0, vim.GridCell{Text: " ", Repeat: 10}),
+		gridLine(2, 0, vim.GridCell{Text: "x", Repeat: 1}),
+		&vim.GridClearEvent{Grid: 2},
+		&vim.GridCursorGotoEvent{Grid: 1, Row: 1, Col: 1},
+		&vim.ModeChangeEvent{Mode: "insert", ModeIdx: 1},
+	})
+
+	if got, want := s.Text(), "Hi{\nab\nx\n~\n"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got, want := s.Markup(), "{1:Hi}{{\nab\nx\n~\n\n{1} bold foreground=#ff0000\n"; got != want {
+		t.Errorf("Markup() = %q, want %q", got, want)
+	}
+	if w, h := s.Size(); w != 10 || h != 4 {
+		t.Errorf("Size() = %d, %d, want 10, 4", w, h)
+	}
+	if row, col := s.Cursor(); row != 1 || col != 1 {
+		t.Errorf("Cursor() = %d, %d, want 1, 1", row, col)
+	}
+	if mode := s.Mode(); mode != "insert" {
+		t.Errorf("Mode() = %q, want insert", mode)
+	}
+	if c := s.Cell(0, 1); c != (Cell{Text: "i", HighlightID: 7}) {
+		t.Errorf("Cell(0, 1) = %+v, want i with highlight 7", c)
+	}
+
+	// Shrink the grid and scroll down.
+	s.Apply([]vim.RedrawEvent{
+		&vim.GridResizeEvent{Grid: 1, Width: 2, Height: 3},
+		&vim.GridScrollEvent{Grid: 1, Top: 0, Bot: 3, Left: 0, Right: 2, Rows: -1},
+	})
+	if got, want := s.Text(), "Hi\nHi\nab\n"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if n := s.Frames(); n != 2 {
+		t.Errorf("Frames() = %d, want 2", n)
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	f, err := vimfake.New(t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s, err := Attach(f.Vim(), 5, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hasText := func(s *Screen) bool { return s.Text() == "hello\n\n" }
+
+	if err := s.WaitFor(hasText, 10*time.Millisecond); err == nil {
+		t.Fatal("WaitFor on empty screen did not return error")
+	} else if _, ok := err.(*TimeoutError); !ok {
+		t.Fatalf("WaitFor returned %v, want *TimeoutError", err)
+	}
+
+	err = f.Redraw(
+		[]interface{}{"grid_resize", []interface{}{1, 5, 2}},
+		[]interface{}{"grid_clear", []interface{}{1}},
+		[]interface{}{"flush", []interface{}{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Redraw(
+		[]interface{}{"grid_line", []interface{}{1, 0, 0, []interface{}{
+			[]interface{}{"h", 0}, []interface{}{"e"}, []interface{}{"l", 0, 2}, []interface{}{"o"},
+		}}},
+		[]interface{}{"flush", []interface{}{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WaitFor(hasText, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,433 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/garyburd/neovim-go/msgpack"
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+)
+
+// RedrawEvent is a UI event sent by Neovim in a redraw notification. The
+// concrete type of a RedrawEvent is a pointer to one of the *Event types for
+// the UI events, such as *GridLineEvent and *ModeChangeEvent, or
+// *UnknownRedrawEvent.
+//
+//	:help ui-events
+type RedrawEvent interface {
+	redrawEvent()
+}
+
+// UnknownRedrawEvent is a UI event that is not known to this package or a
+// known event with malformed arguments. Args is nil for a known event.
+type UnknownRedrawEvent struct {
+	Name string
+	Args []interface{}
+}
+
+func (*UnknownRedrawEvent) redrawEvent() {}
+
+// UIOptions specifies options for attaching a UI.
+//
+//	:help ui-option
+type UIOptions struct {
+	// RGB specifies that colors are sent as RGB values. Terminal colors are
+	// sent if RGB is false.
+	RGB bool
+
+	// The Ext* fields specify the UI elements that the application draws.
+	// Neovim draws the elements on the grid when the field is false.
+	ExtCmdline   bool
+	ExtPopupmenu bool
+	ExtTabline   bool
+	ExtWildmenu  bool
+	ExtMessages  bool
+	ExtMultigrid bool
+	ExtHLState   bool
+
+	// ExtTermColors specifies that terminal colors are sent as highlights.
+	ExtTermColors bool
+
+	// OnFrame is called with the redraw events for each frame. A frame is
+	// the events between flush events. The flush event is not included in
+	// the frame. OnFrame is called from a single goroutine and should return
+	// promptly.
+	OnFrame func(frame []RedrawEvent)
+}
+
+// AttachUI registers the client as a remote UI with the given grid size.
+// The redraw events sent by Neovim are decoded to RedrawEvent values and
+// delivered to options.OnFrame. The client always uses the line based grid
+// protocol.
+//
+// The Ext* options are sent to Neovim only when set, so that versions of
+// Neovim without an option accept the attach. AttachUI returns an error if
+// the client is attached as a UI. Call DetachUI before attaching again.
+//
+//	:help nvim_ui_attach()
+//	:help ui-linegrid
+func (v *Vim) AttachUI(width, height int, options *UIOptions) error {
+	if options == nil {
+		options = &UIOptions{}
+	}
+
+	v.uiMu.Lock()
+	if v.uiAttached {
+		v.uiMu.Unlock()
+		return errors.New("nvim: UI already attached")
+	}
+	v.uiAttached = true
+	v.onFrame = options.OnFrame
+	register := !v.redrawHandler
+	v.redrawHandler = true
+	v.uiMu.Unlock()
+
+	if register {
+		if err := v.registerRedrawHandler(); err != nil {
+			v.uiMu.Lock()
+			v.uiAttached = false
+			v.onFrame = nil
+			v.redrawHandler = false
+			v.uiMu.Unlock()
+			return err
+		}
+	}
+
+	opts := map[string]interface{}{
+		"rgb":          options.RGB,
+		"ext_linegrid": true,
+	}
+	for name, set := range map[string]bool{
+		"ext_cmdline":    options.ExtCmdline,
+		"ext_popupmenu":  options.ExtPopupmenu,
+		"ext_tabline":    options.ExtTabline,
+		"ext_wildmenu":   options.ExtWildmenu,
+		"ext_messages":   options.ExtMessages,
+		"ext_multigrid":  options.ExtMultigrid,
+		"ext_hlstate":    options.ExtHLState,
+		"ext_termcolors": options.ExtTermColors,
+	} {
+		if set {
+			opts[name] = true
+		}
+	}
+	if err := v.call("nvim_ui_attach", nil, width, height, opts); err != nil {
+		v.uiMu.Lock()
+		v.uiAttached = false
+		v.onFrame = nil
+		v.uiMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// DetachUI unregisters the client as a remote UI.
+//
+//	:help nvim_ui_detach()
+func (v *Vim) DetachUI() error {
+	if err := v.call("nvim_ui_detach", nil); err != nil {
+		return err
+	}
+	v.uiMu.Lock()
+	v.uiAttached = false
+	v.onFrame = nil
+	v.uiMu.Unlock()
+	return nil
+}
+
+// registerRedrawHandler registers the handler for redraw notifications. The
+// handler delivers the events to the OnFrame function for the attached UI.
+func (v *Vim) registerRedrawHandler() error {
+	var frame []RedrawEvent
+	return v.ep.RegisterHandler("redraw", func(v *Vim, batches ...redrawBatch) {
+		for _, batch := range batches {
+			for _, e := range batch {
+				if _, ok := e.(*FlushEvent); ok {
+					v.uiMu.Lock()
+					onFrame := v.onFrame
+					v.uiMu.Unlock()
+					if onFrame != nil {
+						onFrame(frame)
+					}
+					frame = nil
+					continue
+				}
+				frame = append(frame, e)
+			}
+		}
+	}, rpc.Serial())
+}
+
+// redrawBatch decodes the events in an item of a redraw notification. The
+// item is the event name followed by the arguments for one or more events.
+// Items that are not arrays are skipped. Events with an unknown name or with
+// malformed arguments are decoded to *UnknownRedrawEvent.
+type redrawBatch []RedrawEvent
+
+func (b *redrawBatch) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.Type() != msgpack.ArrayLen {
+		return dec.Skip()
+	}
+	n := dec.Len()
+	if n == 0 {
+		return nil
+	}
+	var name string
+	if err := dec.Decode(&name); err != nil && !isConvertError(err) {
+		return err
+	}
+	for i := 1; i < n; i++ {
+		newEvent := redrawEventTypes[name]
+		if newEvent == nil {
+			e := &UnknownRedrawEvent{Name: name}
+			if err := dec.Decode(&e.Args); err != nil && !isConvertError(err) {
+				return err
+			}
+			*b = append(*b, e)
+			continue
+		}
+		e := newEvent()
+		if err := dec.Decode(e); err != nil {
+			if !isConvertError(err) {
+				return err
+			}
+			e = &UnknownRedrawEvent{Name: name}
+		}
+		*b = append(*b, e)
+	}
+	return nil
+}
+
+func isConvertError(err error) bool {
+	_, ok := err.(*msgpack.DecodeConvertError)
+	return ok
+}
+
+// invalidValue skips the current value and returns a
+// *msgpack.DecodeConvertError for decoding the value to v. Returning the
+// convert error from an UnmarshalMsgPack method tells the caller that the
+// value was skipped and that decoding can continue.
+func invalidValue(dec *msgpack.Decoder, v interface{}) error {
+	err := &msgpack.DecodeConvertError{SrcType: dec.Type(), DestType: reflect.TypeOf(v)}
+	if skipErr := dec.Skip(); skipErr != nil {
+		return skipErr
+	}
+	return err
+}
+
+// skipValues skips the next n values in the stream and returns err. If err
+// is not a convert error, then skipValues returns err without skipping the
+// values because the stream is not usable.
+func skipValues(dec *msgpack.Decoder, n int, err error) error {
+	if err != nil && !isConvertError(err) {
+		return err
+	}
+	for ; n > 0; n-- {
+		if skipErr := skipValue(dec); skipErr != nil {
+			return skipErr
+		}
+	}
+	return err
+}
+
+// GridLineEvent is the grid_line UI event. The event updates a line in a
+// grid starting at ColStart.
+//
+//	:help ui-event-grid_line
+type GridLineEvent struct {
+	Grid     int
+	Row      int
+	ColStart int
+	Cells    []GridCell
+}
+
+// GridCell is a run of grid cells in a GridLineEvent.
+type GridCell struct {
+	// Text is the text of the cell. Text is "" for the right half of a
+	// double width character.
+	Text string
+
+	// HighlightID is the id of the highlight defined with hl_attr_define.
+	// The id is copied from the previous cell when Neovim omits the id.
+	HighlightID int
+
+	// Repeat is the number of times the cell is repeated.
+	Repeat int
+}
+
+func (e *GridLineEvent) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	if dec.Type() != msgpack.ArrayLen || dec.Len() < 4 {
+		return invalidValue(dec, e)
+	}
+	n := dec.Len()
+	for i, p := range []*int{&e.Grid, &e.Row, &e.ColStart} {
+		if err := dec.Decode(p); err != nil {
+			return skipValues(dec, n-i-1, err)
+		}
+	}
+
+	if err := dec.Unpack(); err != nil {
+		return err
+	}
+	if dec.Type() != msgpack.ArrayLen {
+		return skipValues(dec, n-4, invalidValue(dec, e.Cells))
+	}
+	hlID := 0
+	e.Cells = make([]GridCell, dec.Len())
+	for i := range e.Cells {
+		if err := e.Cells[i].decode(dec, &hlID); err != nil {
+			// Skip the remaining cells and parameters.
+			return skipValues(dec, len(e.Cells)-i-1+n-4, err)
+		}
+	}
+
+	// Skip parameters added in later versions of Neovim.
+	return skipValues(dec, n-4, nil)
+}
+
+// decode decodes a cell in a grid_line event. The argument hlID is the
+// highlight id of the previous cell.
+func (c *GridCell) decode(dec *msgpack.Decoder, hlID *int) error {
+	if err := dec.Unpack(); err != nil {
+		return err
+	}
+	if dec.Type() != msgpack.ArrayLen || dec.Len() == 0 {
+		return invalidValue(dec, c)
+	}
+	m := dec.Len()
+	if err := dec.Decode(&c.Text); err != nil {
+		return skipValues(dec, m-1, err)
+	}
+	if m > 1 {
+		if err := dec.Decode(hlID); err != nil {
+			return skipValues(dec, m-2, err)
+		}
+	}
+	c.HighlightID = *hlID
+	c.Repeat = 1
+	if m > 2 {
+		if err := dec.Decode(&c.Repeat); err != nil {
+			return skipValues(dec, m-3, err)
+		}
+	}
+	return skipValues(dec, m-3, nil)
+}
+
+// skipValue skips the next value in the stream.
+func skipValue(dec *msgpack.Decoder) error {
+	if err := dec.Unpack(); err != nil {
+		return err
+	}
+	return dec.Skip()
+}
+
+// HighlightAttrs are the attributes of a highlight. The colors are -1 when
+// the highlight uses the default color.
+//
+//	:help ui-event-hl_attr_define
+type HighlightAttrs struct {
+	Foreground int
+	Background int
+	Special    int
+
+	Reverse       bool
+	Italic        bool
+	Bold          bool
+	Strikethrough bool
+	Underline     bool
+	Undercurl     bool
+	Underdouble   bool
+	Underdotted   bool
+	Underdashed   bool
+	Standout      bool
+	Nocombine     bool
+
+	// Blend is the blend level for floating windows, 0 to 100.
+	Blend int
+}
+
+func (a *HighlightAttrs) UnmarshalMsgPack(dec *msgpack.Decoder) error {
+	*a = HighlightAttrs{Foreground: -1, Background: -1, Special: -1}
+	if dec.Type() != msgpack.MapLen {
+		return invalidValue(dec, a)
+	}
+	n := dec.Len()
+	var convertErr error
+	for i := 0; i < n; i++ {
+		var key string
+		if err := dec.Decode(&key); err != nil {
+			key = ""
+			if !isConvertError(err) {
+				return err
+			}
+			if convertErr == nil {
+				convertErr = err
+			}
+		}
+		var err error
+		switch key {
+		case "foreground":
+			err = dec.Decode(&a.Foreground)
+		case "background":
+			err = dec.Decode(&a.Background)
+		case "special":
+			err = dec.Decode(&a.Special)
+		case "reverse":
+			err = dec.Decode(&a.Reverse)
+		case "italic":
+			err = dec.Decode(&a.Italic)
+		case "bold":
+			err = dec.Decode(&a.Bold)
+		case "strikethrough":
+			err = dec.Decode(&a.Strikethrough)
+		case "underline":
+			err = dec.Decode(&a.Underline)
+		case "undercurl":
+			err = dec.Decode(&a.Undercurl)
+		case "underdouble":
+			err = dec.Decode(&a.Underdouble)
+		case "underdotted":
+			err = dec.Decode(&a.Underdotted)
+		case "underdashed":
+			err = dec.Decode(&a.Underdashed)
+		case "standout":
+			err = dec.Decode(&a.Standout)
+		case "nocombine":
+			err = dec.Decode(&a.Nocombine)
+		case "blend":
+			err = dec.Decode(&a.Blend)
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			if !isConvertError(err) {
+				return err
+			}
+			if convertErr == nil {
+				convertErr = err
+			}
+		}
+	}
+	return convertErr
+}
+
+// TextChunk is a chunk of text with a highlight in the content of cmdline
+// and message events.
+type TextChunk struct {
+	HighlightID int `msgpack:",array"`
+	Text        string
+}
+
+// PopupmenuItem is an item in a PopupmenuShowEvent.
+//
+//	:help complete-items
+type PopupmenuItem struct {
+	Word string `msgpack:",array"`
+	Kind string
+	Menu string
+	Info string
+}
@@ -0,0 +1,142 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vim_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func TestAttachUI(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	frames := make(chan []vim.RedrawEvent, 4)
+	err := v.AttachUI(80, 24, &vim.UIOptions{
+		RGB:          true,
+		ExtPopupmenu: true,
+		OnFrame:      func(frame []vim.RedrawEvent) { frames <- frame },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := f.UIOptions()
+	want := map[string]interface{}{"rgb": true, "ext_linegrid": true, "ext_popupmenu": true}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("options = %v, want %v", options, want)
+	}
+
+	err = f.Redraw(
+		[]interface{}{"hl_attr_define",
+			[]interface{}{1, map[string]interface{}{"foreground": 0xff0000, "bold": true}, map[string]interface{}{}, []interface{}{}}},
+		[]interface{}{"grid_line",
+			[]interface{}{1, 2, 3, []interface{}{
+				[]interface{}{"a", 1},
+				[]interface{}{"b"},
+				[]interface{}{" ", 0, 3},
+			}},
+			[]interface{}{1, 3, 0, []interface{}{[]interface{}{"c", 1}}, false},
+			// Malformed events.
+			[]interface{}{1, "x", 0, []interface{}{[]interface{}{"d"}}},
+			[]interface{}{1, 4, 0, []interface{}{[]interface{}{}, []interface{}{"e", 1, "x"}}, false}},
+		[]interface{}{"hl_attr_define",
+			[]interface{}{2, []interface{}{}, map[string]interface{}{}, []interface{}{}}},
+		[]interface{}{"grid_cursor_goto", []interface{}{1, 2, 4}},
+		[]interface{}{"unknown_event", []interface{}{"x", 1}},
+		[]interface{}{"flush", []interface{}{}},
+		[]interface{}{"mode_change", []interface{}{"insert", 1}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Redraw([]interface{}{"flush", []interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	receive := func() []vim.RedrawEvent {
+		t.Helper()
+		select {
+		case frame := <-frames:
+			return frame
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for frame")
+			return nil
+		}
+	}
+
+	wantFrame := []vim.RedrawEvent{
+		&vim.HighlightAttrDefineEvent{
+			ID:         1,
+			RGBAttrs:   vim.HighlightAttrs{Foreground: 0xff0000, Background: -1, Special: -1, Bold: true},
+			CtermAttrs: vim.HighlightAttrs{Foreground: -1, Background: -1, Special: -1},
+		},
+		&vim.GridLineEvent{Grid: 1, Row: 2, ColStart: 3, Cells: []vim.GridCell{
+			{Text: "a", HighlightID: 1, Repeat: 1},
+			{Text: "b", HighlightID: 1, Repeat: 1},
+			{Text: " ", HighlightID: 0, Repeat: 3},
+		}},
+		&vim.GridLineEvent{Grid: 1, Row: 3, ColStart: 0, Cells: []vim.GridCell{
+			{Text: "c", HighlightID: 1, Repeat: 1},
+		}},
+		&vim.UnknownRedrawEvent{Name: "grid_line"},
+		&vim.UnknownRedrawEvent{Name: "grid_line"},
+		&vim.UnknownRedrawEvent{Name: "hl_attr_define"},
+		&vim.GridCursorGotoEvent{Grid: 1, Row: 2, Col: 4},
+		&vim.UnknownRedrawEvent{Name: "unknown_event", Args: []interface{}{"x", int64(1)}},
+	}
+	if frame := receive(); !reflect.DeepEqual(frame, wantFrame) {
+		t.Errorf("frame\n got %s\nwant %s", formatEvents(frame), formatEvents(wantFrame))
+	}
+
+	wantFrame = []vim.RedrawEvent{&vim.ModeChangeEvent{Mode: "insert", ModeIdx: 1}}
+	if frame := receive(); !reflect.DeepEqual(frame, wantFrame) {
+		t.Errorf("frame\n got %s\nwant %s", formatEvents(frame), formatEvents(wantFrame))
+	}
+
+	// A second AttachUI fails and does not replace OnFrame.
+	if err := v.AttachUI(80, 24, nil); err == nil {
+		t.Error("second AttachUI did not return error")
+	}
+	if err := f.Redraw([]interface{}{"flush", []interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+	if frame := receive(); len(frame) != 0 {
+		t.Errorf("frame = %s, want empty frame", formatEvents(frame))
+	}
+
+	// Attach again after detaching.
+	if err := v.DetachUI(); err != nil {
+		t.Fatal(err)
+	}
+	if f.UIOptions() != nil {
+		t.Error("UI attached after DetachUI")
+	}
+	if err := v.AttachUI(40, 10, &vim.UIOptions{
+		OnFrame: func(frame []vim.RedrawEvent) { frames <- frame },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Redraw([]interface{}{"mode_change", []interface{}{"normal", 0}}, []interface{}{"flush", []interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+	wantFrame = []vim.RedrawEvent{&vim.ModeChangeEvent{Mode: "normal", ModeIdx: 0}}
+	if frame := receive(); !reflect.DeepEqual(frame, wantFrame) {
+		t.Errorf("frame\n got %s\nwant %s", formatEvents(frame), formatEvents(wantFrame))
+	}
+}
+
+func formatEvents(events []vim.RedrawEvent) string {
+	var s []string
+	for _, e := range events {
+		s = append(s, fmt.Sprintf("%#v", e))
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
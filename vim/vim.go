@@ -4,11 +4,17 @@
 
 // Package vim implements a Neovim client.
 //
+// Each API method has a variant with the suffix Context that takes a
+// context.Context. The variant abandons the call and returns ctx.Err() when
+// the context is done. Use the variants to bound the time spent waiting for
+// an unresponsive Neovim.
+//
 // See the ./plugin package for additional functionality required for writing
 // Neovim plugins.
 package vim
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -17,17 +23,46 @@ import (
 	"sync"
 	"time"
 
+	"github.com/garyburd/neovim-go/msgpack"
 	"github.com/garyburd/neovim-go/msgpack/rpc"
 )
 
-//go:generate go run genapi.go -out api.go
+//go:generate go run genapi.go apioverrides.go -out api.go
 
 // Vim represents a remote instance of Neovim. It is safe to call *Vim methods
 // concurrently.
 type Vim struct {
-	ep        *rpc.Endpoint
-	mu        sync.Mutex
-	channelID int
+	ep          *rpc.Endpoint
+	mu          sync.Mutex
+	apiMetadata *APIMetadata
+
+	eventMu      sync.Mutex
+	bufferEvents map[Buffer]func(BufferEvent)
+
+	subMu         sync.Mutex
+	subscriptions map[string]*eventSubscription
+	eventHandlers map[string]bool
+
+	// luaModules is the set of Lua modules loaded in the session.
+	luaMu      sync.Mutex
+	luaModules map[*LuaModule]bool
+
+	// callbacks is the set of Go functions bound to keymaps, user commands
+	// and autocmds. wipeouts maps buffers with callbacks to the ids of the
+	// autocmds that release the callbacks when the buffers are wiped out.
+	// The id is zero while the autocmd is created.
+	callbackMu     sync.Mutex
+	nextCallback   int
+	callbacks      map[*Callback]bool
+	wipeouts       map[Buffer]int
+	wipeoutHandler bool
+
+	// onFrame is the OnFrame function for the attached UI. The redraw
+	// handler is registered on the first call to AttachUI.
+	uiMu          sync.Mutex
+	uiAttached    bool
+	onFrame       func(frame []RedrawEvent)
+	redrawHandler bool
 
 	// close is a hook for closing embedded Neovim process.
 	close func() error
@@ -40,7 +75,21 @@ func (v *Vim) Serve() error {
 
 // Close closes the client.
 func (v *Vim) Close() error {
-	err := v.ep.Close()
+	return v.closeHook(v.ep.Close())
+}
+
+// Shutdown gracefully shuts down the client. Shutdown stops accepting calls
+// from Neovim, waits for running handlers to complete and then closes the
+// client. For a clean exit from a plugin, call Shutdown in a new goroutine
+// from a VimLeavePre autocmd handler.
+//
+// If ctx is done before the handlers complete, Shutdown closes the client and
+// returns ctx.Err(). See rpc.Endpoint.Shutdown for more information.
+func (v *Vim) Shutdown(ctx context.Context) error {
+	return v.closeHook(v.ep.Shutdown(ctx))
+}
+
+func (v *Vim) closeHook(err error) error {
 	if v.close != nil {
 		errc := v.close()
 		if err == nil {
@@ -52,10 +101,13 @@ func (v *Vim) Close() error {
 
 // New create a Neovim client. When connecting to Neovim over stdio, use stdin
 // as r and stdout as wc. When connecting to Neovim over a network connection,
-// use the connection for both r and wc.
+// use the connection for both r and wc, or use Dial.
+//
+//	:help msgpack-rpc-connecting
 //
-//  :help msgpack-rpc-connecting
-func New(r io.Reader, wc io.WriteCloser, logf func(string, ...interface{})) (*Vim, error) {
+// The options specify additional options for the MessagePack RPC endpoint,
+// such as rpc.WithClientInterceptor and rpc.WithServerInterceptor.
+func New(r io.Reader, wc io.WriteCloser, logf func(string, ...interface{}), options ...rpc.Option) (*Vim, error) {
 	v := &Vim{}
 
 	rwc := struct {
@@ -64,7 +116,7 @@ func New(r io.Reader, wc io.WriteCloser, logf func(string, ...interface{})) (*Vi
 	}{r, wc}
 
 	var err error
-	v.ep, err = rpc.NewEndpoint(rwc, withExtensions(), rpc.WithLogf(logf), rpc.WithFirstArg(v))
+	v.ep, err = rpc.NewEndpoint(rwc, endpointOptions(v, logf, options)...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +124,34 @@ func New(r io.Reader, wc io.WriteCloser, logf func(string, ...interface{})) (*Vi
 	return v, nil
 }
 
+func endpointOptions(v *Vim, logf func(string, ...interface{}), options []rpc.Option) []rpc.Option {
+	options = append(options[:len(options):len(options)], withExtensions(), rpc.WithFirstArg(v))
+	if logf != nil {
+		options = append(options, rpc.WithLogf(logf))
+	}
+	return options
+}
+
+// NewServer returns a MessagePack RPC server for accepting connections from
+// multiple instances of Neovim. The first argument to handlers registered
+// with the server is the *Vim for the connection. Use the endpoint's FirstArg
+// method to get the *Vim in the server's connection callbacks.
+//
+// The server runs the RPC server loop for each connection. Do not call the
+// Serve method on the *Vim.
+func NewServer(logf func(string, ...interface{}), options ...rpc.Option) *rpc.Server {
+	options = append(options[:len(options):len(options)], withExtensions())
+	if logf != nil {
+		options = append(options, rpc.WithLogf(logf))
+	}
+	return &rpc.Server{
+		Options: options,
+		NewFirstArg: func(ep *rpc.Endpoint) interface{} {
+			return &Vim{ep: ep}
+		},
+	}
+}
+
 // EmbedOptions specifies options for starting an embedded instance of Neovim.
 type EmbedOptions struct {
 	// Args specifies the command line arguments. Do not include the program
@@ -91,6 +171,11 @@ type EmbedOptions struct {
 	Path string
 
 	Logf func(string, ...interface{})
+
+	// RPCOptions specifies additional options for the MessagePack RPC
+	// endpoint, such as rpc.WithClientInterceptor and
+	// rpc.WithServerInterceptor.
+	RPCOptions []rpc.Option
 }
 
 // StartEmbeddedVim starts an embedded instance of Neovim using the specified
@@ -134,7 +219,7 @@ func StartEmbeddedVim(options *EmbedOptions) (*Vim, error) {
 		io.WriteCloser
 	}{outr, inw}
 
-	v.ep, err = rpc.NewEndpoint(rwc, withExtensions(), rpc.WithLogf(options.Logf), rpc.WithFirstArg(v))
+	v.ep, err = rpc.NewEndpoint(rwc, endpointOptions(v, options.Logf, options.RPCOptions)...)
 	if err != nil {
 		return nil, err
 	}
@@ -188,48 +273,53 @@ func StartEmbeddedVim(options *EmbedOptions) (*Vim, error) {
 // RegisterHandler registers fn as a MessagePack RPC handler for the named
 // method. The function signature for fn is one of
 //
-//  func(v *vim.Vim, {args}) ({resultType}, error)
-//  func(v *vim.Vim, {args}) error
-//  func(v *vim.Vim, {args})
+//	func(v *vim.Vim, [ctx context.Context,] {args}) ({resultType}, error)
+//	func(v *vim.Vim, [ctx context.Context,] {args}) error
+//	func(v *vim.Vim, [ctx context.Context,] {args})
 //
 // where {args} is zero or more arguments and {resultType} is the type of of a
-// return value. Call the handler from Neovim using the rpcnotify and
+// return value. The optional context is cancelled when the handler returns or
+// the client is closed. Call the handler from Neovim using the rpcnotify and
 // rpcrequest functions:
 //
-//  :help rpcrequest()
-//  :help rpcnotify()
+//	:help rpcrequest()
+//	:help rpcnotify()
+//
+// The options control how calls to the handler are scheduled. See
+// rpc.Serial, rpc.Parallel, rpc.LatestWins and rpc.InQueue.
 //
 // Plugin applications should use the Handler* methods in the ./plugin package
 // to register handlers instead of this method.
-func (v *Vim) RegisterHandler(method string, fn interface{}) error {
-	return v.ep.RegisterHandler(method, fn)
+func (v *Vim) RegisterHandler(method string, fn interface{}, options ...rpc.HandlerOption) error {
+	return v.ep.RegisterHandler(method, fn, options...)
 }
 
 // ChannelID returns Neovim's channel id for this client.
 func (v *Vim) ChannelID() (int, error) {
-	v.mu.Lock()
-	defer v.mu.Unlock()
-	if v.channelID != 0 {
-		return v.channelID, nil
-	}
-	var info struct {
-		ChannelID int `msgpack:",array"`
-		Info      interface{}
-	}
-	if err := v.ep.Call("vim_get_api_info", &info); err != nil {
+	info, err := v.APIMetadata()
+	if err != nil {
 		return 0, err
 	}
-	v.channelID = info.ChannelID
-	return v.channelID, nil
+	return info.ChannelID, nil
 }
 
 func (v *Vim) call(sm string, result interface{}, args ...interface{}) error {
+	if err := v.checkLevel(sm); err != nil {
+		return err
+	}
 	return fixError(sm, v.ep.Call(sm, result, args...))
 }
 
+func (v *Vim) callContext(ctx context.Context, sm string, result interface{}, args ...interface{}) error {
+	if err := v.checkLevel(sm); err != nil {
+		return err
+	}
+	return fixError(sm, v.ep.CallContext(ctx, sm, result, args...))
+}
+
 // NewPipeline creates a new pipeline.
 func (v *Vim) NewPipeline() *Pipeline {
-	return &Pipeline{ep: v.ep}
+	return &Pipeline{v: v, ep: v.ep}
 }
 
 // Pipeline pipelines calls to Neovim. The underlying calls to Neovim execute
@@ -238,80 +328,255 @@ func (v *Vim) NewPipeline() *Pipeline {
 //
 // Pipelines do not support concurrent calls by the application.
 type Pipeline struct {
+	v     *Vim
 	ep    *rpc.Endpoint
 	n     int
 	done  chan *rpc.Call
 	chans []chan *rpc.Call
+	calls []*rpc.Call
+
+	// batch holds the calls for an atomic pipeline. Atomic pipelines send
+	// the batch in the Wait method.
+	atomic bool
+	batch  []atomicCall
+	err    error
 }
 
 const doneChunkSize = 32
 
 func (p *Pipeline) call(sm string, result interface{}, args ...interface{}) {
+	err := p.v.checkLevel(sm)
+	if p.atomic {
+		if err != nil && p.err == nil {
+			p.err = &CallError{Index: len(p.batch), Err: err}
+		}
+		p.batch = append(p.batch, atomicCall{sm: sm, result: result, args: args})
+		return
+	}
 	if p.n%doneChunkSize == 0 {
 		done := make(chan *rpc.Call, doneChunkSize)
 		p.done = done
 		p.chans = append(p.chans, done)
 	}
 	p.n++
-	p.ep.Go(sm, p.done, result, args...)
+	if err != nil {
+		// Complete the call without sending it to Neovim.
+		c := &rpc.Call{ServiceMethod: sm, Reply: result, Err: err, Done: p.done}
+		p.calls = append(p.calls, c)
+		p.done <- c
+		return
+	}
+	p.calls = append(p.calls, p.ep.Go(sm, p.done, result, args...))
+}
+
+// index returns the position of call c in the pipeline.
+func (p *Pipeline) index(c *rpc.Call) int {
+	for i, call := range p.calls {
+		if call == c {
+			return i
+		}
+	}
+	return -1
 }
 
 // Wait waits for all calls in the pipeline to complete. If there is more than
 // one call in the pipeline, then Wait returns errors using type ErrorList.
 func (p *Pipeline) Wait() error {
+	return p.WaitContext(context.Background())
+}
+
+// WaitContext is like Wait, but abandons the calls that have not completed
+// when ctx is done. If calls are abandoned, then WaitContext returns
+// ctx.Err().
+func (p *Pipeline) WaitContext(ctx context.Context) error {
+	if p.atomic {
+		return p.waitAtomic(ctx)
+	}
 	var el ErrorList
 	var done chan *rpc.Call
 	useList := p.n > 1
+	cancelled := false
+	ctxDone := ctx.Done()
 	for i := 0; i < p.n; i++ {
 		if i%doneChunkSize == 0 {
 			done = p.chans[0]
 			p.chans = p.chans[1:]
 		}
-		c := <-done
+		var c *rpc.Call
+		select {
+		case c = <-done:
+		case <-ctxDone:
+			ctxDone = nil
+			for _, call := range p.calls {
+				if p.ep.Cancel(call, ctx.Err()) {
+					cancelled = true
+				}
+			}
+			c = <-done
+		}
 		if c.Err != nil {
-			el = append(el, fixError(c.ServiceMethod, c.Err))
+			el = append(el, &CallError{Index: p.index(c), Err: fixError(c.ServiceMethod, c.Err)})
 		}
 	}
 	p.n = 0
 	p.done = nil
 	p.chans = nil
+	p.calls = nil
 	switch {
+	case cancelled:
+		return ctx.Err()
 	case len(el) == 0:
 		return nil
 	case useList:
 		return el
 	default:
-		return el[0]
+		return el[0].(*CallError).Err
+	}
+}
+
+// ErrorKind is the kind of an error returned by the Neovim API.
+type ErrorKind int
+
+const (
+	// ExceptionError is the kind of error returned when an API call fails
+	// while running, for example when Vimscript evaluation fails.
+	ExceptionError ErrorKind = exceptionError
+
+	// ValidationError is the kind of error returned when the arguments to an
+	// API call are not valid, for example when a buffer handle is stale.
+	ValidationError ErrorKind = validationError
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ExceptionError:
+		return "exception"
+	case ValidationError:
+		return "validation"
+	default:
+		return fmt.Sprintf("error %d", int(k))
 	}
 }
 
+// APIError is an error returned by the Neovim API. Use errors.As to test for
+// an APIError:
+//
+//	var e *vim.APIError
+//	if errors.As(err, &e) && e.Kind == vim.ValidationError {
+//	    // handle invalid argument
+//	}
+//
+// Handlers can return an *APIError to reply to Neovim with an error of the
+// specified kind.
+type APIError struct {
+	// Kind is the kind of error.
+	Kind ErrorKind
+
+	// Message is the error message from Neovim.
+	Message string
+
+	// Method is the API method that returned the error. Method is "" for
+	// errors returned by handlers.
+	Method string
+}
+
+func (e *APIError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("nvim: %s: %s", e.Kind, e.Message)
+	}
+	return fmt.Sprintf("nvim:%s %s: %s", e.Method, e.Kind, e.Message)
+}
+
+// MarshalMsgPack encodes the error in the format used by Neovim, the array
+// [kind, message].
+func (e *APIError) MarshalMsgPack(enc *msgpack.Encoder) error {
+	if err := enc.PackArrayLen(2); err != nil {
+		return err
+	}
+	if err := enc.PackInt(int64(e.Kind)); err != nil {
+		return err
+	}
+	return enc.PackString(e.Message)
+}
+
+// fixError converts Neovim's [kind, message] error replies to *APIError.
 func fixError(sm string, err error) error {
 	if e, ok := err.(rpc.Error); ok {
 		if a, ok := e.Value.([]interface{}); ok && len(a) == 2 {
-			switch a[0] {
-			case int64(exceptionError), uint64(exceptionError):
-				return fmt.Errorf("nvim:%s exception: %v", sm, a[1])
-			case int64(validationError), uint64(validationError):
-				return fmt.Errorf("nvim:%s validation: %v", sm, a[1])
+			var kind ErrorKind
+			switch k := a[0].(type) {
+			case int64:
+				kind = ErrorKind(k)
+			case uint64:
+				kind = ErrorKind(k)
+			default:
+				return err
+			}
+			msg, ok := a[1].(string)
+			if !ok {
+				msg = fmt.Sprint(a[1])
 			}
+			return &APIError{Kind: kind, Message: msg, Method: sm}
 		}
 	}
 	return err
 }
 
-// ErrorList is a list of errors.
+// CallError is the error for a call in a pipeline.
+type CallError struct {
+	// Index is the position of the call in the pipeline, starting from zero.
+	Index int
+
+	// Err is the error returned from the call.
+	Err error
+}
+
+func (e *CallError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error returned from the call.
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList is a list of errors. The errors returned by pipelines have type
+// *CallError.
 type ErrorList []error
 
 func (el ErrorList) Error() string {
 	return el[0].Error()
 }
 
+// Unwrap returns the errors in the list.
+func (el ErrorList) Unwrap() []error {
+	return el
+}
+
+// DeprecatedFunction returns the API function that replaces the deprecated
+// API function sm. The replacement is "" when Neovim does not provide a
+// replacement. The ok result is false if sm is not a known deprecated
+// function.
+func DeprecatedFunction(sm string) (replacement string, ok bool) {
+	replacement, ok = deprecatedFunctions[sm]
+	return replacement, ok
+}
+
 // Call calls a vimscript function.
 func (v *Vim) Call(fname string, result interface{}, args ...interface{}) error {
 	if args == nil {
 		args = []interface{}{}
 	}
-	return v.call("vim_call_function", result, fname, args)
+	return v.call("nvim_call_function", result, fname, args)
+}
+
+// CallContext is like Call, but abandons the call and returns ctx.Err() when
+// ctx is done before the call completes.
+func (v *Vim) CallContext(ctx context.Context, fname string, result interface{}, args ...interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	return v.callContext(ctx, "nvim_call_function", result, fname, args)
 }
 
 // Call calls a vimscript function.
@@ -319,7 +584,7 @@ func (p *Pipeline) Call(fname string, result interface{}, args ...interface{}) {
 	if args == nil {
 		args = []interface{}{}
 	}
-	p.call("vim_call_function", result, fname, args)
+	p.call("nvim_call_function", result, fname, args)
 }
 
 // decodeExt decodes a MsgPack encoded number to an integer.
@@ -0,0 +1,2164 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vimfake implements an in-process fake Neovim for testing.
+//
+// The fake implements the Neovim API methods used by package vim against an
+// in-memory model of buffers, windows, tabpages, variables and options. The
+// fake serves the API over a MessagePack RPC connection, so the *vim.Vim
+// returned from the Vim method cannot tell the difference between the fake
+// and a real instance of Neovim.
+//
+// The fake does not evaluate Vimscript or Lua. Use the StubEval, StubFunction
+// and StubLua methods to set the results of nvim_eval, nvim_call_function and
+// nvim_exec_lua. The fake implements the rpcrequest() and rpcnotify()
+// functions by calling the handlers registered with the client.
+package vimfake
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/garyburd/neovim-go/msgpack"
+	"github.com/garyburd/neovim-go/msgpack/rpc"
+	"github.com/garyburd/neovim-go/vim"
+)
+
+const (
+	exceptionError  = 0
+	validationError = 1
+)
+
+func exceptionf(format string, args ...interface{}) error {
+	return rpc.Error{Value: []interface{}{exceptionError, fmt.Sprintf(format, args...)}}
+}
+
+func validationf(format string, args ...interface{}) error {
+	return rpc.Error{Value: []interface{}{validationError, fmt.Sprintf(format, args...)}}
+}
+
+// Highlight represents a highlight added with nvim_buf_add_highlight.
+type Highlight struct {
+	SrcID    int
+	HLGroup  string
+	Line     int
+	StartCol int
+	EndCol   int
+}
+
+// Autocmd is an autocmd created with nvim_create_autocmd.
+type Autocmd struct {
+	ID      int
+	Events  []string
+	Buffer  vim.Buffer
+	Pattern []string
+	Command string
+	Once    bool
+}
+
+// keymapKey identifies a keymap. The buffer is zero for a global keymap.
+type keymapKey struct {
+	buffer vim.Buffer
+	mode   string
+	lhs    string
+}
+
+// userCommandKey identifies a user command. The buffer is zero for a global
+// command.
+type userCommandKey struct {
+	buffer vim.Buffer
+	name   string
+}
+
+type buffer struct {
+	lines      [][]byte
+	name       string
+	vars       map[string]interface{}
+	options    map[string]interface{}
+	marks      map[string][2]int
+	highlights []Highlight
+
+	changedTick int
+	attached    bool
+
+	// extmarks maps namespace ids to the extmarks in the namespace.
+	extmarks    map[int]map[int]*extmark
+	nextExtmark int
+}
+
+type extmark struct {
+	row  int
+	col  int
+	opts vim.ExtmarkOptions
+}
+
+type window struct {
+	buffer   vim.Buffer
+	tabpage  vim.Tabpage
+	cursor   [2]int
+	height   int
+	width    int
+	position [2]int
+	vars     map[string]interface{}
+	options  map[string]interface{}
+
+	// config is the layout of a floating window or nil.
+	config *vim.WindowConfig
+}
+
+type tabpage struct {
+	windows []vim.Window
+	window  vim.Window
+	vars    map[string]interface{}
+}
+
+// Fake is a fake instance of Neovim.
+type Fake struct {
+	v    *vim.Vim
+	ep   *rpc.Endpoint
+	done chan struct{}
+
+	mu sync.Mutex
+
+	buffers  map[vim.Buffer]*buffer
+	windows  map[vim.Window]*window
+	tabpages map[vim.Tabpage]*tabpage
+
+	nextBuffer  vim.Buffer
+	nextWindow  vim.Window
+	nextTabpage vim.Tabpage
+	nextSrcID   int
+	namespaces  map[string]int
+
+	tabpage vim.Tabpage
+
+	apiLevel int
+
+	// uiOptions is the options passed to nvim_ui_attach or nil if no UI is
+	// attached.
+	uiOptions map[string]interface{}
+
+	vars     map[string]interface{}
+	vvars    map[string]interface{}
+	options  map[string]interface{}
+	cwd      string
+	rtp      []string
+	events   map[string]bool
+	commands []string
+	input    []string
+	out      []string
+	err      []string
+
+	evals     map[string]interface{}
+	functions map[string]func(args []interface{}) (interface{}, error)
+	cmdStubs  map[string]func() (string, error)
+	lua       func(code string, args []interface{}) (interface{}, error)
+
+	keymaps      map[keymapKey]string
+	userCommands map[userCommandKey]string
+	autocmds     map[int]*Autocmd
+	nextAutocmd  int
+}
+
+// New starts a fake instance of Neovim. Use the Vim method to get the client
+// connected to the fake. The fake has one tabpage with one window showing an
+// empty buffer.
+func New(logf func(string, ...interface{})) (*Fake, error) {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+
+	f := &Fake{
+		done:        make(chan struct{}),
+		buffers:     make(map[vim.Buffer]*buffer),
+		windows:     make(map[vim.Window]*window),
+		tabpages:    make(map[vim.Tabpage]*tabpage),
+		nextBuffer:  1,
+		nextWindow:  1000,
+		nextTabpage: 1,
+		nextSrcID:   1,
+		namespaces:  make(map[string]int),
+		apiLevel:    11,
+		vars:        make(map[string]interface{}),
+		vvars:       map[string]interface{}{"count": 0, "progname": "nvim"},
+		options:     map[string]interface{}{"shiftwidth": 8, "tabstop": 8, "ignorecase": false, "columns": 80, "lines": 24},
+		cwd:         "/",
+		events:      make(map[string]bool),
+		evals:       make(map[string]interface{}),
+		functions:   make(map[string]func(args []interface{}) (interface{}, error)),
+		cmdStubs:    make(map[string]func() (string, error)),
+
+		keymaps:      make(map[keymapKey]string),
+		userCommands: make(map[userCommandKey]string),
+		autocmds:     make(map[int]*Autocmd),
+		nextAutocmd:  1,
+	}
+	f.tabpage = f.newTabpage(f.newBuffer("", nil))
+
+	serverConn, clientConn := net.Pipe()
+
+	var err error
+	f.ep, err = rpc.NewEndpoint(serverConn, rpc.WithLogf(logf))
+	if err != nil {
+		return nil, err
+	}
+
+	// Neovim handles requests one at a time in the order received. Calls to
+	// nvim_call_function run concurrently with other calls so that the
+	// handlers called through rpcrequest() can call back to the fake.
+	q := rpc.NewQueue()
+	for sm, fn := range f.methods() {
+		var options []rpc.HandlerOption
+		if sm != "nvim_call_function" {
+			options = append(options, rpc.InQueue(q))
+		}
+		if err := f.ep.RegisterHandler(sm, fn, options...); err != nil {
+			return nil, err
+		}
+	}
+
+	f.v, err = vim.New(clientConn, clientConn, logf)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		f.ep.Serve()
+		close(f.done)
+	}()
+	go f.v.Serve()
+
+	return f, nil
+}
+
+// Vim returns the client connected to the fake.
+func (f *Fake) Vim() *vim.Vim {
+	return f.v
+}
+
+// Close closes the client and the fake.
+func (f *Fake) Close() error {
+	err := f.v.Close()
+	f.ep.Close()
+	<-f.done
+	return err
+}
+
+// SetAPILevel sets the API level reported by the fake. The default level is
+// 11. The client caches the API info, so call SetAPILevel before the first
+// call to the client.
+func (f *Fake) SetAPILevel(level int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.apiLevel = level
+}
+
+// StubEval sets the result of evaluating expr with nvim_eval.
+func (f *Fake) StubEval(expr string, result interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.evals[expr] = result
+}
+
+// StubFunction sets fn as the implementation of the named Vimscript function
+// for nvim_call_function.
+func (f *Fake) StubFunction(name string, fn func(args []interface{}) (interface{}, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.functions[name] = fn
+}
+
+// StubLua sets fn as the implementation of nvim_exec_lua. The fn function is
+// called with the Lua code and the arguments.
+func (f *Fake) StubLua(fn func(code string, args []interface{}) (interface{}, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lua = fn
+}
+
+// StubCommand sets fn as the implementation of the ex command cmd. The string
+// returned from fn is the output of the command for nvim_command_output. Ex
+// commands that are not stubbed succeed with no output.
+func (f *Fake) StubCommand(cmd string, fn func() (string, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cmdStubs[cmd] = fn
+}
+
+// Commands returns the ex commands executed by the fake.
+func (f *Fake) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.commands...)
+}
+
+// Input returns the keys sent to the fake with nvim_input and nvim_feedkeys.
+func (f *Fake) Input() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.input...)
+}
+
+// Output returns the messages written with nvim_out_write.
+func (f *Fake) Output() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.out, "")
+}
+
+// ErrorOutput returns the messages written with nvim_err_write and
+// nvim_err_writeln.
+func (f *Fake) ErrorOutput() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.err, "")
+}
+
+// Highlights returns the highlights in buffer b.
+func (f *Fake) Highlights(b vim.Buffer) []Highlight {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if buf := f.buffers[b]; buf != nil {
+		return append([]Highlight(nil), buf.highlights...)
+	}
+	return nil
+}
+
+// NewBuffer creates a buffer with the given name and lines.
+func (f *Fake) NewBuffer(name string, lines ...string) vim.Buffer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := make([][]byte, len(lines))
+	for i, line := range lines {
+		p[i] = []byte(line)
+	}
+	return f.newBuffer(name, p)
+}
+
+// NewWindow creates a window showing buffer b in the current tabpage.
+func (f *Fake) NewWindow(b vim.Buffer) vim.Window {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.newWindow(f.tabpage, b)
+}
+
+// NewTabpage creates a tabpage with one window showing buffer b.
+func (f *Fake) NewTabpage(b vim.Buffer) vim.Tabpage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.newTabpage(b)
+}
+
+// Notify sends a notification for event to the client if the client
+// subscribed to the event with nvim_subscribe.
+func (f *Fake) Notify(event string, args ...interface{}) error {
+	f.mu.Lock()
+	subscribed := f.events[event]
+	f.mu.Unlock()
+	if !subscribed {
+		return nil
+	}
+	return f.ep.Notify(event, args...)
+}
+
+// Call sends a request for method to the client and waits for the reply, as
+// Neovim does for rpcrequest(). Use Call to test the handlers registered by
+// the client.
+func (f *Fake) Call(method string, result interface{}, args ...interface{}) error {
+	return f.ep.Call(method, result, args...)
+}
+
+// Redraw sends a redraw notification with the given batches to the client
+// if the client is attached as a UI. Each batch is the event name followed by
+// the arguments for one or more events.
+//
+//	f.Redraw([]interface{}{"grid_cursor_goto", []interface{}{1, 0, 5}},
+//	    []interface{}{"flush", []interface{}{}})
+func (f *Fake) Redraw(batches ...[]interface{}) error {
+	f.mu.Lock()
+	attached := f.uiOptions != nil
+	f.mu.Unlock()
+	if !attached {
+		return nil
+	}
+	args := make([]interface{}, len(batches))
+	for i, b := range batches {
+		args[i] = b
+	}
+	return f.ep.Notify("redraw", args...)
+}
+
+// UIOptions returns the options passed to nvim_ui_attach or nil if the
+// client is not attached as a UI.
+func (f *Fake) UIOptions() map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.uiOptions
+}
+
+// Subscribed returns true if the client is subscribed to event.
+func (f *Fake) Subscribed(event string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.events[event]
+}
+
+// Keymap returns the rhs of the keymap for lhs in mode. Use buffer zero for
+// a global keymap.
+func (f *Fake) Keymap(b vim.Buffer, mode, lhs string) (rhs string, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rhs, ok = f.keymaps[keymapKey{b, mode, lhs}]
+	return rhs, ok
+}
+
+// UserCommand returns the replacement text of the named user command. Use
+// buffer zero for a global command.
+func (f *Fake) UserCommand(b vim.Buffer, name string) (command string, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	command, ok = f.userCommands[userCommandKey{b, name}]
+	return command, ok
+}
+
+// Autocmds returns the autocmds for event sorted by id.
+func (f *Fake) Autocmds(event string) []*Autocmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var autocmds []*Autocmd
+	for _, a := range f.autocmds {
+		for _, e := range a.Events {
+			if e == event {
+				x := *a
+				autocmds = append(autocmds, &x)
+				break
+			}
+		}
+	}
+	sort.Slice(autocmds, func(i, j int) bool { return autocmds[i].ID < autocmds[j].ID })
+	return autocmds
+}
+
+func (f *Fake) newBuffer(name string, lines [][]byte) vim.Buffer {
+	if len(lines) == 0 {
+		lines = [][]byte{{}}
+	}
+	b := f.nextBuffer
+	f.nextBuffer++
+	f.buffers[b] = &buffer{
+		lines:   lines,
+		name:    name,
+		vars:    make(map[string]interface{}),
+		options: map[string]interface{}{"buftype": "", "filetype": "", "modified": false},
+		marks:   make(map[string][2]int),
+
+		changedTick: 1,
+		extmarks:    make(map[int]map[int]*extmark),
+		nextExtmark: 1,
+	}
+	return b
+}
+
+func (f *Fake) newWindow(t vim.Tabpage, b vim.Buffer) vim.Window {
+	w := f.nextWindow
+	f.nextWindow++
+	f.windows[w] = &window{
+		buffer:  b,
+		tabpage: t,
+		cursor:  [2]int{1, 0},
+		height:  22,
+		width:   80,
+		vars:    make(map[string]interface{}),
+		options: map[string]interface{}{"number": false, "wrap": true},
+	}
+	tp := f.tabpages[t]
+	tp.windows = append(tp.windows, w)
+	tp.window = w
+	return w
+}
+
+func (f *Fake) newTabpage(b vim.Buffer) vim.Tabpage {
+	t := f.nextTabpage
+	f.nextTabpage++
+	f.tabpages[t] = &tabpage{vars: make(map[string]interface{})}
+	f.newWindow(t, b)
+	f.tabpage = t
+	return t
+}
+
+func (f *Fake) methods() map[string]interface{} {
+	return map[string]interface{}{
+		"nvim_buf_attach":              f.bufferAttach,
+		"nvim_buf_detach":              f.bufferDetach,
+		"nvim_buf_get_changedtick":     f.bufferGetChangedTick,
+		"nvim_buf_line_count":          f.bufferLineCount,
+		"nvim_buf_get_lines":           f.bufferGetLines,
+		"nvim_buf_set_lines":           f.bufferSetLines,
+		"nvim_buf_get_var":             f.bufferGetVar,
+		"nvim_buf_del_var":             f.bufferDelVar,
+		"nvim_buf_set_var":             f.bufferSetVar,
+		"nvim_buf_get_option":          f.bufferGetOption,
+		"nvim_buf_set_option":          f.bufferSetOption,
+		"nvim_buf_get_number":          f.bufferGetNumber,
+		"nvim_buf_get_name":            f.bufferGetName,
+		"nvim_buf_set_name":            f.bufferSetName,
+		"nvim_buf_is_valid":            f.bufferIsValid,
+		"nvim_buf_delete":              f.bufferDelete,
+		"nvim_buf_get_mark":            f.bufferGetMark,
+		"nvim_buf_add_highlight":       f.bufferAddHighlight,
+		"nvim_buf_clear_namespace":     f.bufferClearHighlight,
+		"nvim_buf_clear_highlight":     f.bufferClearHighlight,
+		"nvim_buf_set_extmark":         f.bufferSetExtmark,
+		"nvim_buf_get_extmark_by_id":   f.bufferGetExtmarkByID,
+		"nvim_buf_get_extmarks":        f.bufferGetExtmarks,
+		"nvim_buf_del_extmark":         f.bufferDelExtmark,
+		"nvim_create_namespace":        f.createNamespace,
+		"nvim_get_namespaces":          f.getNamespaces,
+		"nvim_tabpage_list_wins":       f.tabpageGetWindows,
+		"nvim_tabpage_get_var":         f.tabpageGetVar,
+		"nvim_tabpage_del_var":         f.tabpageDelVar,
+		"nvim_tabpage_set_var":         f.tabpageSetVar,
+		"nvim_tabpage_get_win":         f.tabpageGetWindow,
+		"nvim_tabpage_is_valid":        f.tabpageIsValid,
+		"nvim_command":                 f.command,
+		"nvim_feedkeys":                f.feedkeys,
+		"nvim_input":                   f.vimInput,
+		"nvim_replace_termcodes":       f.replaceTermcodes,
+		"nvim_command_output":          f.commandOutput,
+		"nvim_eval":                    f.eval,
+		"nvim_call_function":           f.callFunction,
+		"nvim_exec_lua":                f.execLua,
+		"nvim_strwidth":                f.strwidth,
+		"nvim_list_runtime_paths":      f.listRuntimePaths,
+		"nvim_set_current_dir":         f.changeDirectory,
+		"nvim_get_current_line":        f.getCurrentLine,
+		"nvim_set_current_line":        f.setCurrentLine,
+		"nvim_del_current_line":        f.delCurrentLine,
+		"nvim_get_var":                 f.getVar,
+		"nvim_del_var":                 f.delVar,
+		"nvim_set_var":                 f.setVar,
+		"nvim_get_vvar":                f.getVvar,
+		"nvim_get_option":              f.getOption,
+		"nvim_set_option":              f.setOption,
+		"nvim_out_write":               f.outWrite,
+		"nvim_err_write":               f.errWrite,
+		"nvim_err_writeln":             f.reportError,
+		"nvim_list_bufs":               f.getBuffers,
+		"nvim_get_current_buf":         f.getCurrentBuffer,
+		"nvim_set_current_buf":         f.setCurrentBuffer,
+		"nvim_list_wins":               f.getWindows,
+		"nvim_get_current_win":         f.getCurrentWindow,
+		"nvim_set_current_win":         f.setCurrentWindow,
+		"nvim_list_tabpages":           f.getTabpages,
+		"nvim_get_current_tabpage":     f.getCurrentTabpage,
+		"nvim_set_current_tabpage":     f.setCurrentTabpage,
+		"nvim_subscribe":               f.subscribe,
+		"nvim_unsubscribe":             f.unsubscribe,
+		"nvim_get_color_by_name":       f.nameToColor,
+		"nvim_get_color_map":           f.getColorMap,
+		"nvim_get_api_info":            f.getAPIInfo,
+		"nvim_ui_attach":               f.uiAttach,
+		"nvim_ui_detach":               f.uiDetach,
+		"nvim_call_atomic":             f.callAtomic,
+		"nvim_win_get_buf":             f.windowGetBuffer,
+		"nvim_win_get_cursor":          f.windowGetCursor,
+		"nvim_win_set_cursor":          f.windowSetCursor,
+		"nvim_win_get_height":          f.windowGetHeight,
+		"nvim_win_set_height":          f.windowSetHeight,
+		"nvim_win_get_width":           f.windowGetWidth,
+		"nvim_win_set_width":           f.windowSetWidth,
+		"nvim_win_get_var":             f.windowGetVar,
+		"nvim_win_del_var":             f.windowDelVar,
+		"nvim_win_set_var":             f.windowSetVar,
+		"nvim_win_get_option":          f.windowGetOption,
+		"nvim_win_set_option":          f.windowSetOption,
+		"nvim_win_get_position":        f.windowGetPosition,
+		"nvim_win_get_tabpage":         f.windowGetTabpage,
+		"nvim_win_is_valid":            f.windowIsValid,
+		"nvim_win_get_config":          f.windowGetConfig,
+		"nvim_win_set_config":          f.windowSetConfig,
+		"nvim_win_close":               f.windowClose,
+		"nvim_open_win":                f.openWindow,
+		"nvim_create_buf":              f.createBuffer,
+		"nvim_set_keymap":              f.setKeymap,
+		"nvim_del_keymap":              f.delKeymap,
+		"nvim_buf_set_keymap":          f.bufferSetKeymap,
+		"nvim_buf_del_keymap":          f.bufferDelKeymap,
+		"nvim_create_user_command":     f.createUserCommand,
+		"nvim_del_user_command":        f.delUserCommand,
+		"nvim_buf_create_user_command": f.bufferCreateUserCommand,
+		"nvim_buf_del_user_command":    f.bufferDelUserCommand,
+		"nvim_create_autocmd":          f.createAutocmd,
+		"nvim_del_autocmd":             f.delAutocmd,
+	}
+}
+
+// The following methods must be called with f.mu held.
+
+func (f *Fake) buffer(b vim.Buffer) (*buffer, error) {
+	buf := f.buffers[b]
+	if buf == nil {
+		return nil, validationf("Invalid buffer id")
+	}
+	return buf, nil
+}
+
+func (f *Fake) window(w vim.Window) (*window, error) {
+	if w == 0 {
+		// Zero is the current window.
+		w = f.tabpages[f.tabpage].window
+	}
+	win := f.windows[w]
+	if win == nil {
+		return nil, validationf("Invalid window id")
+	}
+	return win, nil
+}
+
+func (f *Fake) tabpageByID(t vim.Tabpage) (*tabpage, error) {
+	tp := f.tabpages[t]
+	if tp == nil {
+		return nil, validationf("Invalid tabpage id")
+	}
+	return tp, nil
+}
+
+func (f *Fake) currentWindow() (vim.Window, *window) {
+	w := f.tabpages[f.tabpage].window
+	return w, f.windows[w]
+}
+
+// Buffers
+
+func (f *Fake) bufferLineCount(b vim.Buffer) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return 0, err
+	}
+	return len(buf.lines), nil
+}
+
+// normalizeIndex converts a line index as specified by buffer_get_lines to a
+// position in a slice of length n.
+func normalizeIndex(index, n int, strict bool) (int, error) {
+	if index < 0 {
+		index = n + 1 + index
+	}
+	if index < 0 || index > n {
+		if strict {
+			return 0, validationf("Index out of bounds")
+		}
+		if index < 0 {
+			index = 0
+		} else {
+			index = n
+		}
+	}
+	return index, nil
+}
+
+func lineRange(buf *buffer, start, end int, strict bool) (int, int, error) {
+	start, err := normalizeIndex(start, len(buf.lines), strict)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err = normalizeIndex(end, len(buf.lines), strict)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start > end {
+		return 0, 0, validationf("Argument \"start\" is higher than \"end\"")
+	}
+	return start, end, nil
+}
+
+func (f *Fake) bufferGetLines(b vim.Buffer, start, end int, strict bool) ([][]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err = lineRange(buf, start, end, strict)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([][]byte, end-start)
+	copy(lines, buf.lines[start:end])
+	return lines, nil
+}
+
+func (f *Fake) bufferSetLines(b vim.Buffer, start, end int, strict bool, replacement [][]byte) error {
+	f.mu.Lock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	start, end, err = lineRange(buf, start, end, strict)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	for _, line := range replacement {
+		if strings.ContainsRune(string(line), '\n') {
+			f.mu.Unlock()
+			return validationf("String cannot contain newlines")
+		}
+	}
+	lines := make([][]byte, 0, len(buf.lines)-(end-start)+len(replacement))
+	lines = append(lines, buf.lines[:start]...)
+	lines = append(lines, replacement...)
+	lines = append(lines, buf.lines[end:]...)
+	if len(lines) == 0 {
+		// A buffer always has at least one line.
+		lines = [][]byte{{}}
+		replacement = lines
+	}
+	buf.lines = lines
+	buf.moveExtmarks(start, end, len(replacement))
+	buf.options["modified"] = true
+	buf.changedTick++
+	attached, changedTick := buf.attached, buf.changedTick
+	f.mu.Unlock()
+
+	if attached {
+		return f.ep.Notify("nvim_buf_lines_event", b, changedTick, start, end, replacement, false)
+	}
+	return nil
+}
+
+func (f *Fake) bufferAttach(b vim.Buffer, sendBuffer bool, opts map[string]interface{}) (bool, error) {
+	f.mu.Lock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		f.mu.Unlock()
+		return false, err
+	}
+	buf.attached = true
+	lines, changedTick := buf.lines, buf.changedTick
+	f.mu.Unlock()
+
+	if sendBuffer {
+		if err := f.ep.Notify("nvim_buf_lines_event", b, changedTick, 0, -1, lines, false); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (f *Fake) bufferDetach(b vim.Buffer) (bool, error) {
+	f.mu.Lock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		f.mu.Unlock()
+		return false, err
+	}
+	attached := buf.attached
+	buf.attached = false
+	f.mu.Unlock()
+
+	if attached {
+		if err := f.ep.Notify("nvim_buf_detach_event", b); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (f *Fake) bufferGetChangedTick(b vim.Buffer) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return 0, err
+	}
+	return buf.changedTick, nil
+}
+
+func getVar(vars map[string]interface{}, name string) (interface{}, error) {
+	v, ok := vars[name]
+	if !ok {
+		return nil, validationf("Key not found: %s", name)
+	}
+	return v, nil
+}
+
+func setVar(vars map[string]interface{}, name string, value interface{}) error {
+	vars[name] = value
+	return nil
+}
+
+func delVar(vars map[string]interface{}, name string) error {
+	if _, ok := vars[name]; !ok {
+		return validationf("Key not found: %s", name)
+	}
+	delete(vars, name)
+	return nil
+}
+
+func getOption(options map[string]interface{}, name string) (interface{}, error) {
+	v, ok := options[name]
+	if !ok {
+		return nil, validationf("Invalid option name \"%s\"", name)
+	}
+	return v, nil
+}
+
+func (f *Fake) bufferGetVar(b vim.Buffer, name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return getVar(buf.vars, name)
+}
+
+func (f *Fake) bufferSetVar(b vim.Buffer, name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return err
+	}
+	return setVar(buf.vars, name, value)
+}
+
+func (f *Fake) bufferDelVar(b vim.Buffer, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return err
+	}
+	return delVar(buf.vars, name)
+}
+
+func (f *Fake) bufferGetOption(b vim.Buffer, name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return getOption(buf.options, name)
+}
+
+func (f *Fake) bufferSetOption(b vim.Buffer, name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return err
+	}
+	buf.options[name] = value
+	return nil
+}
+
+func (f *Fake) bufferDelete(b vim.Buffer, opts map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.buffer(b); err != nil {
+		return err
+	}
+	for _, win := range f.windows {
+		if win.buffer == b {
+			return exceptionf("fake does not delete buffers shown in windows")
+		}
+	}
+	delete(f.buffers, b)
+	return nil
+}
+
+func (f *Fake) bufferGetNumber(b vim.Buffer) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.buffer(b); err != nil {
+		return 0, err
+	}
+	return int(b), nil
+}
+
+func (f *Fake) bufferGetName(b vim.Buffer) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return "", err
+	}
+	return buf.name, nil
+}
+
+func (f *Fake) bufferSetName(b vim.Buffer, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return err
+	}
+	buf.name = name
+	return nil
+}
+
+func (f *Fake) bufferIsValid(b vim.Buffer) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.buffers[b] != nil, nil
+}
+
+func (f *Fake) bufferGetMark(b vim.Buffer, name string) ([2]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return [2]int{}, err
+	}
+	if utf8.RuneCountInString(name) != 1 {
+		return [2]int{}, validationf("Mark name must be a single character")
+	}
+	return buf.marks[name], nil
+}
+
+func (f *Fake) bufferAddHighlight(b vim.Buffer, srcID int, hlGroup string, line, startCol, endCol int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return 0, err
+	}
+	if line < 0 || line >= len(buf.lines) {
+		return 0, validationf("Line number outside range")
+	}
+	if srcID == 0 {
+		srcID = f.nextSrcID
+		f.nextSrcID++
+	}
+	if hlGroup != "" {
+		buf.highlights = append(buf.highlights, Highlight{
+			SrcID:    srcID,
+			HLGroup:  hlGroup,
+			Line:     line,
+			StartCol: startCol,
+			EndCol:   endCol,
+		})
+	}
+	return srcID, nil
+}
+
+func (f *Fake) bufferClearHighlight(b vim.Buffer, srcID, startLine, endLine int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return err
+	}
+	if endLine < 0 {
+		endLine = len(buf.lines)
+	}
+	highlights := buf.highlights[:0]
+	for _, h := range buf.highlights {
+		if (srcID < 0 || h.SrcID == srcID) && startLine <= h.Line && h.Line < endLine {
+			continue
+		}
+		highlights = append(highlights, h)
+	}
+	buf.highlights = highlights
+	for ns, marks := range buf.extmarks {
+		if srcID >= 0 && ns != srcID {
+			continue
+		}
+		for id, m := range marks {
+			if startLine <= m.row && m.row < endLine {
+				delete(marks, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Fake) createNamespace(name string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if name != "" {
+		if ns, ok := f.namespaces[name]; ok {
+			return ns, nil
+		}
+	}
+	ns := f.nextSrcID
+	f.nextSrcID++
+	if name != "" {
+		f.namespaces[name] = ns
+	}
+	return ns, nil
+}
+
+func (f *Fake) getNamespaces() (map[string]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	namespaces := make(map[string]int)
+	for name, ns := range f.namespaces {
+		namespaces[name] = ns
+	}
+	return namespaces, nil
+}
+
+func (f *Fake) validNamespace(ns int) bool {
+	return 0 < ns && ns < f.nextSrcID
+}
+
+func (f *Fake) bufferSetExtmark(b vim.Buffer, ns, line, col int, opts vim.ExtmarkOptions) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return 0, err
+	}
+	if !f.validNamespace(ns) {
+		return 0, validationf("Invalid 'ns_id': %d", ns)
+	}
+	if opts.Strict != nil && !*opts.Strict {
+		line = clamp(line, 0, len(buf.lines)-1)
+		col = clamp(col, 0, len(buf.lines[line]))
+		if opts.EndRow != nil && opts.EndCol != nil {
+			endRow := clamp(*opts.EndRow, 0, len(buf.lines)-1)
+			endCol := clamp(*opts.EndCol, 0, len(buf.lines[endRow]))
+			opts.EndRow, opts.EndCol = &endRow, &endCol
+		}
+	}
+	if line < 0 || line >= len(buf.lines) {
+		return 0, validationf("Invalid 'line': out of range")
+	}
+	if col < 0 || col > len(buf.lines[line]) {
+		return 0, validationf("Invalid 'col': out of range")
+	}
+	id := opts.ID
+	if id == 0 {
+		id = buf.nextExtmark
+	}
+	if id >= buf.nextExtmark {
+		buf.nextExtmark = id + 1
+	}
+	opts.ID = 0
+	if buf.extmarks[ns] == nil {
+		buf.extmarks[ns] = make(map[int]*extmark)
+	}
+	buf.extmarks[ns][id] = &extmark{row: line, col: col, opts: opts}
+	return id, nil
+}
+
+func clamp(n, min, max int) int {
+	if n > max {
+		n = max
+	}
+	if n < min {
+		n = min
+	}
+	return n
+}
+
+// details returns the details map for the mark.
+func (m *extmark) details(ns int) map[string]interface{} {
+	d := map[string]interface{}{"ns_id": ns}
+	if m.opts.EndRow != nil && m.opts.EndCol != nil {
+		d["end_row"] = *m.opts.EndRow
+		d["end_col"] = *m.opts.EndCol
+	}
+	for k, v := range map[string]string{
+		"hl_group":      m.opts.HighlightGroup,
+		"virt_text_pos": m.opts.VirtTextPos,
+		"sign_text":     m.opts.SignText,
+		"sign_hl_group": m.opts.SignHighlightGroup,
+	} {
+		if v != "" {
+			d[k] = v
+		}
+	}
+	if m.opts.VirtText != nil {
+		d["virt_text"] = m.opts.VirtText
+	}
+	if m.opts.Priority != 0 {
+		d["priority"] = m.opts.Priority
+	}
+	return d
+}
+
+func (f *Fake) bufferGetExtmarkByID(b vim.Buffer, ns, id int, opts vim.ExtmarksOptions) ([]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return nil, err
+	}
+	if !f.validNamespace(ns) {
+		return nil, validationf("Invalid 'ns_id': %d", ns)
+	}
+	m := buf.extmarks[ns][id]
+	if m == nil {
+		return []interface{}{}, nil
+	}
+	result := []interface{}{m.row, m.col}
+	if opts.Details {
+		result = append(result, m.details(ns))
+	}
+	return result, nil
+}
+
+func (f *Fake) bufferGetExtmarks(b vim.Buffer, ns int, start, end [2]int, opts vim.ExtmarksOptions) ([]interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return nil, err
+	}
+	if ns != -1 && !f.validNamespace(ns) {
+		return nil, validationf("Invalid 'ns_id': %d", ns)
+	}
+	// Negative rows and columns are the end of the buffer and line.
+	for _, pos := range []*[2]int{&start, &end} {
+		for i := range pos {
+			if pos[i] < 0 {
+				pos[i] = math.MaxInt32
+			}
+		}
+	}
+	before := func(a, b [2]int) bool {
+		return a[0] < b[0] || (a[0] == b[0] && a[1] < b[1])
+	}
+	reverse := before(end, start)
+	if reverse {
+		start, end = end, start
+	}
+
+	type result struct {
+		ns, id int
+		m      *extmark
+	}
+	var results []result
+	for mns, marks := range buf.extmarks {
+		if ns != -1 && mns != ns {
+			continue
+		}
+		for id, m := range marks {
+			pos := [2]int{m.row, m.col}
+			if before(pos, start) || before(end, pos) {
+				continue
+			}
+			results = append(results, result{mns, id, m})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.m.row != b.m.row {
+			return a.m.row < b.m.row
+		}
+		if a.m.col != b.m.col {
+			return a.m.col < b.m.col
+		}
+		return a.id < b.id
+	})
+	if reverse {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+	}
+	if opts.Limit > 0 && opts.Limit < len(results) {
+		results = results[:opts.Limit]
+	}
+
+	marks := []interface{}{}
+	for _, r := range results {
+		mark := []interface{}{r.id, r.m.row, r.m.col}
+		if opts.Details {
+			mark = append(mark, r.m.details(r.ns))
+		}
+		marks = append(marks, mark)
+	}
+	return marks, nil
+}
+
+func (f *Fake) bufferDelExtmark(b vim.Buffer, ns, id int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, err := f.buffer(b)
+	if err != nil {
+		return false, err
+	}
+	if !f.validNamespace(ns) {
+		return false, validationf("Invalid 'ns_id': %d", ns)
+	}
+	if buf.extmarks[ns][id] == nil {
+		return false, nil
+	}
+	delete(buf.extmarks[ns], id)
+	return true, nil
+}
+
+// moveExtmarks moves the extmarks for the replacement of lines start to end
+// with n lines. The marks in the replaced lines move to the start of the
+// replacement.
+func (buf *buffer) moveExtmarks(start, end, n int) {
+	move := func(row, col *int) {
+		switch {
+		case *row >= end:
+			*row += n - (end - start)
+		case *row >= start:
+			*row, *col = start, 0
+		}
+	}
+	for _, marks := range buf.extmarks {
+		for _, m := range marks {
+			move(&m.row, &m.col)
+			if m.opts.EndRow != nil && m.opts.EndCol != nil {
+				endRow, endCol := *m.opts.EndRow, *m.opts.EndCol
+				move(&endRow, &endCol)
+				m.opts.EndRow, m.opts.EndCol = &endRow, &endCol
+			}
+		}
+	}
+}
+
+// Tabpages
+
+func (f *Fake) tabpageGetWindows(t vim.Tabpage) ([]vim.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tp, err := f.tabpageByID(t)
+	if err != nil {
+		return nil, err
+	}
+	return append([]vim.Window{}, tp.windows...), nil
+}
+
+func (f *Fake) tabpageGetVar(t vim.Tabpage, name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tp, err := f.tabpageByID(t)
+	if err != nil {
+		return nil, err
+	}
+	return getVar(tp.vars, name)
+}
+
+func (f *Fake) tabpageSetVar(t vim.Tabpage, name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tp, err := f.tabpageByID(t)
+	if err != nil {
+		return err
+	}
+	return setVar(tp.vars, name, value)
+}
+
+func (f *Fake) tabpageDelVar(t vim.Tabpage, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tp, err := f.tabpageByID(t)
+	if err != nil {
+		return err
+	}
+	return delVar(tp.vars, name)
+}
+
+func (f *Fake) tabpageGetWindow(t vim.Tabpage) (vim.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tp, err := f.tabpageByID(t)
+	if err != nil {
+		return 0, err
+	}
+	return tp.window, nil
+}
+
+func (f *Fake) tabpageIsValid(t vim.Tabpage) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tabpages[t] != nil, nil
+}
+
+// Global
+
+func (f *Fake) runCommand(cmd string) (string, error) {
+	f.mu.Lock()
+	f.commands = append(f.commands, cmd)
+	fn := f.cmdStubs[cmd]
+	f.mu.Unlock()
+	if fn == nil {
+		return "", nil
+	}
+	return fn()
+}
+
+func (f *Fake) command(cmd string) error {
+	_, err := f.runCommand(cmd)
+	return err
+}
+
+func (f *Fake) commandOutput(cmd string) (string, error) {
+	return f.runCommand(cmd)
+}
+
+func (f *Fake) feedkeys(keys, mode string, escapeCsi bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.input = append(f.input, keys)
+	return nil
+}
+
+func (f *Fake) vimInput(keys string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.input = append(f.input, keys)
+	return len(keys), nil
+}
+
+var termcodes = map[string]string{
+	"<cr>":     "\r",
+	"<nl>":     "\n",
+	"<esc>":    "\x1b",
+	"<tab>":    "\t",
+	"<bs>":     "\b",
+	"<space>":  " ",
+	"<bar>":    "|",
+	"<bslash>": "\\",
+}
+
+func (f *Fake) replaceTermcodes(str string, fromPart, doLt, special bool) (string, error) {
+	var buf []byte
+	for len(str) > 0 {
+		if str[0] == '<' {
+			if i := strings.IndexByte(str, '>'); i > 0 {
+				name := strings.ToLower(str[:i+1])
+				if s, ok := termcodes[name]; ok {
+					buf = append(buf, s...)
+					str = str[i+1:]
+					continue
+				}
+				if doLt && name == "<lt>" {
+					buf = append(buf, '<')
+					str = str[i+1:]
+					continue
+				}
+				if len(name) == 5 && strings.HasPrefix(name, "<c-") {
+					buf = append(buf, name[3]&0x1f)
+					str = str[i+1:]
+					continue
+				}
+			}
+		}
+		buf = append(buf, str[0])
+		str = str[1:]
+	}
+	return string(buf), nil
+}
+
+func (f *Fake) eval(expr string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result, ok := f.evals[expr]
+	if !ok {
+		return nil, exceptionf("vimfake: no stub for expression %q", expr)
+	}
+	return result, nil
+}
+
+func (f *Fake) execLua(code string, args []interface{}) (interface{}, error) {
+	f.mu.Lock()
+	fn := f.lua
+	f.mu.Unlock()
+	if fn == nil {
+		return nil, exceptionf("vimfake: no stub for Lua code %q", code)
+	}
+	return fn(code, args)
+}
+
+func (f *Fake) callFunction(fname string, args []interface{}) (interface{}, error) {
+	f.mu.Lock()
+	fn := f.functions[fname]
+	cwd := f.cwd
+	f.mu.Unlock()
+
+	if fn != nil {
+		return fn(args)
+	}
+
+	switch fname {
+	case "rpcrequest", "rpcnotify":
+		if len(args) < 2 {
+			return nil, exceptionf("Vim:E119: Not enough arguments for function: %s", fname)
+		}
+		method, ok := args[1].(string)
+		if !ok {
+			return nil, exceptionf("Vim:E475: Invalid argument: %v", args[1])
+		}
+		if fname == "rpcnotify" {
+			return 1, f.ep.Notify(method, args[2:]...)
+		}
+		var result interface{}
+		if err := f.ep.Call(method, &result, args[2:]...); err != nil {
+			if e, ok := err.(rpc.Error); ok {
+				return nil, exceptionf("Vim:Error invoking '%s' on channel 1:\n%v", method, e.Value)
+			}
+			return nil, err
+		}
+		return result, nil
+	case "getcwd":
+		return cwd, nil
+	}
+	return nil, exceptionf("Vim:E117: Unknown function: %s", fname)
+}
+
+func (f *Fake) strwidth(str string) (int, error) {
+	return utf8.RuneCountInString(str), nil
+}
+
+func (f *Fake) listRuntimePaths() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.rtp...), nil
+}
+
+func (f *Fake) changeDirectory(dir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cwd = dir
+	return nil
+}
+
+func (f *Fake) getCurrentLine() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, win := f.currentWindow()
+	return f.buffers[win.buffer].lines[win.cursor[0]-1], nil
+}
+
+func (f *Fake) setCurrentLine(line []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, win := f.currentWindow()
+	f.buffers[win.buffer].lines[win.cursor[0]-1] = line
+	return nil
+}
+
+func (f *Fake) delCurrentLine() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, win := f.currentWindow()
+	buf := f.buffers[win.buffer]
+	i := win.cursor[0] - 1
+	buf.lines = append(buf.lines[:i:i], buf.lines[i+1:]...)
+	if len(buf.lines) == 0 {
+		buf.lines = [][]byte{{}}
+	}
+	if win.cursor[0] > len(buf.lines) {
+		win.cursor = [2]int{len(buf.lines), 0}
+	}
+	return nil
+}
+
+func (f *Fake) getVar(name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return getVar(f.vars, name)
+}
+
+func (f *Fake) setVar(name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return setVar(f.vars, name, value)
+}
+
+func (f *Fake) delVar(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return delVar(f.vars, name)
+}
+
+func (f *Fake) getVvar(name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return getVar(f.vvars, name)
+}
+
+func (f *Fake) getOption(name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return getOption(f.options, name)
+}
+
+func (f *Fake) setOption(name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.options[name] = value
+	return nil
+}
+
+func (f *Fake) outWrite(str string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.out = append(f.out, str)
+	return nil
+}
+
+func (f *Fake) errWrite(str string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = append(f.err, str)
+	return nil
+}
+
+func (f *Fake) reportError(str string) error {
+	return f.errWrite(str + "\n")
+}
+
+func (f *Fake) getBuffers() ([]vim.Buffer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buffers := make([]vim.Buffer, 0, len(f.buffers))
+	for b := range f.buffers {
+		buffers = append(buffers, b)
+	}
+	sort.Slice(buffers, func(i, j int) bool { return buffers[i] < buffers[j] })
+	return buffers, nil
+}
+
+func (f *Fake) getCurrentBuffer() (vim.Buffer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, win := f.currentWindow()
+	return win.buffer, nil
+}
+
+func (f *Fake) setCurrentBuffer(b vim.Buffer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.buffer(b); err != nil {
+		return err
+	}
+	_, win := f.currentWindow()
+	if win.buffer != b {
+		win.buffer = b
+		win.cursor = [2]int{1, 0}
+	}
+	return nil
+}
+
+func (f *Fake) getWindows() ([]vim.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var windows []vim.Window
+	for _, t := range f.sortedTabpages() {
+		windows = append(windows, f.tabpages[t].windows...)
+	}
+	return windows, nil
+}
+
+func (f *Fake) getCurrentWindow() (vim.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w, _ := f.currentWindow()
+	return w, nil
+}
+
+func (f *Fake) setCurrentWindow(w vim.Window) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	f.tabpage = win.tabpage
+	f.tabpages[win.tabpage].window = w
+	return nil
+}
+
+func (f *Fake) sortedTabpages() []vim.Tabpage {
+	tabpages := make([]vim.Tabpage, 0, len(f.tabpages))
+	for t := range f.tabpages {
+		tabpages = append(tabpages, t)
+	}
+	sort.Slice(tabpages, func(i, j int) bool { return tabpages[i] < tabpages[j] })
+	return tabpages
+}
+
+func (f *Fake) getTabpages() ([]vim.Tabpage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sortedTabpages(), nil
+}
+
+func (f *Fake) getCurrentTabpage() (vim.Tabpage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tabpage, nil
+}
+
+func (f *Fake) setCurrentTabpage(t vim.Tabpage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.tabpageByID(t); err != nil {
+		return err
+	}
+	f.tabpage = t
+	return nil
+}
+
+func (f *Fake) subscribe(event string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events[event] = true
+	return nil
+}
+
+func (f *Fake) unsubscribe(event string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.events, event)
+	return nil
+}
+
+func (f *Fake) uiAttach(width, height int, options map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.uiOptions != nil {
+		return exceptionf("UI already attached to channel")
+	}
+	if width <= 0 || height <= 0 {
+		return validationf("Expected width > 0 and height > 0")
+	}
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+	f.uiOptions = options
+	return nil
+}
+
+func (f *Fake) uiDetach() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.uiOptions == nil {
+		return exceptionf("UI not attached to channel")
+	}
+	f.uiOptions = nil
+	return nil
+}
+
+var colorMap = map[string]interface{}{
+	"Black":   0x000000,
+	"Blue":    0x0000ff,
+	"Green":   0x00ff00,
+	"Cyan":    0x00ffff,
+	"Red":     0xff0000,
+	"Magenta": 0xff00ff,
+	"Yellow":  0xffff00,
+	"White":   0xffffff,
+}
+
+func (f *Fake) nameToColor(name string) (int, error) {
+	for k, v := range colorMap {
+		if strings.EqualFold(k, name) {
+			return v.(int), nil
+		}
+	}
+	return -1, nil
+}
+
+func (f *Fake) getColorMap() (map[string]interface{}, error) {
+	return colorMap, nil
+}
+
+func (f *Fake) getAPIInfo() ([]interface{}, error) {
+	var functions []interface{}
+	for sm := range f.methods() {
+		functions = append(functions, map[string]interface{}{"name": sm})
+	}
+	f.mu.Lock()
+	level := f.apiLevel
+	f.mu.Unlock()
+	return []interface{}{1, map[string]interface{}{
+		"version": map[string]interface{}{
+			"major":          0,
+			"minor":          9,
+			"patch":          5,
+			"api_level":      level,
+			"api_compatible": 0,
+			"api_prerelease": false,
+		},
+		"functions": functions,
+		"error_types": map[string]interface{}{
+			"Exception":  map[string]interface{}{"id": exceptionError},
+			"Validation": map[string]interface{}{"id": validationError},
+		},
+		"types": map[string]interface{}{
+			"Buffer":  map[string]interface{}{"id": 0},
+			"Window":  map[string]interface{}{"id": 1},
+			"Tabpage": map[string]interface{}{"id": 2},
+		},
+	}}, nil
+}
+
+// callAtomic executes the calls in order. Calls in the batch are not
+// interleaved with other requests because the fake handles requests one at a
+// time.
+func (f *Fake) callAtomic(calls [][]interface{}) ([]interface{}, error) {
+	methods := f.methods()
+	results := []interface{}{}
+	for i, call := range calls {
+		var err error
+		var result interface{}
+		if len(call) != 2 {
+			err = validationf("Items in calls array must be arrays of size 2")
+		} else if sm, ok := call[0].(string); !ok {
+			err = validationf("Name must be String")
+		} else if args, ok := call[1].([]interface{}); !ok {
+			err = validationf("Args must be Array")
+		} else if fn := methods[sm]; fn == nil || sm == "nvim_call_atomic" {
+			err = validationf("Invalid method name")
+		} else {
+			result, err = invoke(fn, args)
+		}
+		if err != nil {
+			var value interface{} = []interface{}{exceptionError, err.Error()}
+			if e, ok := err.(rpc.Error); ok {
+				value = e.Value
+			}
+			return []interface{}{results, append([]interface{}{i}, value.([]interface{})...)}, nil
+		}
+		results = append(results, result)
+	}
+	return []interface{}{results, nil}, nil
+}
+
+// invoke calls method fn with arguments decoded from args.
+func invoke(fn interface{}, args []interface{}) (interface{}, error) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if len(args) != t.NumIn() {
+		return nil, validationf("Wrong number of arguments: expecting %d but got %d", t.NumIn(), len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var buf bytes.Buffer
+		if err := msgpack.NewEncoder(&buf).Encode(arg); err != nil {
+			return nil, err
+		}
+		pv := reflect.New(t.In(i))
+		if err := msgpack.NewDecoder(&buf).Decode(pv.Interface()); err != nil {
+			return nil, validationf("Wrong type for argument %d: %v", i+1, err)
+		}
+		in[i] = pv.Elem()
+	}
+	out := v.Call(in)
+	var result interface{}
+	if len(out) == 2 {
+		result = out[0].Interface()
+	}
+	err, _ := out[len(out)-1].Interface().(error)
+	return result, err
+}
+
+// Windows
+
+func (f *Fake) windowGetBuffer(w vim.Window) (vim.Buffer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return 0, err
+	}
+	return win.buffer, nil
+}
+
+func (f *Fake) windowGetCursor(w vim.Window) ([2]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return [2]int{}, err
+	}
+	return win.cursor, nil
+}
+
+func (f *Fake) windowSetCursor(w vim.Window, pos [2]int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	if pos[0] <= 0 || pos[0] > len(f.buffers[win.buffer].lines) {
+		return validationf("Cursor position outside buffer")
+	}
+	if pos[1] < 0 {
+		return validationf("Column value outside range")
+	}
+	win.cursor = pos
+	return nil
+}
+
+func (f *Fake) windowGetHeight(w vim.Window) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return 0, err
+	}
+	return win.height, nil
+}
+
+func (f *Fake) windowSetHeight(w vim.Window, height int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	win.height = height
+	return nil
+}
+
+func (f *Fake) windowGetWidth(w vim.Window) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return 0, err
+	}
+	return win.width, nil
+}
+
+func (f *Fake) windowSetWidth(w vim.Window, width int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	win.width = width
+	return nil
+}
+
+func (f *Fake) windowGetVar(w vim.Window, name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return nil, err
+	}
+	return getVar(win.vars, name)
+}
+
+func (f *Fake) windowSetVar(w vim.Window, name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	return setVar(win.vars, name, value)
+}
+
+func (f *Fake) windowDelVar(w vim.Window, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	return delVar(win.vars, name)
+}
+
+func (f *Fake) windowGetOption(w vim.Window, name string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return nil, err
+	}
+	return getOption(win.options, name)
+}
+
+func (f *Fake) windowSetOption(w vim.Window, name string, value interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	win.options[name] = value
+	return nil
+}
+
+func (f *Fake) windowGetPosition(w vim.Window) ([2]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return [2]int{}, err
+	}
+	return win.position, nil
+}
+
+func (f *Fake) windowGetTabpage(w vim.Window) (vim.Tabpage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return 0, err
+	}
+	return win.tabpage, nil
+}
+
+func (f *Fake) windowIsValid(w vim.Window) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.windows[w] != nil, nil
+}
+
+func (f *Fake) createBuffer(listed, scratch bool) (vim.Buffer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b := f.newBuffer("", nil)
+	buf := f.buffers[b]
+	buf.options["buflisted"] = listed
+	if scratch {
+		buf.options["buftype"] = "nofile"
+		buf.options["bufhidden"] = "hide"
+		buf.options["swapfile"] = false
+	}
+	return b, nil
+}
+
+func (f *Fake) openWindow(b vim.Buffer, enter bool, config vim.WindowConfig) (vim.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.buffer(b); err != nil {
+		return 0, err
+	}
+	if !config.Floating() {
+		return 0, exceptionf("fake supports floating windows only")
+	}
+	switch config.Relative {
+	case "editor", "win", "cursor", "mouse":
+	default:
+		return 0, validationf("Invalid value of 'relative' key")
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return 0, validationf("'width' and 'height' must be positive")
+	}
+	tp := f.tabpages[f.tabpage]
+	current := tp.window
+	w := f.newWindow(f.tabpage, b)
+	if !enter {
+		tp.window = current
+	}
+	win := f.windows[w]
+	win.width, win.height = config.Width, config.Height
+	win.config = &config
+	return w, nil
+}
+
+func (f *Fake) windowGetConfig(w vim.Window) (*vim.WindowConfig, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return nil, err
+	}
+	if win.config == nil {
+		return &vim.WindowConfig{Width: win.width, Height: win.height}, nil
+	}
+	config := *win.config
+	return &config, nil
+}
+
+func (f *Fake) windowSetConfig(w vim.Window, config vim.WindowConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	if win.config == nil || !config.Floating() {
+		return exceptionf("fake supports floating windows only")
+	}
+	if config.Width > 0 {
+		win.width = config.Width
+	} else {
+		config.Width = win.config.Width
+	}
+	if config.Height > 0 {
+		win.height = config.Height
+	} else {
+		config.Height = win.config.Height
+	}
+	win.config = &config
+	return nil
+}
+
+func (f *Fake) windowClose(w vim.Window, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	win, err := f.window(w)
+	if err != nil {
+		return err
+	}
+	tp := f.tabpages[win.tabpage]
+	if len(tp.windows) == 1 {
+		return exceptionf("Vim:E444: Cannot close last window")
+	}
+	windows := tp.windows[:0]
+	for _, x := range tp.windows {
+		if x != w {
+			windows = append(windows, x)
+		}
+	}
+	tp.windows = windows
+	if tp.window == w {
+		tp.window = windows[0]
+	}
+	delete(f.windows, w)
+	return nil
+}
+
+func (f *Fake) setKeymap(mode, lhs, rhs string, opts map[string]interface{}) error {
+	return f.bufferSetKeymap(0, mode, lhs, rhs, opts)
+}
+
+func (f *Fake) delKeymap(mode, lhs string) error {
+	return f.bufferDelKeymap(0, mode, lhs)
+}
+
+func (f *Fake) bufferSetKeymap(b vim.Buffer, mode, lhs, rhs string, opts map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b != 0 {
+		if _, err := f.buffer(b); err != nil {
+			return err
+		}
+	}
+	f.keymaps[keymapKey{b, mode, lhs}] = rhs
+	return nil
+}
+
+func (f *Fake) bufferDelKeymap(b vim.Buffer, mode, lhs string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := keymapKey{b, mode, lhs}
+	if _, ok := f.keymaps[k]; !ok {
+		return validationf("E31: No such mapping")
+	}
+	delete(f.keymaps, k)
+	return nil
+}
+
+func (f *Fake) createUserCommand(name string, command interface{}, opts map[string]interface{}) error {
+	return f.bufferCreateUserCommand(0, name, command, opts)
+}
+
+func (f *Fake) delUserCommand(name string) error {
+	return f.bufferDelUserCommand(0, name)
+}
+
+func (f *Fake) bufferCreateUserCommand(b vim.Buffer, name string, command interface{}, opts map[string]interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b != 0 {
+		if _, err := f.buffer(b); err != nil {
+			return err
+		}
+	}
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		return validationf("'name' must begin with an uppercase letter")
+	}
+	s, ok := command.(string)
+	if !ok {
+		return validationf("'command' must be a string")
+	}
+	f.userCommands[userCommandKey{b, name}] = s
+	return nil
+}
+
+func (f *Fake) bufferDelUserCommand(b vim.Buffer, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := userCommandKey{b, name}
+	if _, ok := f.userCommands[k]; !ok {
+		return validationf("Invalid command (not found): %s", name)
+	}
+	delete(f.userCommands, k)
+	return nil
+}
+
+func (f *Fake) createAutocmd(event interface{}, opts map[string]interface{}) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a := &Autocmd{ID: f.nextAutocmd}
+	switch event := event.(type) {
+	case string:
+		a.Events = []string{event}
+	case []interface{}:
+		for _, e := range event {
+			s, ok := e.(string)
+			if !ok {
+				return 0, validationf("All entries in 'event' must be strings")
+			}
+			a.Events = append(a.Events, s)
+		}
+	default:
+		return 0, validationf("'event' must be String or Array")
+	}
+	for k, v := range opts {
+		switch k {
+		case "command":
+			a.Command, _ = v.(string)
+		case "buffer":
+			n, _ := v.(int64)
+			a.Buffer = vim.Buffer(n)
+			if _, err := f.buffer(a.Buffer); err != nil {
+				return 0, err
+			}
+		case "pattern":
+			switch v := v.(type) {
+			case string:
+				a.Pattern = []string{v}
+			case []interface{}:
+				for _, p := range v {
+					s, _ := p.(string)
+					a.Pattern = append(a.Pattern, s)
+				}
+			}
+		case "once":
+			a.Once, _ = v.(bool)
+		case "group", "nested", "desc":
+		default:
+			return 0, validationf("Invalid key: '%s'", k)
+		}
+	}
+	if a.Command == "" {
+		return 0, validationf("Required: 'command' or 'callback'")
+	}
+	if a.Buffer != 0 && a.Pattern != nil {
+		return 0, validationf("Cannot use both 'pattern' and 'buffer' for the same autocmd")
+	}
+	f.nextAutocmd++
+	f.autocmds[a.ID] = a
+	return a.ID, nil
+}
+
+func (f *Fake) delAutocmd(id int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.autocmds, id)
+	return nil
+}
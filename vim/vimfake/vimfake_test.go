@@ -0,0 +1,259 @@
+// Copyright 2016 Gary Burd. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vimfake
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/neovim-go/vim"
+)
+
+func newFake(t *testing.T) *Fake {
+	f, err := New(t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestBuffer(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b := f.NewBuffer("hello.txt", "a", "b", "c")
+	if err := v.SetCurrentBuffer(b); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := v.BufferLineCount(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("BufferLineCount() = %d, want 3", n)
+	}
+
+	if err := v.SetBufferLines(b, 1, -1, true, [][]byte{[]byte("x"), []byte("y")}); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := v.BufferLines(b, 0, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]byte{[]byte("a"), []byte("x"), []byte("y")}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("BufferLines() = %q, want %q", lines, want)
+	}
+
+	if _, err := v.BufferLines(b, 0, 10, true); err == nil {
+		t.Error("BufferLines(strict) out of bounds did not return error")
+	}
+
+	line, err := v.CurrentLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(line) != "a" {
+		t.Errorf("CurrentLine() = %q, want %q", line, "a")
+	}
+
+	name, err := v.BufferName(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "hello.txt" {
+		t.Errorf("BufferName() = %q, want %q", name, "hello.txt")
+	}
+
+	if _, err := v.BufferLineCount(vim.Buffer(100)); err == nil {
+		t.Error("BufferLineCount(invalid) did not return error")
+	}
+}
+
+func TestWindowsAndTabpages(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	b := f.NewBuffer("", "one", "two")
+	w := f.NewWindow(b)
+	if err := v.SetCurrentWindow(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetWindowCursor(w, [2]int{2, 1}); err != nil {
+		t.Fatal(err)
+	}
+	line, err := v.CurrentLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(line) != "two" {
+		t.Errorf("CurrentLine() = %q, want %q", line, "two")
+	}
+
+	windows, err := v.Windows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[1] != w {
+		t.Errorf("Windows() = %v, want two windows ending with %v", windows, w)
+	}
+
+	tp := f.NewTabpage(b)
+	tabpages, err := v.Tabpages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tabpages) != 2 || tabpages[1] != tp {
+		t.Errorf("Tabpages() = %v, want two tabpages ending with %v", tabpages, tp)
+	}
+	wt, err := v.WindowTabpage(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetCurrentWindow(w); err != nil {
+		t.Fatal(err)
+	}
+	ct, err := v.CurrentTabpage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct != wt {
+		t.Errorf("CurrentTabpage() = %v, want %v", ct, wt)
+	}
+}
+
+func TestVarsAndOptions(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	if err := v.SetVar("answer", 42, nil); err != nil {
+		t.Fatal(err)
+	}
+	var answer int
+	if err := v.Var("answer", &answer); err != nil {
+		t.Fatal(err)
+	}
+	if answer != 42 {
+		t.Errorf("Var() = %d, want 42", answer)
+	}
+	if err := v.Var("missing", &answer); err == nil {
+		t.Error("Var(missing) did not return error")
+	}
+
+	if err := v.SetOption("tabstop", 4); err != nil {
+		t.Fatal(err)
+	}
+	var ts int
+	if err := v.Option("tabstop", &ts); err != nil {
+		t.Fatal(err)
+	}
+	if ts != 4 {
+		t.Errorf("Option(tabstop) = %d, want 4", ts)
+	}
+}
+
+func TestCallOrder(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	// Calls to different methods run in the order sent.
+	p := v.NewPipeline()
+	results := make([]int, 100)
+	for i := range results {
+		p.SetVar("x", i, nil)
+		p.Var("x", &results[i])
+	}
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r != i {
+			t.Fatalf("Var() after SetVar(%d) = %d, want %d", i, r, i)
+		}
+	}
+}
+
+func TestStubs(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	f.StubEval(`expand("%")`, "main.go")
+	var name string
+	if err := v.Eval(`expand("%")`, &name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "main.go" {
+		t.Errorf("Eval() = %q, want %q", name, "main.go")
+	}
+	if err := v.Eval(`line(".")`, &name); err == nil {
+		t.Error("Eval(unstubbed) did not return error")
+	}
+
+	f.StubFunction("strlen", func(args []interface{}) (interface{}, error) {
+		return len(args[0].(string)), nil
+	})
+	var n int
+	if err := v.Call("strlen", &n, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Errorf("Call(strlen) = %d, want 5", n)
+	}
+
+	f.StubCommand("version", func() (string, error) { return "NVIM fake", nil })
+	out, err := v.CommandOutput("version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "NVIM fake" {
+		t.Errorf("CommandOutput() = %q, want %q", out, "NVIM fake")
+	}
+	if err := v.Command("set nowrap"); err != nil {
+		t.Fatal(err)
+	}
+	if cmds := f.Commands(); !reflect.DeepEqual(cmds, []string{"version", "set nowrap"}) {
+		t.Errorf("Commands() = %q", cmds)
+	}
+}
+
+func TestRPCRequest(t *testing.T) {
+	f := newFake(t)
+	defer f.Close()
+	v := f.Vim()
+
+	cid, err := v.ChannelID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.RegisterHandler("hello", func(v *vim.Vim, s string) (string, error) {
+		// Call back to the fake from the handler.
+		if err := v.SetVar("greeted", s, nil); err != nil {
+			return "", err
+		}
+		return "Hello, " + s, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	if err := v.Call("rpcrequest", &result, cid, "hello", "world"); err != nil {
+		t.Fatal(err)
+	}
+	if result != "Hello, world" {
+		t.Errorf("rpcrequest returned %q, want %q", result, "Hello, world")
+	}
+	var greeted string
+	if err := v.Var("greeted", &greeted); err != nil {
+		t.Fatal(err)
+	}
+	if greeted != "world" {
+		t.Errorf("g:greeted = %q, want %q", greeted, "world")
+	}
+}